@@ -5,9 +5,13 @@ import (
 	"net/http"
 	"os"
 	"time"
+	// embed the time zone database for the locations time zones, which containers may not provide
+	_ "time/tzdata"
 
 	"github.com/softstone1/fl/config"
 	"github.com/softstone1/fl/internal/client"
+	"github.com/softstone1/fl/internal/model"
+	"github.com/softstone1/fl/internal/renderer"
 	"github.com/softstone1/fl/internal/server"
 	"github.com/softstone1/fl/internal/service"
 )
@@ -29,22 +33,216 @@ func main() {
 		Timeout: 		 cfg.ClientTimeout,
 	}
 	
-	clientCfg.BaseURL = cfg.LocationBaseURL
-    locationClient := client.NewLocation(client.InitializeClient(clientCfg))
+	var locationClient client.Location
+	switch cfg.LocationSource {
+	case "file":
+		locationClient, err = client.NewFileLocations(cfg.LocationFile)
+	case "round-robin":
+		locationClient, err = client.NewRoundRobinLocations(cfg.LocationFile)
+	case "area":
+		if cfg.LocationPolygonFile != "" {
+			locationClient, err = client.NewPolygonLocations(cfg.LocationPolygonFile)
+		} else {
+			var bbox client.BoundingBox
+			if bbox, err = client.ParseBoundingBox(cfg.LocationBBox); err == nil {
+				locationClient = client.NewBoundingBoxLocations(bbox)
+			}
+		}
+	default:
+		clientCfg.BaseURL = cfg.LocationBaseURL
+		locationClient = client.NewLocation(client.InitializeClient(clientCfg))
+	}
+	if err != nil {
+		slog.Error("failed to create location source", "source", cfg.LocationSource, "err", err)
+		os.Exit(1)
+	}
 	
 	clientCfg.BaseURL = cfg.ForecastBaseURL
-    forecastClient := client.NewForecast(client.InitializeClient(clientCfg))
+	nwsClient := client.InitializeClient(clientCfg)
+	forecastClient := client.NewForecast(nwsClient)
+
+	// random locations are fetched ahead so that requests do not wait for the location source
+	var locationBuffer *service.LocationBuffer
+	if cfg.LocationBufferSize > 0 {
+		locationBuffer = service.NewLocationBuffer(locationClient, nil, service.LocationBufferConfiguration{
+			Size:     cfg.LocationBufferSize,
+			LowWater: cfg.LocationBufferLowWater,
+		})
+		locationClient = locationBuffer
+	}
 
 	
-	forecastService, err := service.NewForecast(locationClient, forecastClient, 15 * time.Second, cfg.CacheSize)
+	serviceOpts := []service.Option{
+		service.WithPeriodTolerance(cfg.PeriodTolerance),
+		service.WithHourlyHorizon(cfg.HourlyHorizon),
+		service.WithGridDataTTL(cfg.GridDataCacheTTL),
+		service.WithUnits(model.Units(cfg.Units)),
+	}
+	if cfg.TimeZone != "" {
+		zone, _ := time.LoadLocation(cfg.TimeZone)
+		serviceOpts = append(serviceOpts, service.WithTimeZone(zone))
+	}
+	geocoder, err := client.NewGazetteer(cfg.GazetteerFile)
+	if err != nil {
+		slog.Error("failed to load gazetteer", "err", err)
+		os.Exit(1)
+	}
+	serviceOpts = append(serviceOpts, service.WithGeocoder(geocoder))
+
+	locationFilter, err := loadLocationFilter(cfg)
+	if err != nil {
+		slog.Error("failed to load location filter", "err", err)
+		os.Exit(1)
+	}
+	serviceOpts = append(serviceOpts, service.WithLocationFilter(locationFilter))
+	serviceOpts = append(serviceOpts, service.WithBatchLimits(cfg.BatchMaxSize, cfg.BatchConcurrency, cfg.BatchTimeout))
+	providerSelection, err := service.ParseProviderSelection(cfg.ForecastProvider)
+	if err != nil {
+		slog.Error("failed to parse forecast provider", "err", err)
+		os.Exit(1)
+	}
+	failoverRules, err := service.ParseFailoverRules(cfg.ForecastFailover)
+	if err != nil {
+		slog.Error("failed to parse forecast failover rules", "err", err)
+		os.Exit(1)
+	}
+	// the Open-Meteo client is only needed when a rule tries Open-Meteo
+	if service.UsesProvider(providerSelection, failoverRules, service.ProviderOpenMeteo) {
+		if cfg.OpenMeteoBaseURL == "" {
+			slog.Error("open_meteo_base_url cannot be empty")
+			os.Exit(1)
+		}
+		clientCfg.BaseURL = cfg.OpenMeteoBaseURL
+		openMeteoClient := client.NewOpenMeteo(client.InitializeClient(clientCfg))
+		serviceOpts = append(serviceOpts, service.WithOpenMeteo(openMeteoClient, providerSelection, cfg.OpenMeteoCacheTTL))
+	}
+	serviceOpts = append(serviceOpts, service.WithFailover(service.FailoverConfiguration{
+		Rules:            failoverRules,
+		AttemptTimeout:   cfg.ProviderAttemptTimeout,
+		FailureThreshold: cfg.ProviderFailureThreshold,
+		Cooldown:         cfg.ProviderCooldown,
+	}))
+	if cfg.AlertsEnabled {
+		serviceOpts = append(serviceOpts, service.WithAlerts(client.NewAlerts(nwsClient), cfg.AlertsCacheTTL))
+	}
+	if cfg.ObservationsEnabled {
+		serviceOpts = append(serviceOpts, service.WithObservations(client.NewObservations(nwsClient), cfg.ObservationsCacheTTL, cfg.ObservationsMaxStations))
+	}
+	if cfg.CacheBackend != "lru" {
+		redisClient := service.NewRedisClient(service.RedisConfiguration{
+			Addr:        cfg.RedisAddr,
+			Password:    cfg.RedisPassword,
+			DB:          cfg.RedisDB,
+			PoolSize:    cfg.RedisPoolSize,
+			DialTimeout: cfg.ClientTimeout,
+		})
+		defer redisClient.Close()
+		if cfg.CacheBackend == "tiered" {
+			serviceOpts = append(serviceOpts, service.WithTieredCache(redisClient, cfg.RedisKeyPrefix, cfg.CacheLocalTTL))
+		} else {
+			serviceOpts = append(serviceOpts, service.WithRedisCache(redisClient, cfg.RedisKeyPrefix))
+		}
+	}
+
+	warmLocations, err := service.LoadWarmLocations(cfg.WarmLocations, cfg.WarmLocationsFile)
+	if err != nil {
+		slog.Error("failed to load warm locations", "err", err)
+		os.Exit(1)
+	}
+	// the warm locations seed the pool that seeded random locations are picked from
+	serviceOpts = append(serviceOpts, service.WithSeedLocations(warmLocations))
+	serviceOpts = append(serviceOpts, service.WithSeedTTL(cfg.SeedTTL))
+
+	if cfg.RefreshEnabled {
+		serviceOpts = append(serviceOpts, service.WithBackgroundRefresh(cfg.ForecastUpdateInterval, cfg.RefreshIdleTimeout))
+	}
+
+	forecastService, err := service.NewForecast(locationClient, forecastClient, 15 * time.Second, cfg.CacheSize, serviceOpts...)
 	if err != nil {
 		slog.Error("failed to create forecast service", "err", err)
 		os.Exit(1)
 	}
 
-	router := server.NewRouter(forecastService)
+	responseRenderer, err := renderer.New(cfg.TemplatesDir)
+	if err != nil {
+		slog.Error("failed to load response templates", "err", err)
+		os.Exit(1)
+	}
+	slog.Info("response templates loaded", "locales", responseRenderer.Locales())
+
+	router := server.NewRouter(forecastService, responseRenderer)
+	srv := server.NewServer(cfg, router)
+
+	if cfg.CacheSnapshotPath != "" {
+		restored, err := forecastService.LoadSnapshot(cfg.CacheSnapshotPath, cfg.CacheSnapshotMaxAge)
+		if err != nil {
+			slog.Warn("skipping cache snapshot", "path", cfg.CacheSnapshotPath, "err", err)
+		} else {
+			slog.Info("cache snapshot restored", "path", cfg.CacheSnapshotPath, "entries", restored)
+		}
+	}
+
+	if locationBuffer != nil {
+		if cfg.LocationBufferValidate {
+			// the buffered locations are validated against the providers of the service, warming its points cache
+			locationBuffer.SetCoverage(forecastService)
+		}
+		locationBuffer.Start()
+		srv.OnShutdown(locationBuffer.Stop)
+	}
+
+	if cfg.RefreshEnabled {
+		refresher := service.NewRefresher(forecastService, cfg.RefreshCheckInterval)
+		refresher.Start()
+		srv.OnShutdown(refresher.Stop)
+	}
+
+	// the warmer is registered first so that it is stopped before the final snapshot is written
+	if len(warmLocations) > 0 {
+		warmer := service.NewWarmer(forecastService, warmLocations, service.WarmerConfiguration{
+			Interval:    cfg.WarmInterval,
+			RateLimit:   cfg.WarmRateLimit,
+			RefreshLead: cfg.WarmRefreshLead,
+		})
+		warmer.Start()
+		srv.OnShutdown(warmer.Stop)
+		slog.Info("cache warmer started", "locations", len(warmLocations))
+	}
+
+	if cfg.CacheSnapshotPath != "" {
+		snapshotter := service.NewSnapshotter(forecastService, cfg.CacheSnapshotPath, cfg.CacheSnapshotInterval)
+		snapshotter.Start()
+		srv.OnShutdown(snapshotter.Stop)
+	}
+
 	slog.Info("server starting", "port", cfg.ServerPort)
-	if err := server.NewServer(cfg, router).Run(); err != nil && err != http.ErrServerClosed {
+	if err := srv.Run(); err != nil && err != http.ErrServerClosed {
 		slog.Error("server failed to start", "err", err)
 	}
-}
\ No newline at end of file
+}
+
+// loadLocationFilter loads the regions random locations must be in and outside of
+func loadLocationFilter(cfg *config.Config) (service.LocationFilter, error) {
+	include, err := loadRegion(cfg.LocationFilterStates, cfg.LocationFilterBBox, cfg.LocationFilterPolygonFile)
+	if err != nil {
+		return service.LocationFilter{}, err
+	}
+	exclude, err := loadRegion(cfg.LocationExcludeStates, cfg.LocationExcludeBBox, cfg.LocationExcludePolygonFile)
+	if err != nil {
+		return service.LocationFilter{}, err
+	}
+	return service.LocationFilter{Include: include, Exclude: exclude}, nil
+}
+
+func loadRegion(states, boxes, polygonFile string) (service.Region, error) {
+	region, err := service.ParseRegion(states, boxes)
+	if err != nil {
+		return service.Region{}, err
+	}
+	if polygonFile != "" {
+		if region.Polygons, err = client.LoadPolygons(polygonFile); err != nil {
+			return service.Region{}, err
+		}
+	}
+	return region, nil
+}
@@ -9,18 +9,73 @@ import (
 )
 
 type Config struct {
-	ServerPort             int
-	ServerReadTimeout      time.Duration
-	ServerWriteTimeout     time.Duration
-	ServerIdleTimeout      time.Duration
-	LocationBaseURL        string
-	ForecastBaseURL        string
-	ClientMaxRetries       int
-	ClientRetryWaitMin     time.Duration
-	ClientRetryWaitMax     time.Duration
-	ClientRetryMaxWaitTime time.Duration
-	ClientTimeout          time.Duration
-	CacheSize              int
+	ServerPort                 int
+	ServerReadTimeout          time.Duration
+	ServerWriteTimeout         time.Duration
+	ServerIdleTimeout          time.Duration
+	LocationBaseURL            string
+	LocationSource             string
+	LocationFile               string
+	LocationBBox               string
+	LocationPolygonFile        string
+	LocationBufferSize         int
+	LocationBufferLowWater     int
+	LocationBufferValidate     bool
+	LocationFilterStates       string
+	LocationFilterBBox         string
+	LocationFilterPolygonFile  string
+	LocationExcludeStates      string
+	LocationExcludeBBox        string
+	LocationExcludePolygonFile string
+	ForecastBaseURL            string
+	ForecastProvider           string
+	OpenMeteoBaseURL           string
+	OpenMeteoCacheTTL          time.Duration
+	ForecastFailover           string
+	ProviderAttemptTimeout     time.Duration
+	ProviderFailureThreshold   int
+	ProviderCooldown           time.Duration
+	ClientMaxRetries           int
+	ClientRetryWaitMin         time.Duration
+	ClientRetryWaitMax         time.Duration
+	ClientRetryMaxWaitTime     time.Duration
+	ClientTimeout              time.Duration
+	CacheSize                  int
+	SeedTTL                    time.Duration
+	CacheSnapshotPath          string
+	CacheSnapshotInterval      time.Duration
+	CacheSnapshotMaxAge        time.Duration
+	CacheBackend               string
+	CacheLocalTTL              time.Duration
+	RedisAddr                  string
+	RedisPassword              string
+	RedisDB                    int
+	RedisPoolSize              int
+	RedisKeyPrefix             string
+	WarmLocations              string
+	WarmLocationsFile          string
+	WarmInterval               time.Duration
+	WarmRateLimit              float64
+	WarmRefreshLead            time.Duration
+	RefreshEnabled             bool
+	ForecastUpdateInterval     time.Duration
+	RefreshCheckInterval       time.Duration
+	RefreshIdleTimeout         time.Duration
+	PeriodTolerance            time.Duration
+	HourlyHorizon              time.Duration
+	GridDataCacheTTL           time.Duration
+	AlertsEnabled              bool
+	AlertsCacheTTL             time.Duration
+	ObservationsEnabled        bool
+	ObservationsCacheTTL       time.Duration
+	ObservationsMaxStations    int
+	Units                      string
+	TemplatesDir               string
+	TimeZone                   string
+	GazetteerFile              string
+	BatchMaxSize               int
+	BatchConcurrency           int
+	BatchTimeout               time.Duration
 }
 
 // LoadConfig parses configuration from environment variables and command-line flags
@@ -32,13 +87,69 @@ func LoadConfig() (*Config, error) {
 	flag.DurationVar(&config.ServerWriteTimeout, "write-timeout", 15*time.Second, "server write timeout")
 	flag.DurationVar(&config.ServerIdleTimeout, "idle-timeout", 60*time.Second, "server idle timeout")
 	flag.StringVar(&config.LocationBaseURL, "location-base-url", "https://locations.patch3s.dev", "location base URL")
+	flag.StringVar(&config.LocationSource, "location-source", "patch3s", "source of the random locations: patch3s, file, round-robin or area")
+	flag.StringVar(&config.LocationFile, "location-file", "", "CSV or GeoJSON locations of the file and round-robin location sources")
+	flag.StringVar(&config.LocationBBox, "location-bbox", "", "south,west,north,east bounding box of the area location source")
+	flag.StringVar(&config.LocationPolygonFile, "location-polygon-file", "", "GeoJSON polygons of the area location source, instead of a bounding box")
+	flag.IntVar(&config.LocationBufferSize, "location-buffer-size", 0, "random locations fetched ahead in the background, 0 (default) to fetch them on demand")
+	flag.IntVar(&config.LocationBufferLowWater, "location-buffer-low-water", 5, "buffered random locations below which the buffer is refilled")
+	flag.BoolVar(&config.LocationBufferValidate, "location-buffer-validate", false, "buffer only the random locations their forecast providers cover")
+	flag.StringVar(&config.LocationFilterStates, "location-filter-states", "", "comma separated US state codes or names random locations must be in")
+	flag.StringVar(&config.LocationFilterBBox, "location-filter-bbox", "", "south,west,north,east bounding boxes, separated by semicolons, random locations must be in")
+	flag.StringVar(&config.LocationFilterPolygonFile, "location-filter-polygon-file", "", "GeoJSON polygons random locations must be in")
+	flag.StringVar(&config.LocationExcludeStates, "location-exclude-states", "", "comma separated US state codes or names random locations must be outside of")
+	flag.StringVar(&config.LocationExcludeBBox, "location-exclude-bbox", "", "south,west,north,east bounding boxes, separated by semicolons, random locations must be outside of")
+	flag.StringVar(&config.LocationExcludePolygonFile, "location-exclude-polygon-file", "", "GeoJSON polygons random locations must be outside of")
 	flag.StringVar(&config.ForecastBaseURL, "forecast-base-url", "https://api.weather.gov", "forecast base URL")
+	flag.StringVar(&config.ForecastProvider, "forecast-provider", "nws", "forecast provider: nws, open-meteo or auto, NWS in the US and Open-Meteo elsewhere")
+	flag.StringVar(&config.OpenMeteoBaseURL, "open-meteo-base-url", "https://api.open-meteo.com", "Open-Meteo base URL")
+	flag.DurationVar(&config.OpenMeteoCacheTTL, "open-meteo-cache-ttl", 30*time.Minute, "how long Open-Meteo forecasts are cached")
+	flag.StringVar(&config.ForecastFailover, "forecast-failover", "", "failover rules replacing the providers of -forecast-provider, separated by semicolons: an area (us, * or south,west,north,east), = and the providers tried in order, e.g. us=nws,open-meteo;*=open-meteo")
+	flag.DurationVar(&config.ProviderAttemptTimeout, "provider-attempt-timeout", 5*time.Second, "how long a forecast provider has to answer before the next one is tried")
+	flag.IntVar(&config.ProviderFailureThreshold, "provider-failure-threshold", 3, "consecutive failures after which a forecast provider is skipped")
+	flag.DurationVar(&config.ProviderCooldown, "provider-cooldown", 30*time.Second, "how long a failing forecast provider is skipped")
 	flag.IntVar(&config.ClientMaxRetries, "client-max-retries", 5, "client max retries")
 	flag.DurationVar(&config.ClientRetryWaitMin, "client-retry-wait-min", 500*time.Millisecond, "client retry wait min")
 	flag.DurationVar(&config.ClientRetryWaitMax, "client-retry-wait-max", 5000*time.Millisecond, "client retry wait max")
 	flag.DurationVar(&config.ClientRetryMaxWaitTime, "client-retry-max-wait-time", 30000*time.Millisecond, "client retry max wait time")
 	flag.DurationVar(&config.ClientTimeout, "client-timeout", 15*time.Second, "client timeout")
 	flag.IntVar(&config.CacheSize, "cache-size", 1000, "cache size")
+	flag.DurationVar(&config.SeedTTL, "seed-ttl", 24*time.Hour, "how long a seed replays the random location it picked")
+	flag.StringVar(&config.CacheSnapshotPath, "cache-snapshot-path", "", "cache snapshot file (empty disables snapshots)")
+	flag.DurationVar(&config.CacheSnapshotInterval, "cache-snapshot-interval", 5*time.Minute, "cache snapshot interval")
+	flag.DurationVar(&config.CacheSnapshotMaxAge, "cache-snapshot-max-age", 24*time.Hour, "max age of a cache snapshot to restore forecast URLs from")
+
+	flag.StringVar(&config.CacheBackend, "cache-backend", "lru", "cache backend: lru, redis or tiered")
+	flag.DurationVar(&config.CacheLocalTTL, "cache-local-ttl", time.Minute, "lifetime of local entries in front of redis with the tiered cache backend")
+	flag.StringVar(&config.RedisAddr, "redis-addr", "localhost:6379", "redis address")
+	flag.StringVar(&config.RedisPassword, "redis-password", "", "redis password")
+	flag.IntVar(&config.RedisDB, "redis-db", 0, "redis database")
+	flag.IntVar(&config.RedisPoolSize, "redis-pool-size", 10, "redis connection pool size")
+	flag.StringVar(&config.RedisKeyPrefix, "redis-key-prefix", "fl:", "prefix of the redis keys")
+	flag.StringVar(&config.WarmLocations, "warm-locations", "", "locations to keep warm in the cache, as lat,lng[,name] separated by semicolons")
+	flag.StringVar(&config.WarmLocationsFile, "warm-locations-file", "", "file with one lat,lng[,name] location to keep warm per line")
+	flag.DurationVar(&config.WarmInterval, "warm-interval", time.Hour, "interval between full cache warm-ups")
+	flag.Float64Var(&config.WarmRateLimit, "warm-rate-limit", 2, "max locations warmed per second")
+	flag.DurationVar(&config.WarmRefreshLead, "warm-refresh-lead", 5*time.Minute, "how long before the current forecast period ends a warm location is refreshed")
+	flag.BoolVar(&config.RefreshEnabled, "refresh-enabled", true, "refresh served forecasts in the background")
+	flag.DurationVar(&config.ForecastUpdateInterval, "forecast-update-interval", time.Hour, "how often NWS is expected to update a forecast")
+	flag.DurationVar(&config.RefreshCheckInterval, "refresh-check-interval", time.Minute, "max interval between checks for forecasts to refresh")
+	flag.DurationVar(&config.RefreshIdleTimeout, "refresh-idle-timeout", 2*time.Hour, "stop refreshing forecasts not served for this long")
+	flag.DurationVar(&config.PeriodTolerance, "period-tolerance", 30*time.Minute, "select the next forecast period starting within this tolerance when none covers the current time")
+	flag.DurationVar(&config.HourlyHorizon, "hourly-horizon", 24*time.Hour, "default horizon of the hourly forecast")
+	flag.DurationVar(&config.GridDataCacheTTL, "grid-data-cache-ttl", 15*time.Minute, "how long raw gridpoint data is cached")
+	flag.BoolVar(&config.AlertsEnabled, "alerts-enabled", false, "include active NWS alerts in the forecasts")
+	flag.DurationVar(&config.AlertsCacheTTL, "alerts-cache-ttl", 2*time.Minute, "how long active alerts are cached")
+	flag.BoolVar(&config.ObservationsEnabled, "observations-enabled", false, "include the latest observation of the nearest station in the current forecast")
+	flag.DurationVar(&config.ObservationsCacheTTL, "observations-cache-ttl", 10*time.Minute, "how long station observations are cached")
+	flag.IntVar(&config.ObservationsMaxStations, "observations-max-stations", 3, "max stations tried, nearest first, until one has a usable observation")
+	flag.StringVar(&config.Units, "units", "us", "default unit system of the responses: us or si")
+	flag.StringVar(&config.TimeZone, "time-zone", "", "time zone of the response times, e.g. UTC (empty uses the location time zone)")
+	flag.StringVar(&config.TemplatesDir, "templates-dir", "", "directory of <locale>.tmpl files overriding or adding response templates")
+	flag.StringVar(&config.GazetteerFile, "gazetteer-file", "", "CSV of the places looked up by name (empty uses the bundled US places)")
+	flag.IntVar(&config.BatchMaxSize, "batch-max-size", 250, "max locations of a batch forecast request")
+	flag.IntVar(&config.BatchConcurrency, "batch-concurrency", 8, "max locations of a batch forecast request forecast concurrently")
+	flag.DurationVar(&config.BatchTimeout, "batch-timeout", 10*time.Second, "deadline of a batch forecast request")
 
 	flag.Parse()
 
@@ -80,10 +191,118 @@ func LoadConfig() (*Config, error) {
 		config.LocationBaseURL = locationBaseURLEnv
 	}
 
+	if locationSourceEnv, ok := os.LookupEnv("LOCATION_SOURCE"); ok {
+		config.LocationSource = locationSourceEnv
+	}
+
+	if locationFileEnv, ok := os.LookupEnv("LOCATION_FILE"); ok {
+		config.LocationFile = locationFileEnv
+	}
+
+	if locationBBoxEnv, ok := os.LookupEnv("LOCATION_BBOX"); ok {
+		config.LocationBBox = locationBBoxEnv
+	}
+
+	if locationPolygonFileEnv, ok := os.LookupEnv("LOCATION_POLYGON_FILE"); ok {
+		config.LocationPolygonFile = locationPolygonFileEnv
+	}
+
+	if locationBufferSizeEnv, ok := os.LookupEnv("LOCATION_BUFFER_SIZE"); ok {
+		locationBufferSize, err := strconv.Atoi(locationBufferSizeEnv)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse LOCATION_BUFFER_SIZE: %w", err)
+		}
+		config.LocationBufferSize = locationBufferSize
+	}
+
+	if locationBufferLowWaterEnv, ok := os.LookupEnv("LOCATION_BUFFER_LOW_WATER"); ok {
+		locationBufferLowWater, err := strconv.Atoi(locationBufferLowWaterEnv)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse LOCATION_BUFFER_LOW_WATER: %w", err)
+		}
+		config.LocationBufferLowWater = locationBufferLowWater
+	}
+
+	if locationBufferValidateEnv, ok := os.LookupEnv("LOCATION_BUFFER_VALIDATE"); ok {
+		locationBufferValidate, err := strconv.ParseBool(locationBufferValidateEnv)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse LOCATION_BUFFER_VALIDATE: %w", err)
+		}
+		config.LocationBufferValidate = locationBufferValidate
+	}
+
+	if locationFilterStatesEnv, ok := os.LookupEnv("LOCATION_FILTER_STATES"); ok {
+		config.LocationFilterStates = locationFilterStatesEnv
+	}
+
+	if locationFilterBBoxEnv, ok := os.LookupEnv("LOCATION_FILTER_BBOX"); ok {
+		config.LocationFilterBBox = locationFilterBBoxEnv
+	}
+
+	if locationFilterPolygonFileEnv, ok := os.LookupEnv("LOCATION_FILTER_POLYGON_FILE"); ok {
+		config.LocationFilterPolygonFile = locationFilterPolygonFileEnv
+	}
+
+	if locationExcludeStatesEnv, ok := os.LookupEnv("LOCATION_EXCLUDE_STATES"); ok {
+		config.LocationExcludeStates = locationExcludeStatesEnv
+	}
+
+	if locationExcludeBBoxEnv, ok := os.LookupEnv("LOCATION_EXCLUDE_BBOX"); ok {
+		config.LocationExcludeBBox = locationExcludeBBoxEnv
+	}
+
+	if locationExcludePolygonFileEnv, ok := os.LookupEnv("LOCATION_EXCLUDE_POLYGON_FILE"); ok {
+		config.LocationExcludePolygonFile = locationExcludePolygonFileEnv
+	}
+
 	if forecastBaseURLEnv, ok := os.LookupEnv("FORECAST_BASE_URL"); ok {
 		config.ForecastBaseURL = forecastBaseURLEnv
 	}
 
+	if forecastProviderEnv, ok := os.LookupEnv("FORECAST_PROVIDER"); ok {
+		config.ForecastProvider = forecastProviderEnv
+	}
+
+	if openMeteoBaseURLEnv, ok := os.LookupEnv("OPEN_METEO_BASE_URL"); ok {
+		config.OpenMeteoBaseURL = openMeteoBaseURLEnv
+	}
+
+	if openMeteoCacheTTLEnv, ok := os.LookupEnv("OPEN_METEO_CACHE_TTL"); ok {
+		openMeteoCacheTTL, err := time.ParseDuration(openMeteoCacheTTLEnv)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse OPEN_METEO_CACHE_TTL: %w", err)
+		}
+		config.OpenMeteoCacheTTL = openMeteoCacheTTL
+	}
+
+	if forecastFailoverEnv, ok := os.LookupEnv("FORECAST_FAILOVER"); ok {
+		config.ForecastFailover = forecastFailoverEnv
+	}
+
+	if providerAttemptTimeoutEnv, ok := os.LookupEnv("PROVIDER_ATTEMPT_TIMEOUT"); ok {
+		providerAttemptTimeout, err := time.ParseDuration(providerAttemptTimeoutEnv)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse PROVIDER_ATTEMPT_TIMEOUT: %w", err)
+		}
+		config.ProviderAttemptTimeout = providerAttemptTimeout
+	}
+
+	if providerFailureThresholdEnv, ok := os.LookupEnv("PROVIDER_FAILURE_THRESHOLD"); ok {
+		providerFailureThreshold, err := strconv.Atoi(providerFailureThresholdEnv)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse PROVIDER_FAILURE_THRESHOLD: %w", err)
+		}
+		config.ProviderFailureThreshold = providerFailureThreshold
+	}
+
+	if providerCooldownEnv, ok := os.LookupEnv("PROVIDER_COOLDOWN"); ok {
+		providerCooldown, err := time.ParseDuration(providerCooldownEnv)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse PROVIDER_COOLDOWN: %w", err)
+		}
+		config.ProviderCooldown = providerCooldown
+	}
+
 	if clientMaxRetriesEnv, ok := os.LookupEnv("CLIENT_MAX_RETRIES"); ok {
 		clientMaxRetries, err := strconv.Atoi(clientMaxRetriesEnv)
 		if err != nil {
@@ -132,6 +351,242 @@ func LoadConfig() (*Config, error) {
 		config.CacheSize = cacheSize
 	}
 
+	if seedTTLEnv, ok := os.LookupEnv("SEED_TTL"); ok {
+		seedTTL, err := time.ParseDuration(seedTTLEnv)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse SEED_TTL: %w", err)
+		}
+		config.SeedTTL = seedTTL
+	}
+
+	if cacheSnapshotPathEnv, ok := os.LookupEnv("CACHE_SNAPSHOT_PATH"); ok {
+		config.CacheSnapshotPath = cacheSnapshotPathEnv
+	}
+
+	if cacheSnapshotIntervalEnv, ok := os.LookupEnv("CACHE_SNAPSHOT_INTERVAL"); ok {
+		cacheSnapshotInterval, err := time.ParseDuration(cacheSnapshotIntervalEnv)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse CACHE_SNAPSHOT_INTERVAL: %w", err)
+		}
+		config.CacheSnapshotInterval = cacheSnapshotInterval
+	}
+
+	if cacheSnapshotMaxAgeEnv, ok := os.LookupEnv("CACHE_SNAPSHOT_MAX_AGE"); ok {
+		cacheSnapshotMaxAge, err := time.ParseDuration(cacheSnapshotMaxAgeEnv)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse CACHE_SNAPSHOT_MAX_AGE: %w", err)
+		}
+		config.CacheSnapshotMaxAge = cacheSnapshotMaxAge
+	}
+
+	if cacheBackendEnv, ok := os.LookupEnv("CACHE_BACKEND"); ok {
+		config.CacheBackend = cacheBackendEnv
+	}
+
+	if cacheLocalTTLEnv, ok := os.LookupEnv("CACHE_LOCAL_TTL"); ok {
+		cacheLocalTTL, err := time.ParseDuration(cacheLocalTTLEnv)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse CACHE_LOCAL_TTL: %w", err)
+		}
+		config.CacheLocalTTL = cacheLocalTTL
+	}
+
+	if redisAddrEnv, ok := os.LookupEnv("REDIS_ADDR"); ok {
+		config.RedisAddr = redisAddrEnv
+	}
+
+	if redisPasswordEnv, ok := os.LookupEnv("REDIS_PASSWORD"); ok {
+		config.RedisPassword = redisPasswordEnv
+	}
+
+	if redisDBEnv, ok := os.LookupEnv("REDIS_DB"); ok {
+		redisDB, err := strconv.Atoi(redisDBEnv)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse REDIS_DB: %w", err)
+		}
+		config.RedisDB = redisDB
+	}
+
+	if redisPoolSizeEnv, ok := os.LookupEnv("REDIS_POOL_SIZE"); ok {
+		redisPoolSize, err := strconv.Atoi(redisPoolSizeEnv)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse REDIS_POOL_SIZE: %w", err)
+		}
+		config.RedisPoolSize = redisPoolSize
+	}
+
+	if redisKeyPrefixEnv, ok := os.LookupEnv("REDIS_KEY_PREFIX"); ok {
+		config.RedisKeyPrefix = redisKeyPrefixEnv
+	}
+
+	if warmLocationsEnv, ok := os.LookupEnv("WARM_LOCATIONS"); ok {
+		config.WarmLocations = warmLocationsEnv
+	}
+
+	if warmLocationsFileEnv, ok := os.LookupEnv("WARM_LOCATIONS_FILE"); ok {
+		config.WarmLocationsFile = warmLocationsFileEnv
+	}
+
+	if warmIntervalEnv, ok := os.LookupEnv("WARM_INTERVAL"); ok {
+		warmInterval, err := time.ParseDuration(warmIntervalEnv)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse WARM_INTERVAL: %w", err)
+		}
+		config.WarmInterval = warmInterval
+	}
+
+	if warmRateLimitEnv, ok := os.LookupEnv("WARM_RATE_LIMIT"); ok {
+		warmRateLimit, err := strconv.ParseFloat(warmRateLimitEnv, 64)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse WARM_RATE_LIMIT: %w", err)
+		}
+		config.WarmRateLimit = warmRateLimit
+	}
+
+	if warmRefreshLeadEnv, ok := os.LookupEnv("WARM_REFRESH_LEAD"); ok {
+		warmRefreshLead, err := time.ParseDuration(warmRefreshLeadEnv)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse WARM_REFRESH_LEAD: %w", err)
+		}
+		config.WarmRefreshLead = warmRefreshLead
+	}
+
+	if refreshEnabledEnv, ok := os.LookupEnv("REFRESH_ENABLED"); ok {
+		refreshEnabled, err := strconv.ParseBool(refreshEnabledEnv)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse REFRESH_ENABLED: %w", err)
+		}
+		config.RefreshEnabled = refreshEnabled
+	}
+
+	if forecastUpdateIntervalEnv, ok := os.LookupEnv("FORECAST_UPDATE_INTERVAL"); ok {
+		forecastUpdateInterval, err := time.ParseDuration(forecastUpdateIntervalEnv)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse FORECAST_UPDATE_INTERVAL: %w", err)
+		}
+		config.ForecastUpdateInterval = forecastUpdateInterval
+	}
+
+	if refreshCheckIntervalEnv, ok := os.LookupEnv("REFRESH_CHECK_INTERVAL"); ok {
+		refreshCheckInterval, err := time.ParseDuration(refreshCheckIntervalEnv)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse REFRESH_CHECK_INTERVAL: %w", err)
+		}
+		config.RefreshCheckInterval = refreshCheckInterval
+	}
+
+	if refreshIdleTimeoutEnv, ok := os.LookupEnv("REFRESH_IDLE_TIMEOUT"); ok {
+		refreshIdleTimeout, err := time.ParseDuration(refreshIdleTimeoutEnv)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse REFRESH_IDLE_TIMEOUT: %w", err)
+		}
+		config.RefreshIdleTimeout = refreshIdleTimeout
+	}
+
+	if periodToleranceEnv, ok := os.LookupEnv("PERIOD_TOLERANCE"); ok {
+		periodTolerance, err := time.ParseDuration(periodToleranceEnv)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse PERIOD_TOLERANCE: %w", err)
+		}
+		config.PeriodTolerance = periodTolerance
+	}
+
+	if hourlyHorizonEnv, ok := os.LookupEnv("HOURLY_HORIZON"); ok {
+		hourlyHorizon, err := time.ParseDuration(hourlyHorizonEnv)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse HOURLY_HORIZON: %w", err)
+		}
+		config.HourlyHorizon = hourlyHorizon
+	}
+
+	if gridDataCacheTTLEnv, ok := os.LookupEnv("GRID_DATA_CACHE_TTL"); ok {
+		gridDataCacheTTL, err := time.ParseDuration(gridDataCacheTTLEnv)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse GRID_DATA_CACHE_TTL: %w", err)
+		}
+		config.GridDataCacheTTL = gridDataCacheTTL
+	}
+
+	if alertsEnabledEnv, ok := os.LookupEnv("ALERTS_ENABLED"); ok {
+		alertsEnabled, err := strconv.ParseBool(alertsEnabledEnv)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse ALERTS_ENABLED: %w", err)
+		}
+		config.AlertsEnabled = alertsEnabled
+	}
+
+	if alertsCacheTTLEnv, ok := os.LookupEnv("ALERTS_CACHE_TTL"); ok {
+		alertsCacheTTL, err := time.ParseDuration(alertsCacheTTLEnv)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse ALERTS_CACHE_TTL: %w", err)
+		}
+		config.AlertsCacheTTL = alertsCacheTTL
+	}
+
+	if observationsEnabledEnv, ok := os.LookupEnv("OBSERVATIONS_ENABLED"); ok {
+		observationsEnabled, err := strconv.ParseBool(observationsEnabledEnv)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse OBSERVATIONS_ENABLED: %w", err)
+		}
+		config.ObservationsEnabled = observationsEnabled
+	}
+
+	if observationsCacheTTLEnv, ok := os.LookupEnv("OBSERVATIONS_CACHE_TTL"); ok {
+		observationsCacheTTL, err := time.ParseDuration(observationsCacheTTLEnv)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse OBSERVATIONS_CACHE_TTL: %w", err)
+		}
+		config.ObservationsCacheTTL = observationsCacheTTL
+	}
+
+	if observationsMaxStationsEnv, ok := os.LookupEnv("OBSERVATIONS_MAX_STATIONS"); ok {
+		observationsMaxStations, err := strconv.Atoi(observationsMaxStationsEnv)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse OBSERVATIONS_MAX_STATIONS: %w", err)
+		}
+		config.ObservationsMaxStations = observationsMaxStations
+	}
+
+	if unitsEnv, ok := os.LookupEnv("UNITS"); ok {
+		config.Units = unitsEnv
+	}
+
+	if templatesDirEnv, ok := os.LookupEnv("TEMPLATES_DIR"); ok {
+		config.TemplatesDir = templatesDirEnv
+	}
+
+	if timeZoneEnv, ok := os.LookupEnv("TIME_ZONE"); ok {
+		config.TimeZone = timeZoneEnv
+	}
+
+	if gazetteerFileEnv, ok := os.LookupEnv("GAZETTEER_FILE"); ok {
+		config.GazetteerFile = gazetteerFileEnv
+	}
+
+	if batchMaxSizeEnv, ok := os.LookupEnv("BATCH_MAX_SIZE"); ok {
+		batchMaxSize, err := strconv.Atoi(batchMaxSizeEnv)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse BATCH_MAX_SIZE: %w", err)
+		}
+		config.BatchMaxSize = batchMaxSize
+	}
+
+	if batchConcurrencyEnv, ok := os.LookupEnv("BATCH_CONCURRENCY"); ok {
+		batchConcurrency, err := strconv.Atoi(batchConcurrencyEnv)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse BATCH_CONCURRENCY: %w", err)
+		}
+		config.BatchConcurrency = batchConcurrency
+	}
+
+	if batchTimeoutEnv, ok := os.LookupEnv("BATCH_TIMEOUT"); ok {
+		batchTimeout, err := time.ParseDuration(batchTimeoutEnv)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse BATCH_TIMEOUT: %w", err)
+		}
+		config.BatchTimeout = batchTimeout
+	}
+
 	// validate configuration
 	if err := config.validate(); err != nil {
 		return nil, err
@@ -156,9 +611,45 @@ func (c *Config) validate() error {
 	if c.LocationBaseURL == "" {
 		return fmt.Errorf("location_base_url cannot be empty")
 	}
+	switch c.LocationSource {
+	case "patch3s":
+	case "file", "round-robin":
+		if c.LocationFile == "" {
+			return fmt.Errorf("location_file cannot be empty with the %s location source", c.LocationSource)
+		}
+	case "area":
+		if (c.LocationBBox == "") == (c.LocationPolygonFile == "") {
+			return fmt.Errorf("the area location source needs either location_bbox or location_polygon_file")
+		}
+	default:
+		return fmt.Errorf("invalid location_source: %s", c.LocationSource)
+	}
+	if c.LocationBufferSize < 0 {
+		return fmt.Errorf("location_buffer_size must be non-negative")
+	}
+	if c.LocationBufferSize > 0 && (c.LocationBufferLowWater < 0 || c.LocationBufferLowWater >= c.LocationBufferSize) {
+		return fmt.Errorf("location_buffer_low_water must be non-negative and less than location_buffer_size")
+	}
 	if c.ForecastBaseURL == "" {
 		return fmt.Errorf("forecast_base_url cannot be empty")
 	}
+	switch c.ForecastProvider {
+	case "nws", "open-meteo", "auto":
+	default:
+		return fmt.Errorf("invalid forecast_provider: %s", c.ForecastProvider)
+	}
+	if c.OpenMeteoCacheTTL <= 0 {
+		return fmt.Errorf("open_meteo_cache_ttl must be positive")
+	}
+	if c.ProviderAttemptTimeout <= 0 {
+		return fmt.Errorf("provider_attempt_timeout must be positive")
+	}
+	if c.ProviderFailureThreshold <= 0 {
+		return fmt.Errorf("provider_failure_threshold must be positive")
+	}
+	if c.ProviderCooldown <= 0 {
+		return fmt.Errorf("provider_cooldown must be positive")
+	}
 	if c.ClientMaxRetries < 0 {
 		return fmt.Errorf("client_max_retries must be non-negative")
 	}
@@ -178,6 +669,104 @@ func (c *Config) validate() error {
 	if c.CacheSize <= 0 {
 		return fmt.Errorf("cache_size must be positive")
 	}
+	if c.SeedTTL <= 0 {
+		return fmt.Errorf("seed_ttl must be positive")
+	}
+	if c.CacheSnapshotPath != "" {
+		if c.CacheSnapshotInterval <= 0 {
+			return fmt.Errorf("cache_snapshot_interval must be positive")
+		}
+		if c.CacheSnapshotMaxAge <= 0 {
+			return fmt.Errorf("cache_snapshot_max_age must be positive")
+		}
+	}
+
+	if c.WarmInterval <= 0 {
+		return fmt.Errorf("warm_interval must be positive")
+	}
+	if c.WarmRateLimit <= 0 {
+		return fmt.Errorf("warm_rate_limit must be positive")
+	}
+	if c.WarmRefreshLead < 0 {
+		return fmt.Errorf("warm_refresh_lead must be non-negative")
+	}
+
+	if c.RefreshEnabled {
+		if c.ForecastUpdateInterval <= 0 {
+			return fmt.Errorf("forecast_update_interval must be positive")
+		}
+		if c.RefreshCheckInterval <= 0 {
+			return fmt.Errorf("refresh_check_interval must be positive")
+		}
+		if c.RefreshIdleTimeout <= 0 {
+			return fmt.Errorf("refresh_idle_timeout must be positive")
+		}
+	}
+
+	if c.PeriodTolerance < 0 {
+		return fmt.Errorf("period_tolerance must be non-negative")
+	}
+
+	if c.HourlyHorizon <= 0 {
+		return fmt.Errorf("hourly_horizon must be positive")
+	}
+
+	if c.GridDataCacheTTL <= 0 {
+		return fmt.Errorf("grid_data_cache_ttl must be positive")
+	}
+
+	if c.AlertsEnabled && c.AlertsCacheTTL <= 0 {
+		return fmt.Errorf("alerts_cache_ttl must be positive")
+	}
+
+	if c.ObservationsEnabled {
+		if c.ObservationsCacheTTL <= 0 {
+			return fmt.Errorf("observations_cache_ttl must be positive")
+		}
+		if c.ObservationsMaxStations <= 0 {
+			return fmt.Errorf("observations_max_stations must be positive")
+		}
+	}
+
+	if c.Units != "us" && c.Units != "si" {
+		return fmt.Errorf("invalid units: %s", c.Units)
+	}
+
+	if c.TimeZone != "" {
+		if _, err := time.LoadLocation(c.TimeZone); err != nil {
+			return fmt.Errorf("invalid time_zone: %w", err)
+		}
+	}
+
+	switch c.CacheBackend {
+	case "lru":
+	case "redis", "tiered":
+		if c.RedisAddr == "" {
+			return fmt.Errorf("redis_addr cannot be empty")
+		}
+		if c.RedisDB < 0 {
+			return fmt.Errorf("redis_db must be non-negative")
+		}
+		if c.RedisPoolSize <= 0 {
+			return fmt.Errorf("redis_pool_size must be positive")
+		}
+		if c.CacheBackend == "tiered" && c.CacheLocalTTL <= 0 {
+			return fmt.Errorf("cache_local_ttl must be positive")
+		}
+	default:
+		return fmt.Errorf("invalid cache_backend: %q", c.CacheBackend)
+	}
+
+	if c.BatchMaxSize <= 0 {
+		return fmt.Errorf("batch_max_size must be positive")
+	}
+	if c.BatchConcurrency <= 0 {
+		return fmt.Errorf("batch_concurrency must be positive")
+	}
+	// the batch results are written once its deadline passed at the latest, which must be before the write timeout
+	if c.BatchTimeout <= 0 || c.BatchTimeout >= c.ServerWriteTimeout {
+		return fmt.Errorf("batch_timeout must be positive and less than write_timeout")
+	}
 
 	return nil
 }
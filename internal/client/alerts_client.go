@@ -0,0 +1,65 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/go-resty/resty/v2"
+
+	"github.com/softstone1/fl/internal/model"
+)
+
+type Alerts interface {
+	GetActiveAlerts(ctx context.Context, lat, lng float64) ([]model.Alert, error)
+}
+
+type alerts struct {
+	client *resty.Client
+}
+
+// make sure alerts implements the Alerts interface
+var _ Alerts = (*alerts)(nil)
+
+// AlertsResponse represents the top-level GeoJSON feature collection
+type AlertsResponse struct {
+	Features []AlertFeature `json:"features"`
+}
+
+// AlertFeature holds a single alert
+type AlertFeature struct {
+	Properties model.Alert `json:"properties"`
+}
+
+// NewAlerts initializes a new Alerts Client with a shared http.Client
+func NewAlerts(client *resty.Client) *alerts {
+	return &alerts{
+		client: client,
+	}
+}
+
+// GetActiveAlerts returns the alerts currently active at a given location
+func (a *alerts) GetActiveAlerts(ctx context.Context, lat, lng float64) ([]model.Alert, error) {
+	url := fmt.Sprintf("%s/alerts/active", a.client.BaseURL)
+	alertsResponse := &AlertsResponse{}
+	resp, err := a.client.R().
+		SetResult(alertsResponse).
+		SetContext(ctx).
+		SetHeader("Accept", "application/geo+json").
+		SetQueryParam("point", fmt.Sprintf("%.4f,%.4f", lat, lng)).
+		Get(url)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch active alerts: %w", err)
+	}
+
+	if resp.StatusCode() != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode())
+	}
+
+	activeAlerts := make([]model.Alert, 0, len(alertsResponse.Features))
+	for _, feature := range alertsResponse.Features {
+		activeAlerts = append(activeAlerts, feature.Properties)
+	}
+	return activeAlerts, nil
+}
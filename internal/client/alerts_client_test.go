@@ -0,0 +1,56 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetActiveAlerts(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/alerts/active", r.URL.Path)
+		assert.Equal(t, "39.7456,-97.0892", r.URL.Query().Get("point"))
+		w.Header().Set("Content-Type", "application/geo+json")
+		w.Write([]byte(`{
+			"type": "FeatureCollection",
+			"features": [
+				{
+					"properties": {
+						"id": "urn:oid:2.49.0.1.840.0.1",
+						"event": "Winter Storm Warning",
+						"headline": "Winter Storm Warning issued by NWS Topeka",
+						"severity": "Severe",
+						"urgency": "Expected",
+						"onset": "2025-01-01T18:00:00-06:00",
+						"expires": "2025-01-02T06:00:00-06:00"
+					}
+				},
+				{
+					"properties": {
+						"event": "Special Weather Statement",
+						"severity": "Minor",
+						"urgency": "Expected",
+						"onset": null,
+						"expires": "2025-01-01T20:00:00-06:00"
+					}
+				}
+			]
+		}`))
+	}))
+	defer srv.Close()
+
+	a := NewAlerts(InitializeClient(Configuration{BaseURL: srv.URL, Timeout: time.Second}))
+	alerts, err := a.GetActiveAlerts(context.Background(), 39.7456, -97.0892)
+
+	require.NoError(t, err)
+	require.Len(t, alerts, 2)
+	assert.Equal(t, "Winter Storm Warning", alerts[0].Event)
+	assert.Equal(t, "Severe", alerts[0].Severity)
+	assert.Equal(t, time.Date(2025, 1, 2, 12, 0, 0, 0, time.UTC), alerts[0].Expires.UTC())
+	assert.True(t, alerts[1].Onset.IsZero())
+}
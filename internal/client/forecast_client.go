@@ -4,73 +4,110 @@ import (
 	"context"
 	"fmt"
 	"net/http"
+	"strconv"
 
 	"github.com/go-resty/resty/v2"
+	lru "github.com/hashicorp/golang-lru/v2"
 
 	"github.com/softstone1/fl/internal/model"
 )
 
 type Forecast interface {
-	GetForecastURL(ctx context.Context, lat, lng float64) (string, error)
-	GetForecastPeriods(ctx context.Context, forecastURL string) ([]model.ForcastPeriod, error)
+	GetPointMetadata(ctx context.Context, lat, lng float64) (*model.PointMetadata, error)
+	GetForecastPeriods(ctx context.Context, forecastURL string) (*model.Forecast, error)
+	GetHourlyForecastPeriods(ctx context.Context, forecastHourlyURL string) (*model.Forecast, error)
+	GetGridData(ctx context.Context, gridDataURL string) (*model.GridData, error)
 }
 
+// maxPointRedirects is how many points redirects are remembered, the least recently used being forgotten first
+const maxPointRedirects = 1024
+
 type forecast struct {
 	client *resty.Client
+	// redirects maps the points URLs NWS redirected to their targets, which are requested directly afterwards
+	redirects *lru.Cache[string, string]
 }
 
 // make sure forcast implements the Forcast interface
 var _ Forecast = (*forecast)(nil)
 
-// ForecastURLResponse represents the top-level JSON structure
-type ForecastURLResponse struct {
-	Properties ForcastURLProperties `json:"properties"`
+// PointResponse represents the top-level JSON structure
+type PointResponse struct {
+	Properties PointProperties `json:"properties"`
 }
 
-// ForcastURLProperties holds the forecast URL
-type ForcastURLProperties struct {
-	Forecast string `json:"forecast"`
+// PointProperties holds the point metadata, whose relative location NWS gives as a GeoJSON feature
+type PointProperties struct {
+	model.PointMetadata
+	RelativeLocation struct {
+		Properties model.RelativeLocation `json:"properties"`
+	} `json:"relativeLocation"`
 }
 
 // ForcastPeriodResponse represents the top-level JSON structure
 type ForcastPeriodResponse struct {
-	Properties ForcastPeriodsProperties `json:"properties"`
-}
-
-// ForcastPeriodsProperties holds the forecast periods
-type ForcastPeriodsProperties struct {
-	Periods []model.ForcastPeriod `json:"periods"`
+	Properties model.Forecast `json:"properties"`
 }
 
 // NewForecast initializes a new Forecast Client with a shared http.Client
 func NewForecast(client *resty.Client) *forecast {
+	// lru.New only fails on a non-positive size
+	redirects, _ := lru.New[string, string](maxPointRedirects)
 	return &forecast{
-		client: client,
+		client:    client,
+		redirects: redirects,
 	}
 }
 
-// GetForecastURL returns the forcast URL for a given location
-func (f *forecast) GetForecastURL(ctx context.Context, lat, lng float64) (string, error) {
-	url := fmt.Sprintf("%s/points/%f,%f", f.client.BaseURL, lat, lng)
-	forcastURL := &ForecastURLResponse{}
+// GetPointMetadata returns the point metadata, including the forcast URLs, for a given location.
+// The coordinates are sent with the NWS precision, which NWS would otherwise redirect to.
+func (f *forecast) GetPointMetadata(ctx context.Context, lat, lng float64) (*model.PointMetadata, error) {
+	pointURL := fmt.Sprintf("%s/points/%s,%s", f.client.BaseURL, formatCoordinate(lat), formatCoordinate(lng))
+	url := pointURL
+	if target, ok := f.redirects.Get(pointURL); ok {
+		url = target
+	}
+	pointResponse := &PointResponse{}
 	resp, err := f.client.R().
-		SetResult(forcastURL).
+		SetResult(pointResponse).
 		SetContext(ctx).
 		SetHeader("Accept", "application/json").
 		Get(url)
 
 	if err != nil {
-		return "", fmt.Errorf("failed to fetch forecast: %w", err)
+		return nil, fmt.Errorf("failed to fetch forecast: %w", err)
 	}
 
 	if resp.StatusCode() != 200 {
-		return "", fmt.Errorf("failed to fetch forecast: %s", resp.Status())
+		return nil, fmt.Errorf("failed to fetch forecast: %s", resp.Status())
+	}
+	if resp.RawResponse != nil && resp.RawResponse.Request != nil {
+		if target := resp.RawResponse.Request.URL.String(); target != url {
+			f.redirects.Add(pointURL, target)
+		}
 	}
-	return forcastURL.Properties.Forecast, nil
+	point := pointResponse.Properties.PointMetadata
+	point.RelativeLocation = pointResponse.Properties.RelativeLocation.Properties
+	return &point, nil
+}
+
+// formatCoordinate formats a coordinate the way NWS does, rounded to its precision without trailing zeros
+func formatCoordinate(value float64) string {
+	return strconv.FormatFloat(model.RoundCoordinate(value), 'f', -1, 64)
+}
+
+// GetForecastPeriods returns the forecast periods and their update times for a given forecast URL
+func (f *forecast) GetForecastPeriods(ctx context.Context, forecastURL string) (*model.Forecast, error) {
+	return f.getPeriods(ctx, forecastURL)
+}
+
+// GetHourlyForecastPeriods returns the hourly forecast periods and their update times for a given hourly forecast URL
+func (f *forecast) GetHourlyForecastPeriods(ctx context.Context, forecastHourlyURL string) (*model.Forecast, error) {
+	return f.getPeriods(ctx, forecastHourlyURL)
 }
 
-// GetForecastPeriods returns the forecast periods for a given forecast URL
-func (f *forecast) GetForecastPeriods(ctx context.Context, forecastURL string) ([]model.ForcastPeriod, error) {
+// getPeriods fetches the periods of a standard or hourly forecast, which share the same layout
+func (f *forecast) getPeriods(ctx context.Context, forecastURL string) (*model.Forecast, error) {
 	forecastResponse := &ForcastPeriodResponse{}
 	resp, err := f.client.R().
 		SetResult(forecastResponse).
@@ -85,5 +122,5 @@ func (f *forecast) GetForecastPeriods(ctx context.Context, forecastURL string) (
 	if resp.StatusCode() != http.StatusOK {
 		return nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode())
 	}
-	return forecastResponse.Properties.Periods, nil
+	return &forecastResponse.Properties, nil
 }
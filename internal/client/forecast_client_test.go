@@ -0,0 +1,173 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestClient(t *testing.T, handler http.HandlerFunc) *forecast {
+	t.Helper()
+	srv := httptest.NewServer(handler)
+	t.Cleanup(srv.Close)
+	return NewForecast(InitializeClient(Configuration{
+		BaseURL:          srv.URL,
+		RetryWaitMin:     time.Millisecond,
+		RetryWaitMax:     time.Millisecond,
+		RetryMaxWaitTime: time.Millisecond,
+		Timeout:          time.Second,
+	}))
+}
+
+func TestGetForecastPeriods_DecodesPeriodDetails(t *testing.T) {
+	f := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/geo+json")
+		w.Write([]byte(`{
+			"properties": {
+				"updateTime": "2025-01-01T10:00:00+00:00",
+				"generatedAt": "2025-01-01T11:00:00+00:00",
+				"periods": [
+					{
+						"number": 1,
+						"name": "Tonight",
+						"startTime": "2025-01-01T18:00:00-07:00",
+						"endTime": "2025-01-02T06:00:00-07:00",
+						"isDaytime": false,
+						"temperature": 28,
+						"temperatureUnit": "F",
+						"probabilityOfPrecipitation": {"unitCode": "wmoUnit:percent", "value": 20},
+						"windSpeed": "5 to 10 mph",
+						"windDirection": "NW",
+						"icon": "https://api.weather.gov/icons/land/night/snow,20?size=medium",
+						"shortForecast": "Slight Chance Snow",
+						"detailedForecast": "A slight chance of snow."
+					},
+					{
+						"number": 2,
+						"name": "Thursday",
+						"startTime": "2025-01-02T06:00:00-07:00",
+						"endTime": "2025-01-02T18:00:00-07:00",
+						"isDaytime": true,
+						"temperature": 41,
+						"temperatureUnit": "F",
+						"probabilityOfPrecipitation": {"unitCode": "wmoUnit:percent", "value": null},
+						"windSpeed": "5 mph",
+						"windDirection": "S",
+						"shortForecast": "Sunny",
+						"detailedForecast": "Sunny."
+					}
+				]
+			}
+		}`))
+	})
+
+	forecast, err := f.GetForecastPeriods(context.Background(), f.client.BaseURL+"/gridpoints/BOU/62,61/forecast")
+
+	require.NoError(t, err)
+	require.Len(t, forecast.Periods, 2)
+	assert.Equal(t, time.Date(2025, 1, 1, 10, 0, 0, 0, time.UTC), forecast.UpdateTime.UTC())
+
+	tonight := forecast.Periods[0]
+	assert.False(t, tonight.IsDaytime)
+	assert.Equal(t, 28.0, tonight.Temperature)
+	assert.Equal(t, "F", tonight.TemperatureUnit)
+	assert.Equal(t, "5 to 10 mph", tonight.WindSpeed)
+	assert.Equal(t, "NW", tonight.WindDirection)
+	assert.Equal(t, "Slight Chance Snow", tonight.ShortForecast)
+	assert.Contains(t, tonight.Icon, "night/snow")
+	require.NotNil(t, tonight.ProbabilityOfPrecipitation.Value)
+	assert.Equal(t, 20.0, *tonight.ProbabilityOfPrecipitation.Value)
+	assert.Equal(t, "wmoUnit:percent", tonight.ProbabilityOfPrecipitation.UnitCode)
+
+	assert.True(t, forecast.Periods[1].IsDaytime)
+	assert.Nil(t, forecast.Periods[1].ProbabilityOfPrecipitation.Value)
+}
+
+func TestGetPointMetadata_DecodesPointMetadata(t *testing.T) {
+	f := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/points/39.7456,-97.0892", r.URL.Path)
+		w.Header().Set("Content-Type", "application/geo+json")
+		w.Write([]byte(`{
+			"properties": {
+				"gridId": "TOP",
+				"gridX": 32,
+				"gridY": 81,
+				"forecast": "https://api.weather.gov/gridpoints/TOP/32,81/forecast",
+				"forecastHourly": "https://api.weather.gov/gridpoints/TOP/32,81/forecast/hourly",
+				"observationStations": "https://api.weather.gov/gridpoints/TOP/32,81/stations",
+				"relativeLocation": {
+					"type": "Feature",
+					"properties": {
+						"city": "Linn",
+						"state": "KS",
+						"distance": {"unitCode": "wmoUnit:m", "value": 7366.9851976444},
+						"bearing": {"unitCode": "wmoUnit:degree_(angle)", "value": 358}
+					}
+				},
+				"county": "https://api.weather.gov/zones/county/KSC201",
+				"fireWeatherZone": "https://api.weather.gov/zones/fire/KSZ009",
+				"timeZone": "America/Chicago",
+				"radarStation": "KTWX"
+			}
+		}`))
+	})
+
+	point, err := f.GetPointMetadata(context.Background(), 39.7456, -97.0892)
+
+	require.NoError(t, err)
+	assert.Equal(t, "TOP", point.GridID)
+	assert.Equal(t, 32, point.GridX)
+	assert.Equal(t, 81, point.GridY)
+	assert.Equal(t, "https://api.weather.gov/gridpoints/TOP/32,81/forecast", point.Forecast)
+	assert.Equal(t, "https://api.weather.gov/gridpoints/TOP/32,81/forecast/hourly", point.ForecastHourly)
+	assert.Equal(t, "https://api.weather.gov/gridpoints/TOP/32,81/stations", point.ObservationStations)
+	assert.Equal(t, "Linn", point.RelativeLocation.City)
+	assert.Equal(t, "KS", point.RelativeLocation.State)
+	assert.Equal(t, 7366.9851976444, *point.RelativeLocation.Distance.Value)
+	assert.Equal(t, 358.0, *point.RelativeLocation.Bearing.Value)
+	assert.Equal(t, "https://api.weather.gov/zones/county/KSC201", point.County)
+	assert.Equal(t, "https://api.weather.gov/zones/fire/KSZ009", point.FireWeatherZone)
+	assert.Equal(t, "America/Chicago", point.TimeZone)
+	assert.Equal(t, "KTWX", point.RadarStation)
+}
+
+func TestGetPointMetadata_NormalizesCoordinates(t *testing.T) {
+	f := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		// NWS precision, without trailing zeros or a negative zero
+		assert.Equal(t, "/points/39.7,0", r.URL.Path)
+		w.Header().Set("Content-Type", "application/geo+json")
+		w.Write([]byte(`{"properties": {"gridId": "TOP"}}`))
+	})
+
+	_, err := f.GetPointMetadata(context.Background(), 39.700004, -0.00001)
+
+	require.NoError(t, err)
+}
+
+func TestGetPointMetadata_CachesRedirect(t *testing.T) {
+	var redirects int
+	f := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/points/39.7456,-97.0892":
+			redirects++
+			http.Redirect(w, r, "/points/39.7456,-97.0892/canonical", http.StatusMovedPermanently)
+		case "/points/39.7456,-97.0892/canonical":
+			w.Header().Set("Content-Type", "application/geo+json")
+			w.Write([]byte(`{"properties": {"gridId": "TOP"}}`))
+		default:
+			t.Errorf("unexpected request to %s", r.URL.Path)
+		}
+	})
+
+	for i := 0; i < 2; i++ {
+		point, err := f.GetPointMetadata(context.Background(), 39.7456, -97.0892)
+		require.NoError(t, err)
+		assert.Equal(t, "TOP", point.GridID)
+	}
+	assert.Equal(t, 1, redirects)
+}
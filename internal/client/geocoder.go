@@ -0,0 +1,352 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	_ "embed"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/softstone1/fl/internal/model"
+)
+
+// Geocoder resolves place names to places
+type Geocoder interface {
+	// Geocode returns the places best matching query, e.g. "Seattle, WA", the most populated first.
+	// Several places mean the query is ambiguous, none that nothing matched.
+	Geocode(ctx context.Context, query string) ([]model.Place, error)
+	// ReverseGeocode returns the place nearest to the coordinates, with their distance and bearing from it,
+	// or nil when there is no place at all
+	ReverseGeocode(ctx context.Context, lat, lng float64) (*model.RelativeLocation, error)
+}
+
+// maxGeocodeCandidates is the max number of places returned for an ambiguous query
+const maxGeocodeCandidates = 10
+
+//go:embed data/us_places.csv
+var bundledPlaces []byte
+
+// gazetteer is an offline Geocoder matching queries against a list of places
+type gazetteer struct {
+	places []gazetteerPlace
+}
+
+// gazetteerPlace is a place with its normalized name
+type gazetteerPlace struct {
+	model.Place
+	key string
+}
+
+// make sure gazetteer implements the Geocoder interface
+var _ Geocoder = (*gazetteer)(nil)
+
+// NewGazetteer loads the places of the CSV file at path, or the bundled US places when path is empty.
+// The file has a name,state,latitude,longitude[,population] header line and one place per line.
+func NewGazetteer(path string) (*gazetteer, error) {
+	if path == "" {
+		return parseGazetteer(bytes.NewReader(bundledPlaces))
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open gazetteer: %w", err)
+	}
+	defer file.Close()
+	return parseGazetteer(file)
+}
+
+// parseGazetteer reads the places of a gazetteer CSV
+func parseGazetteer(r io.Reader) (*gazetteer, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read gazetteer header: %w", err)
+	}
+	columns := make(map[string]int)
+	for i, name := range header {
+		columns[strings.TrimSpace(name)] = i
+	}
+	for _, name := range []string{"name", "state", "latitude", "longitude"} {
+		if _, ok := columns[name]; !ok {
+			return nil, fmt.Errorf("gazetteer has no %s column", name)
+		}
+	}
+
+	g := &gazetteer{}
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read gazetteer: %w", err)
+		}
+		line, _ := reader.FieldPos(0)
+		field := func(name string) string {
+			i, ok := columns[name]
+			if !ok || i >= len(record) {
+				return ""
+			}
+			return strings.TrimSpace(record[i])
+		}
+
+		place := model.Place{Name: field("name"), State: strings.ToUpper(field("state"))}
+		if place.Latitude, err = strconv.ParseFloat(field("latitude"), 64); err != nil {
+			return nil, fmt.Errorf("invalid gazetteer latitude on line %d: %q", line, field("latitude"))
+		}
+		if place.Longitude, err = strconv.ParseFloat(field("longitude"), 64); err != nil {
+			return nil, fmt.Errorf("invalid gazetteer longitude on line %d: %q", line, field("longitude"))
+		}
+		if population := field("population"); population != "" {
+			if place.Population, err = strconv.Atoi(population); err != nil {
+				return nil, fmt.Errorf("invalid gazetteer population on line %d: %q", line, population)
+			}
+		}
+		g.places = append(g.places, gazetteerPlace{Place: place, key: normalizePlaceName(place.Name)})
+	}
+	return g, nil
+}
+
+// Geocode returns the places whose name matches the query best: exactly, then by prefix, then within
+// a few typos. A state, as a code or name after a comma, restricts the places to that state.
+func (g *gazetteer) Geocode(ctx context.Context, query string) ([]model.Place, error) {
+	name, state := query, ""
+	if i := strings.LastIndex(query, ","); i >= 0 {
+		name = query[:i]
+		var ok bool
+		if state, ok = ParseState(query[i+1:]); !ok {
+			return nil, nil
+		}
+	}
+	key := normalizePlaceName(name)
+	if key == "" {
+		return nil, nil
+	}
+
+	best := -1
+	var matches []model.Place
+	for _, place := range g.places {
+		if state != "" && place.State != state {
+			continue
+		}
+		score, ok := matchPlaceName(place.key, key)
+		if !ok || best >= 0 && score > best {
+			continue
+		}
+		if score < best || best < 0 {
+			best, matches = score, nil
+		}
+		matches = append(matches, place.Place)
+	}
+
+	sort.SliceStable(matches, func(i, j int) bool {
+		return matches[i].Population > matches[j].Population
+	})
+	if len(matches) > maxGeocodeCandidates {
+		matches = matches[:maxGeocodeCandidates]
+	}
+	return matches, nil
+}
+
+// ReverseGeocode returns the gazetteer place nearest to the coordinates
+func (g *gazetteer) ReverseGeocode(ctx context.Context, lat, lng float64) (*model.RelativeLocation, error) {
+	var nearest *gazetteerPlace
+	nearestDistance := math.Inf(1)
+	for i := range g.places {
+		place := &g.places[i]
+		if distance := greatCircleDistance(place.Latitude, place.Longitude, lat, lng); distance < nearestDistance {
+			nearest, nearestDistance = place, distance
+		}
+	}
+	if nearest == nil {
+		return nil, nil
+	}
+
+	bearing := initialBearing(nearest.Latitude, nearest.Longitude, lat, lng)
+	return &model.RelativeLocation{
+		City:     nearest.Name,
+		State:    nearest.State,
+		Distance: model.QuantitativeValue{Value: &nearestDistance, UnitCode: "wmoUnit:m"},
+		Bearing:  model.QuantitativeValue{Value: &bearing, UnitCode: "wmoUnit:degree_(angle)"},
+	}, nil
+}
+
+// earthRadius is the mean radius of the Earth in meters
+const earthRadius = 6371008.8
+
+// greatCircleDistance returns the distance in meters between two coordinates
+func greatCircleDistance(lat1, lng1, lat2, lng2 float64) float64 {
+	phi1, phi2 := lat1*math.Pi/180, lat2*math.Pi/180
+	dPhi, dLambda := phi2-phi1, (lng2-lng1)*math.Pi/180
+	a := math.Sin(dPhi/2)*math.Sin(dPhi/2) + math.Cos(phi1)*math.Cos(phi2)*math.Sin(dLambda/2)*math.Sin(dLambda/2)
+	return 2 * earthRadius * math.Asin(math.Min(1, math.Sqrt(a)))
+}
+
+// initialBearing returns the bearing in degrees, clockwise from north, to go from the first coordinates to the second
+func initialBearing(lat1, lng1, lat2, lng2 float64) float64 {
+	phi1, phi2 := lat1*math.Pi/180, lat2*math.Pi/180
+	dLambda := (lng2 - lng1) * math.Pi / 180
+	y := math.Sin(dLambda) * math.Cos(phi2)
+	x := math.Cos(phi1)*math.Sin(phi2) - math.Sin(phi1)*math.Cos(phi2)*math.Cos(dLambda)
+	return math.Mod(math.Atan2(y, x)*180/math.Pi+360, 360)
+}
+
+// matchPlaceName scores how well the normalized name matches the normalized query, lower is better
+func matchPlaceName(name, query string) (int, bool) {
+	if name == query {
+		return 0, true
+	}
+	if strings.HasPrefix(name, query+" ") {
+		return 1, true
+	}
+	// allow a typo every 4 characters
+	maxDistance := len(query) / 4
+	if maxDistance == 0 {
+		return 0, false
+	}
+	if distance := levenshtein(name, query); distance <= maxDistance {
+		return 1 + distance, true
+	}
+	return 0, false
+}
+
+// placeNameAbbreviations maps the abbreviated words of place names to their normalized form
+var placeNameAbbreviations = map[string]string{
+	"saint": "st",
+	"ste":   "st",
+	"ft":    "fort",
+	"mt":    "mount",
+}
+
+// normalizePlaceName lowercases the name, drops its punctuation and expands its abbreviations
+func normalizePlaceName(name string) string {
+	name = strings.ToLower(name)
+	name = strings.NewReplacer(".", " ", "-", " ", "'", "").Replace(name)
+	words := strings.Fields(name)
+	for i, word := range words {
+		if expanded, ok := placeNameAbbreviations[word]; ok {
+			words[i] = expanded
+		}
+	}
+	return strings.Join(words, " ")
+}
+
+// levenshtein returns the edit distance between a and b
+func levenshtein(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	previous := make([]int, len(rb)+1)
+	current := make([]int, len(rb)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		current[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			current[j] = min(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+		previous, current = current, previous
+	}
+	return previous[len(rb)]
+}
+
+// ParseState returns the two letter code of a US state given by code or name
+func ParseState(state string) (string, bool) {
+	state = strings.TrimSpace(state)
+	if code := strings.ToUpper(state); len(code) == 2 {
+		for _, known := range stateCodes {
+			if known == code {
+				return code, true
+			}
+		}
+		return "", false
+	}
+	code, ok := stateCodes[normalizePlaceName(state)]
+	return code, ok
+}
+
+// stateCodes maps the normalized US state names to their codes
+var stateCodes = map[string]string{
+	"alabama": "AL", "alaska": "AK", "arizona": "AZ", "arkansas": "AR", "california": "CA",
+	"colorado": "CO", "connecticut": "CT", "delaware": "DE", "district of columbia": "DC", "florida": "FL",
+	"georgia": "GA", "hawaii": "HI", "idaho": "ID", "illinois": "IL", "indiana": "IN",
+	"iowa": "IA", "kansas": "KS", "kentucky": "KY", "louisiana": "LA", "maine": "ME",
+	"maryland": "MD", "massachusetts": "MA", "michigan": "MI", "minnesota": "MN", "mississippi": "MS",
+	"missouri": "MO", "montana": "MT", "nebraska": "NE", "nevada": "NV", "new hampshire": "NH",
+	"new jersey": "NJ", "new mexico": "NM", "new york": "NY", "north carolina": "NC", "north dakota": "ND",
+	"ohio": "OH", "oklahoma": "OK", "oregon": "OR", "pennsylvania": "PA", "rhode island": "RI",
+	"south carolina": "SC", "south dakota": "SD", "tennessee": "TN", "texas": "TX", "utah": "UT",
+	"vermont": "VT", "virginia": "VA", "washington": "WA", "west virginia": "WV", "wisconsin": "WI",
+	"wyoming": "WY", "puerto rico": "PR",
+}
+
+// StateBoundingBoxes returns the bounding boxes of a US state given by its two letter code, none for an unknown code
+func StateBoundingBoxes(code string) []BoundingBox {
+	return stateBoxes[code]
+}
+
+// stateBoxes are the bounding boxes of the US states, two for Alaska which crosses the antimeridian
+var stateBoxes = map[string][]BoundingBox{
+	"AL": {{South: 30.14, West: -88.47, North: 35.01, East: -84.89}},
+	"AK": {{South: 51.21, West: -180, North: 71.39, East: -129.99}, {South: 51.21, West: 172.44, North: 53.02, East: 180}},
+	"AZ": {{South: 31.33, West: -114.82, North: 37.00, East: -109.04}},
+	"AR": {{South: 33.00, West: -94.62, North: 36.50, East: -89.64}},
+	"CA": {{South: 32.53, West: -124.41, North: 42.01, East: -114.13}},
+	"CO": {{South: 36.99, West: -109.06, North: 41.00, East: -102.04}},
+	"CT": {{South: 40.95, West: -73.73, North: 42.05, East: -71.79}},
+	"DE": {{South: 38.45, West: -75.79, North: 39.84, East: -75.05}},
+	"DC": {{South: 38.79, West: -77.12, North: 38.99, East: -76.91}},
+	"FL": {{South: 24.52, West: -87.63, North: 31.00, East: -80.03}},
+	"GA": {{South: 30.36, West: -85.61, North: 35.00, East: -80.84}},
+	"HI": {{South: 18.91, West: -160.25, North: 22.24, East: -154.81}},
+	"ID": {{South: 41.99, West: -117.24, North: 49.00, East: -111.04}},
+	"IL": {{South: 36.97, West: -91.51, North: 42.51, East: -87.49}},
+	"IN": {{South: 37.77, West: -88.10, North: 41.76, East: -84.78}},
+	"IA": {{South: 40.38, West: -96.64, North: 43.50, East: -90.14}},
+	"KS": {{South: 36.99, West: -102.05, North: 40.00, East: -94.59}},
+	"KY": {{South: 36.50, West: -89.57, North: 39.15, East: -81.96}},
+	"LA": {{South: 28.93, West: -94.04, North: 33.02, East: -88.82}},
+	"ME": {{South: 43.06, West: -71.08, North: 47.46, East: -66.95}},
+	"MD": {{South: 37.91, West: -79.49, North: 39.72, East: -75.05}},
+	"MA": {{South: 41.24, West: -73.51, North: 42.89, East: -69.93}},
+	"MI": {{South: 41.70, West: -90.42, North: 48.31, East: -82.41}},
+	"MN": {{South: 43.50, West: -97.24, North: 49.38, East: -89.49}},
+	"MS": {{South: 30.17, West: -91.66, North: 35.00, East: -88.10}},
+	"MO": {{South: 35.99, West: -95.77, North: 40.61, East: -89.10}},
+	"MT": {{South: 44.36, West: -116.05, North: 49.00, East: -104.04}},
+	"NE": {{South: 40.00, West: -104.05, North: 43.00, East: -95.31}},
+	"NV": {{South: 35.00, West: -120.01, North: 42.00, East: -114.04}},
+	"NH": {{South: 42.70, West: -72.56, North: 45.31, East: -70.61}},
+	"NJ": {{South: 38.93, West: -75.56, North: 41.36, East: -73.89}},
+	"NM": {{South: 31.33, West: -109.05, North: 37.00, East: -103.00}},
+	"NY": {{South: 40.50, West: -79.76, North: 45.02, East: -71.86}},
+	"NC": {{South: 33.84, West: -84.32, North: 36.59, East: -75.46}},
+	"ND": {{South: 45.94, West: -104.05, North: 49.00, East: -96.55}},
+	"OH": {{South: 38.40, West: -84.82, North: 41.98, East: -80.52}},
+	"OK": {{South: 33.62, West: -103.00, North: 37.00, East: -94.43}},
+	"OR": {{South: 41.99, West: -124.57, North: 46.29, East: -116.46}},
+	"PA": {{South: 39.72, West: -80.52, North: 42.27, East: -74.69}},
+	"RI": {{South: 41.15, West: -71.91, North: 42.02, East: -71.12}},
+	"SC": {{South: 32.03, West: -83.35, North: 35.22, East: -78.54}},
+	"SD": {{South: 42.48, West: -104.06, North: 45.95, East: -96.44}},
+	"TN": {{South: 34.98, West: -90.31, North: 36.68, East: -81.65}},
+	"TX": {{South: 25.84, West: -106.65, North: 36.50, East: -93.51}},
+	"UT": {{South: 37.00, West: -114.05, North: 42.00, East: -109.04}},
+	"VT": {{South: 42.73, West: -73.44, North: 45.02, East: -71.46}},
+	"VA": {{South: 36.54, West: -83.68, North: 39.47, East: -75.24}},
+	"WA": {{South: 45.54, West: -124.85, North: 49.00, East: -116.92}},
+	"WV": {{South: 37.20, West: -82.64, North: 40.64, East: -77.72}},
+	"WI": {{South: 42.49, West: -92.89, North: 47.31, East: -86.25}},
+	"WY": {{South: 40.99, West: -111.06, North: 45.01, East: -104.05}},
+	"PR": {{South: 17.88, West: -67.95, North: 18.52, East: -65.22}},
+}
@@ -0,0 +1,97 @@
+package client
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGazetteer_Geocode(t *testing.T) {
+	g, err := NewGazetteer("")
+	require.NoError(t, err)
+
+	tests := []struct {
+		name     string
+		query    string
+		expected []string
+	}{
+		{name: "Name and state code", query: "Seattle,WA", expected: []string{"Seattle, WA"}},
+		{name: "State name", query: "springfield, Illinois", expected: []string{"Springfield, IL"}},
+		{name: "Abbreviation", query: "Saint Louis", expected: []string{"St. Louis, MO"}},
+		{name: "Typo", query: "Albuquerqe", expected: []string{"Albuquerque, NM"}},
+		{name: "Ambiguous name, most populated first", query: "Portland", expected: []string{"Portland, OR", "Portland, ME"}},
+		{name: "Prefix", query: "Salt Lake", expected: []string{"Salt Lake City, UT"}},
+		{name: "Exact match wins over prefix", query: "Columbia, MO", expected: []string{"Columbia, MO"}},
+		{name: "Unknown state", query: "Seattle, XX", expected: nil},
+		{name: "No match", query: "Gotham", expected: nil},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			places, err := g.Geocode(context.Background(), tc.query)
+			require.NoError(t, err)
+
+			var names []string
+			for _, place := range places {
+				names = append(names, place.Location().Name)
+			}
+			assert.Equal(t, tc.expected, names)
+		})
+	}
+}
+
+func TestGazetteer_ReverseGeocode(t *testing.T) {
+	g, err := NewGazetteer("")
+	require.NoError(t, err)
+
+	// 0.09° of latitude, 10 km, north of Boulder
+	nearest, err := g.ReverseGeocode(context.Background(), 40.105, -105.2705)
+
+	require.NoError(t, err)
+	assert.Equal(t, "Boulder", nearest.City)
+	assert.Equal(t, "CO", nearest.State)
+	assert.Equal(t, "wmoUnit:m", nearest.Distance.UnitCode)
+	assert.InDelta(t, 10008, *nearest.Distance.Value, 1)
+	assert.InDelta(t, 0, *nearest.Bearing.Value, 0.01)
+
+	empty, err := parseGazetteer(strings.NewReader("name,state,latitude,longitude\n"))
+	require.NoError(t, err)
+	nearest, err = empty.ReverseGeocode(context.Background(), 40.065, -105.345)
+	assert.NoError(t, err)
+	assert.Nil(t, nearest)
+}
+
+func TestParseGazetteer(t *testing.T) {
+	g, err := parseGazetteer(strings.NewReader("name,state,latitude,longitude\nLinn,ks,39.6814,-97.0864\n"))
+	require.NoError(t, err)
+
+	places, err := g.Geocode(context.Background(), "Linn, KS")
+	require.NoError(t, err)
+	require.Len(t, places, 1)
+	assert.Equal(t, 39.6814, places[0].Latitude)
+	assert.Equal(t, -97.0864, places[0].Longitude)
+
+	_, err = parseGazetteer(strings.NewReader("name,state,latitude,longitude\nLinn,KS,north,-97.0864\n"))
+	assert.ErrorContains(t, err, `invalid gazetteer latitude on line 2: "north"`)
+
+	_, err = parseGazetteer(strings.NewReader("name,latitude,longitude\n"))
+	assert.ErrorContains(t, err, "gazetteer has no state column")
+}
+
+func TestStateBoundingBoxes(t *testing.T) {
+	g, err := NewGazetteer("")
+	require.NoError(t, err)
+
+	// every bundled place is in the bounding boxes of its state
+	for _, place := range g.places {
+		inside := false
+		for _, box := range StateBoundingBoxes(place.State) {
+			inside = inside || box.Contains(place.Latitude, place.Longitude)
+		}
+		assert.True(t, inside, "%s, %s", place.Name, place.State)
+	}
+	assert.Empty(t, StateBoundingBoxes("XX"))
+}
@@ -0,0 +1,128 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/softstone1/fl/internal/model"
+)
+
+// GridDataResponse represents the top-level JSON structure. The layers are decoded from the raw properties,
+// keeping the numerical ones.
+type GridDataResponse struct {
+	Properties map[string]json.RawMessage `json:"properties"`
+}
+
+// GridLayerResponse is a gridpoint layer as returned by NWS
+type GridLayerResponse struct {
+	UnitCode string              `json:"uom"`
+	Values   []GridValueResponse `json:"values"`
+}
+
+// GridValueResponse is a gridpoint layer value over an ISO 8601 interval such as "2025-01-01T12:00:00+00:00/PT6H"
+type GridValueResponse struct {
+	ValidTime string   `json:"validTime"`
+	Value     *float64 `json:"value"`
+}
+
+// GetGridData returns the numerical gridpoint layers for a given forecast grid data URL
+func (f *forecast) GetGridData(ctx context.Context, gridDataURL string) (*model.GridData, error) {
+	gridDataResponse := &GridDataResponse{}
+	resp, err := f.client.R().
+		SetResult(gridDataResponse).
+		SetContext(ctx).
+		SetHeader("Accept", "application/geo+json").
+		Get(gridDataURL)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch grid data: %w", err)
+	}
+
+	if resp.StatusCode() != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode())
+	}
+	return parseGridData(gridDataResponse.Properties)
+}
+
+// parseGridData decodes the numerical layers of the grid data properties. Properties that are not
+// numerical layers, such as weather or hazards, are skipped, and so are the values whose valid time
+// cannot be parsed.
+func parseGridData(properties map[string]json.RawMessage) (*model.GridData, error) {
+	gridData := &model.GridData{Layers: make(map[string]model.GridLayer)}
+	if updateTime, ok := properties["updateTime"]; ok {
+		if err := json.Unmarshal(updateTime, &gridData.UpdateTime); err != nil {
+			return nil, fmt.Errorf("failed to decode grid data update time: %w", err)
+		}
+	}
+
+	for name, raw := range properties {
+		var layerResponse GridLayerResponse
+		if err := json.Unmarshal(raw, &layerResponse); err != nil || layerResponse.Values == nil {
+			continue
+		}
+		layer := model.GridLayer{
+			UnitCode: layerResponse.UnitCode,
+			Values:   make([]model.GridValue, 0, len(layerResponse.Values)),
+		}
+		for _, value := range layerResponse.Values {
+			startTime, duration, err := ParseValidTime(value.ValidTime)
+			if err != nil {
+				log.Printf("Skipping %s grid data value: %v", name, err)
+				continue
+			}
+			layer.Values = append(layer.Values, model.GridValue{StartTime: startTime, Duration: duration, Value: value.Value})
+		}
+		gridData.Layers[name] = layer
+	}
+	return gridData, nil
+}
+
+// ParseValidTime parses an ISO 8601 interval made of a start time and a duration,
+// e.g. "2025-01-01T12:00:00+00:00/PT6H"
+func ParseValidTime(validTime string) (time.Time, time.Duration, error) {
+	start, period, found := strings.Cut(validTime, "/")
+	if !found {
+		return time.Time{}, 0, fmt.Errorf("invalid valid time: %q", validTime)
+	}
+	startTime, err := time.Parse(time.RFC3339, start)
+	if err != nil {
+		return time.Time{}, 0, fmt.Errorf("invalid valid time: %q", validTime)
+	}
+	duration, err := ParseDuration(period)
+	if err != nil {
+		return time.Time{}, 0, fmt.Errorf("invalid valid time: %q", validTime)
+	}
+	return startTime, duration, nil
+}
+
+// isoDuration matches the ISO 8601 durations NWS uses, in weeks, days, hours, minutes and seconds
+var isoDuration = regexp.MustCompile(`^P(?:(\d+)W)?(?:(\d+)D)?(?:T(?:(\d+)H)?(?:(\d+)M)?(?:(\d+(?:\.\d+)?)S)?)?$`)
+
+// ParseDuration parses an ISO 8601 duration such as "P1DT6H". Years and months are not supported
+// since their length varies.
+func ParseDuration(period string) (time.Duration, error) {
+	parts := isoDuration.FindStringSubmatch(period)
+	if parts == nil || period == "P" || strings.HasSuffix(period, "T") {
+		return 0, fmt.Errorf("invalid duration: %q", period)
+	}
+	units := []time.Duration{7 * 24 * time.Hour, 24 * time.Hour, time.Hour, time.Minute, time.Second}
+	var duration time.Duration
+	for i, unit := range units {
+		if parts[i+1] == "" {
+			continue
+		}
+		value, err := strconv.ParseFloat(parts[i+1], 64)
+		if err != nil {
+			return 0, fmt.Errorf("invalid duration: %q", period)
+		}
+		duration += time.Duration(value * float64(unit))
+	}
+	return duration, nil
+}
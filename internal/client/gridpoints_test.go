@@ -0,0 +1,102 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseValidTime(t *testing.T) {
+	tests := []struct {
+		validTime        string
+		expectedStart    time.Time
+		expectedDuration time.Duration
+		expectErr        bool
+	}{
+		{
+			validTime:        "2025-01-01T12:00:00+00:00/PT1H",
+			expectedStart:    time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC),
+			expectedDuration: time.Hour,
+		},
+		{
+			validTime:        "2025-01-01T06:00:00-06:00/P1DT6H",
+			expectedStart:    time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC),
+			expectedDuration: 30 * time.Hour,
+		},
+		{
+			validTime:        "2025-01-01T12:00:00+00:00/PT1H30M15S",
+			expectedStart:    time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC),
+			expectedDuration: time.Hour + 30*time.Minute + 15*time.Second,
+		},
+		{
+			validTime:        "2025-01-01T12:00:00+00:00/P1W",
+			expectedStart:    time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC),
+			expectedDuration: 7 * 24 * time.Hour,
+		},
+		{validTime: "2025-01-01T12:00:00+00:00", expectErr: true},
+		{validTime: "2025-01-01T12:00:00+00:00/P1M", expectErr: true},
+		{validTime: "2025-01-01T12:00:00+00:00/PT", expectErr: true},
+		{validTime: "2025-01-01T12:00:00+00:00/P", expectErr: true},
+		{validTime: "yesterday/PT1H", expectErr: true},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.validTime, func(t *testing.T) {
+			start, duration, err := ParseValidTime(tc.validTime)
+			if tc.expectErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.True(t, tc.expectedStart.Equal(start), "start %s", start)
+			assert.Equal(t, tc.expectedDuration, duration)
+		})
+	}
+}
+
+func TestGetGridData(t *testing.T) {
+	f := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/gridpoints/TOP/32,81", r.URL.Path)
+		w.Header().Set("Content-Type", "application/geo+json")
+		w.Write([]byte(`{
+			"properties": {
+				"updateTime": "2025-01-01T10:00:00+00:00",
+				"validTimes": "2025-01-01T06:00:00+00:00/P7DT19H",
+				"elevation": {"unitCode": "wmoUnit:m", "value": 441.96},
+				"temperature": {
+					"uom": "wmoUnit:degC",
+					"values": [
+						{"validTime": "2025-01-01T12:00:00+00:00/PT2H", "value": 1.5},
+						{"validTime": "2025-01-01T13:00:00+00:00/P1M", "value": 2},
+						{"validTime": "2025-01-01T14:00:00+00:00/PT1H", "value": null}
+					]
+				},
+				"weather": {
+					"values": [
+						{"validTime": "2025-01-01T12:00:00+00:00/PT6H", "value": [{"coverage": "chance", "weather": "snow"}]}
+					]
+				}
+			}
+		}`))
+	})
+
+	gridData, err := f.GetGridData(context.Background(), f.client.BaseURL+"/gridpoints/TOP/32,81")
+
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2025, 1, 1, 10, 0, 0, 0, time.UTC), gridData.UpdateTime.UTC())
+	require.Contains(t, gridData.Layers, "temperature")
+	assert.NotContains(t, gridData.Layers, "weather")
+	assert.NotContains(t, gridData.Layers, "elevation")
+
+	temperature := gridData.Layers["temperature"]
+	assert.Equal(t, "wmoUnit:degC", temperature.UnitCode)
+	// the value whose valid time cannot be parsed is skipped
+	require.Len(t, temperature.Values, 2)
+	assert.Equal(t, 2*time.Hour, temperature.Values[0].Duration)
+	assert.Equal(t, 1.5, *temperature.Values[0].Value)
+	assert.Nil(t, temperature.Values[1].Value)
+}
@@ -0,0 +1,216 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"math/rand"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/softstone1/fl/internal/model"
+)
+
+// maxAreaAttempts is how many random points are drawn in the bounding box of polygons before giving up
+const maxAreaAttempts = 1000
+
+// BoundingBox is an area between two latitudes and two longitudes
+type BoundingBox struct {
+	South, West, North, East float64
+}
+
+// ParseBoundingBox parses a "south,west,north,east" bounding box in degrees
+func ParseBoundingBox(value string) (BoundingBox, error) {
+	fields := strings.Split(value, ",")
+	if len(fields) != 4 {
+		return BoundingBox{}, fmt.Errorf("expected south,west,north,east, got %q", value)
+	}
+	var coordinates [4]float64
+	for i, field := range fields {
+		coordinate, err := strconv.ParseFloat(strings.TrimSpace(field), 64)
+		if err != nil {
+			return BoundingBox{}, fmt.Errorf("invalid bounding box coordinate %q", field)
+		}
+		coordinates[i] = coordinate
+	}
+	bbox := BoundingBox{South: coordinates[0], West: coordinates[1], North: coordinates[2], East: coordinates[3]}
+	if bbox.South < -90 || bbox.North > 90 || bbox.South >= bbox.North {
+		return BoundingBox{}, fmt.Errorf("invalid bounding box latitudes in %q", value)
+	}
+	if bbox.West < -180 || bbox.East > 180 || bbox.West >= bbox.East {
+		return BoundingBox{}, fmt.Errorf("invalid bounding box longitudes in %q", value)
+	}
+	return bbox, nil
+}
+
+// Contains reports whether the point lies in the bounding box
+func (b BoundingBox) Contains(lat, lng float64) bool {
+	return lat >= b.South && lat <= b.North && lng >= b.West && lng <= b.East
+}
+
+// ring is a closed polygon ring of [longitude, latitude] positions
+type ring [][2]float64
+
+// Polygons holds the rings of each polygon, the first one outer and the others holes
+type Polygons [][]ring
+
+// areaLocations generates random points in a bounding box, or in polygons
+type areaLocations struct {
+	bbox BoundingBox
+	// polygons is nil for the whole box
+	polygons Polygons
+	mu       sync.Mutex
+	rand     *rand.Rand
+}
+
+// make sure areaLocations implements the Location interface
+var _ Location = (*areaLocations)(nil)
+
+// NewBoundingBoxLocations generates random points evenly spread over the bounding box
+func NewBoundingBoxLocations(bbox BoundingBox) *areaLocations {
+	return &areaLocations{
+		bbox: bbox,
+		rand: rand.New(rand.NewSource(time.Now().UnixNano())),
+	}
+}
+
+// NewPolygonLocations generates random points evenly spread over the polygons of the GeoJSON file at path,
+// see LoadPolygons
+func NewPolygonLocations(path string) (*areaLocations, error) {
+	polygons, err := LoadPolygons(path)
+	if err != nil {
+		return nil, err
+	}
+
+	a := &areaLocations{
+		bbox:     BoundingBox{South: 90, West: 180, North: -90, East: -180},
+		polygons: polygons,
+		rand:     rand.New(rand.NewSource(time.Now().UnixNano())),
+	}
+	for _, polygon := range polygons {
+		for _, position := range polygon[0] {
+			a.bbox.West, a.bbox.East = math.Min(a.bbox.West, position[0]), math.Max(a.bbox.East, position[0])
+			a.bbox.South, a.bbox.North = math.Min(a.bbox.South, position[1]), math.Max(a.bbox.North, position[1])
+		}
+	}
+	return a, nil
+}
+
+// LoadPolygons reads the Polygon and MultiPolygon geometries of the GeoJSON file at path,
+// which holds a geometry, a feature or a feature collection
+func LoadPolygons(path string) (Polygons, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read polygon file: %w", err)
+	}
+	object := geoJSONObject{}
+	if err := json.Unmarshal(data, &object); err != nil {
+		return nil, fmt.Errorf("%s: failed to decode polygons: %w", path, err)
+	}
+	polygons, err := geoJSONPolygons(object)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	if len(polygons) == 0 {
+		return nil, fmt.Errorf("%s: no polygons", path)
+	}
+	return polygons, nil
+}
+
+// geoJSONPolygons returns the polygons of a GeoJSON object
+func geoJSONPolygons(object geoJSONObject) (Polygons, error) {
+	switch object.Type {
+	case "FeatureCollection":
+		var polygons Polygons
+		for _, feature := range object.Features {
+			featurePolygons, err := geoJSONPolygons(feature)
+			if err != nil {
+				return nil, err
+			}
+			polygons = append(polygons, featurePolygons...)
+		}
+		return polygons, nil
+	case "Feature":
+		if object.Geometry == nil {
+			return nil, errors.New("feature without geometry")
+		}
+		return geoJSONPolygons(*object.Geometry)
+	case "Polygon":
+		var polygon []ring
+		if err := json.Unmarshal(object.Coordinates, &polygon); err != nil || len(polygon) == 0 {
+			return nil, errors.New("invalid Polygon coordinates")
+		}
+		return Polygons{polygon}, nil
+	case "MultiPolygon":
+		var polygons Polygons
+		if err := json.Unmarshal(object.Coordinates, &polygons); err != nil {
+			return nil, errors.New("invalid MultiPolygon coordinates")
+		}
+		for _, polygon := range polygons {
+			if len(polygon) == 0 {
+				return nil, errors.New("invalid MultiPolygon coordinates")
+			}
+		}
+		return polygons, nil
+	default:
+		return nil, fmt.Errorf("unsupported GeoJSON type %q", object.Type)
+	}
+}
+
+// GetRandomLocation returns a random point of the area
+func (a *areaLocations) GetRandomLocation(ctx context.Context) (*model.Location, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	for attempt := 0; attempt < maxAreaAttempts; attempt++ {
+		lat, lng := a.randomPoint()
+		if a.polygons == nil || a.polygons.Contains(lat, lng) {
+			return &model.Location{Latitude: lat, Longitude: lng}, nil
+		}
+	}
+	return nil, errors.New("no random point found in the polygons")
+}
+
+// randomPoint returns a point of the bounding box, evenly spread over the surface of the Earth rather than
+// over the degrees, which would crowd the points toward the poles
+func (a *areaLocations) randomPoint() (float64, float64) {
+	minZ, maxZ := math.Sin(a.bbox.South*math.Pi/180), math.Sin(a.bbox.North*math.Pi/180)
+	lat := math.Asin(minZ+a.rand.Float64()*(maxZ-minZ)) * 180 / math.Pi
+	lng := a.bbox.West + a.rand.Float64()*(a.bbox.East-a.bbox.West)
+	return lat, lng
+}
+
+// Contains reports whether the point lies in one of the polygons, inside its outer ring and outside its holes
+func (p Polygons) Contains(lat, lng float64) bool {
+	for _, polygon := range p {
+		inside := false
+		for _, r := range polygon {
+			if r.contains(lat, lng) {
+				// crossing each ring, holes included, flips whether the point is inside
+				inside = !inside
+			}
+		}
+		if inside {
+			return true
+		}
+	}
+	return false
+}
+
+// contains reports whether the point lies in the ring, by counting the ring edges a ray from the point crosses
+func (r ring) contains(lat, lng float64) bool {
+	inside := false
+	for i, j := 0, len(r)-1; i < len(r); j, i = i, i+1 {
+		xi, yi := r[i][0], r[i][1]
+		xj, yj := r[j][0], r[j][1]
+		if (yi > lat) != (yj > lat) && lng < (xj-xi)*(lat-yi)/(yj-yi)+xi {
+			inside = !inside
+		}
+	}
+	return inside
+}
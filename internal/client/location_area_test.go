@@ -0,0 +1,58 @@
+package client
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseBoundingBox(t *testing.T) {
+	bbox, err := ParseBoundingBox("37, -102.05, 40, -94.6")
+	require.NoError(t, err)
+	assert.Equal(t, BoundingBox{South: 37, West: -102.05, North: 40, East: -94.6}, bbox)
+
+	for _, value := range []string{"37,-102.05,40", "40,-102.05,37,-94.6", "37,-94.6,40,-102.05", "37,west,40,-94.6", "-91,0,0,1"} {
+		_, err := ParseBoundingBox(value)
+		assert.Error(t, err, value)
+	}
+}
+
+func TestBoundingBoxLocations(t *testing.T) {
+	bbox := BoundingBox{South: 37, West: -102.05, North: 40, East: -94.6}
+	a := NewBoundingBoxLocations(bbox)
+
+	for i := 0; i < 1000; i++ {
+		location, err := a.GetRandomLocation(context.Background())
+		require.NoError(t, err)
+		assert.True(t, location.Latitude >= bbox.South && location.Latitude <= bbox.North, location.Latitude)
+		assert.True(t, location.Longitude >= bbox.West && location.Longitude <= bbox.East, location.Longitude)
+	}
+}
+
+func TestPolygonLocations(t *testing.T) {
+	// a 10° square with a hole covering its western half
+	path := writeLocationsFile(t, "area.geojson", `{
+		"type": "Feature",
+		"geometry": {
+			"type": "Polygon",
+			"coordinates": [
+				[[-100, 30], [-90, 30], [-90, 40], [-100, 40], [-100, 30]],
+				[[-100, 30], [-95, 30], [-95, 40], [-100, 40], [-100, 30]]
+			]
+		}
+	}`)
+	a, err := NewPolygonLocations(path)
+	require.NoError(t, err)
+
+	for i := 0; i < 1000; i++ {
+		location, err := a.GetRandomLocation(context.Background())
+		require.NoError(t, err)
+		assert.True(t, location.Latitude >= 30 && location.Latitude <= 40, location.Latitude)
+		assert.True(t, location.Longitude >= -95 && location.Longitude <= -90, location.Longitude)
+	}
+
+	_, err = NewPolygonLocations(writeLocationsFile(t, "point.geojson", `{"type": "Point", "coordinates": [0, 0]}`))
+	assert.ErrorContains(t, err, `unsupported GeoJSON type "Point"`)
+}
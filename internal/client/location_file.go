@@ -0,0 +1,245 @@
+package client
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/softstone1/fl/internal/model"
+)
+
+// weightedLocation is a location of a locations file with its sampling weight
+type weightedLocation struct {
+	model.Location
+	weight float64
+}
+
+// fileLocations samples the locations of a file, by weight when the file gives any, uniformly otherwise
+type fileLocations struct {
+	locations []model.Location
+	// cumulative holds the running sum of the weights, nil when sampling uniformly
+	cumulative []float64
+	mu         sync.Mutex
+	rand       *rand.Rand
+}
+
+// make sure fileLocations implements the Location interface
+var _ Location = (*fileLocations)(nil)
+
+// NewFileLocations loads the locations file at path, see loadLocationsFile, to sample random locations from
+func NewFileLocations(path string) (*fileLocations, error) {
+	locations, err := loadLocationsFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	f := &fileLocations{rand: rand.New(rand.NewSource(time.Now().UnixNano()))}
+	weighted := false
+	for _, location := range locations {
+		if location.weight > 0 {
+			weighted = true
+		}
+	}
+	total := 0.0
+	for _, location := range locations {
+		if weighted {
+			if location.weight <= 0 {
+				// locations without a weight are never sampled
+				continue
+			}
+			total += location.weight
+			f.cumulative = append(f.cumulative, total)
+		}
+		f.locations = append(f.locations, location.Location)
+	}
+	return f, nil
+}
+
+// GetRandomLocation returns one of the locations of the file
+func (f *fileLocations) GetRandomLocation(ctx context.Context) (*model.Location, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	i := 0
+	if f.cumulative != nil {
+		target := f.rand.Float64() * f.cumulative[len(f.cumulative)-1]
+		i = sort.SearchFloat64s(f.cumulative, target)
+		if i < len(f.cumulative) && f.cumulative[i] == target {
+			// a target on a boundary belongs to the next location
+			i++
+		}
+	} else {
+		i = f.rand.Intn(len(f.locations))
+	}
+	location := f.locations[i]
+	return &location, nil
+}
+
+// roundRobinLocations returns the locations of a file one after the other, starting over after the last one
+type roundRobinLocations struct {
+	locations []model.Location
+	mu        sync.Mutex
+	next      int
+}
+
+// make sure roundRobinLocations implements the Location interface
+var _ Location = (*roundRobinLocations)(nil)
+
+// NewRoundRobinLocations loads the locations file at path, see loadLocationsFile, to iterate over
+func NewRoundRobinLocations(path string) (*roundRobinLocations, error) {
+	locations, err := loadLocationsFile(path)
+	if err != nil {
+		return nil, err
+	}
+	r := &roundRobinLocations{}
+	for _, location := range locations {
+		r.locations = append(r.locations, location.Location)
+	}
+	return r, nil
+}
+
+// GetRandomLocation returns the next location of the file
+func (r *roundRobinLocations) GetRandomLocation(ctx context.Context) (*model.Location, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	location := r.locations[r.next]
+	r.next = (r.next + 1) % len(r.locations)
+	return &location, nil
+}
+
+// loadLocationsFile reads the locations of a CSV file with a name,latitude,longitude[,weight] header line,
+// or of a GeoJSON file, ending with .json or .geojson, of Point features with optional name and weight properties
+func loadLocationsFile(path string) ([]weightedLocation, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open locations file: %w", err)
+	}
+	defer file.Close()
+
+	var locations []weightedLocation
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json", ".geojson":
+		locations, err = parseGeoJSONLocations(file)
+	default:
+		locations, err = parseCSVLocations(file)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	if len(locations) == 0 {
+		return nil, fmt.Errorf("%s: no locations", path)
+	}
+	for i, location := range locations {
+		if location.Latitude < -90 || location.Latitude > 90 || location.Longitude < -180 || location.Longitude > 180 {
+			return nil, fmt.Errorf("%s: location %d is out of range: %v,%v", path, i+1, location.Latitude, location.Longitude)
+		}
+		if location.weight < 0 {
+			return nil, fmt.Errorf("%s: location %d has a negative weight", path, i+1)
+		}
+	}
+	return locations, nil
+}
+
+// parseCSVLocations reads the locations of a CSV file
+func parseCSVLocations(r io.Reader) ([]weightedLocation, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read locations header: %w", err)
+	}
+	columns := make(map[string]int)
+	for i, name := range header {
+		columns[strings.TrimSpace(name)] = i
+	}
+	for _, name := range []string{"latitude", "longitude"} {
+		if _, ok := columns[name]; !ok {
+			return nil, fmt.Errorf("locations have no %s column", name)
+		}
+	}
+
+	var locations []weightedLocation
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read locations: %w", err)
+		}
+		line, _ := reader.FieldPos(0)
+		field := func(name string) string {
+			i, ok := columns[name]
+			if !ok || i >= len(record) {
+				return ""
+			}
+			return strings.TrimSpace(record[i])
+		}
+
+		location := weightedLocation{Location: model.Location{Name: field("name")}}
+		if location.Latitude, err = strconv.ParseFloat(field("latitude"), 64); err != nil {
+			return nil, fmt.Errorf("invalid latitude on line %d: %q", line, field("latitude"))
+		}
+		if location.Longitude, err = strconv.ParseFloat(field("longitude"), 64); err != nil {
+			return nil, fmt.Errorf("invalid longitude on line %d: %q", line, field("longitude"))
+		}
+		if weight := field("weight"); weight != "" {
+			if location.weight, err = strconv.ParseFloat(weight, 64); err != nil {
+				return nil, fmt.Errorf("invalid weight on line %d: %q", line, weight)
+			}
+		}
+		locations = append(locations, location)
+	}
+	return locations, nil
+}
+
+// geoJSONObject is the subset of a GeoJSON feature collection, feature or geometry used for locations and areas
+type geoJSONObject struct {
+	Type        string          `json:"type"`
+	Features    []geoJSONObject `json:"features"`
+	Geometry    *geoJSONObject  `json:"geometry"`
+	Coordinates json.RawMessage `json:"coordinates"`
+	Properties  struct {
+		Name   string  `json:"name"`
+		Weight float64 `json:"weight"`
+	} `json:"properties"`
+}
+
+// parseGeoJSONLocations reads the Point features of a GeoJSON feature collection
+func parseGeoJSONLocations(r io.Reader) ([]weightedLocation, error) {
+	collection := geoJSONObject{}
+	if err := json.NewDecoder(r).Decode(&collection); err != nil {
+		return nil, fmt.Errorf("failed to decode locations: %w", err)
+	}
+	if collection.Type != "FeatureCollection" {
+		return nil, fmt.Errorf("expected a FeatureCollection, got %q", collection.Type)
+	}
+
+	var locations []weightedLocation
+	for i, feature := range collection.Features {
+		if feature.Geometry == nil || feature.Geometry.Type != "Point" {
+			return nil, fmt.Errorf("feature %d is not a Point", i+1)
+		}
+		var coordinates []float64
+		if err := json.Unmarshal(feature.Geometry.Coordinates, &coordinates); err != nil || len(coordinates) < 2 {
+			return nil, fmt.Errorf("feature %d has invalid coordinates", i+1)
+		}
+		locations = append(locations, weightedLocation{
+			// GeoJSON positions are longitude first
+			Location: model.Location{Name: feature.Properties.Name, Latitude: coordinates[1], Longitude: coordinates[0]},
+			weight:   feature.Properties.Weight,
+		})
+	}
+	return locations, nil
+}
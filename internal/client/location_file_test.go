@@ -0,0 +1,99 @@
+package client
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeLocationsFile(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	return path
+}
+
+func TestFileLocations_Weighted(t *testing.T) {
+	path := writeLocationsFile(t, "locations.csv", "name,latitude,longitude,weight\n"+
+		"Topeka,39.0473,-95.6752,3\n"+
+		"Never,0,0,0\n"+
+		"Linn,39.6814,-97.0864,1\n")
+	f, err := NewFileLocations(path)
+	require.NoError(t, err)
+
+	counts := map[string]int{}
+	for i := 0; i < 4000; i++ {
+		location, err := f.GetRandomLocation(context.Background())
+		require.NoError(t, err)
+		counts[location.Name]++
+	}
+	assert.Zero(t, counts["Never"])
+	assert.InDelta(t, 3000, counts["Topeka"], 200)
+	assert.InDelta(t, 1000, counts["Linn"], 200)
+}
+
+func TestFileLocations_UniformGeoJSON(t *testing.T) {
+	path := writeLocationsFile(t, "locations.geojson", `{
+		"type": "FeatureCollection",
+		"features": [
+			{"type": "Feature", "geometry": {"type": "Point", "coordinates": [-95.6752, 39.0473]}, "properties": {"name": "Topeka"}},
+			{"type": "Feature", "geometry": {"type": "Point", "coordinates": [-97.0864, 39.6814]}, "properties": {"name": "Linn"}}
+		]
+	}`)
+	f, err := NewFileLocations(path)
+	require.NoError(t, err)
+
+	counts := map[string]int{}
+	for i := 0; i < 2000; i++ {
+		location, err := f.GetRandomLocation(context.Background())
+		require.NoError(t, err)
+		counts[location.Name]++
+		if location.Name == "Topeka" {
+			assert.Equal(t, 39.0473, location.Latitude)
+			assert.Equal(t, -95.6752, location.Longitude)
+		}
+	}
+	assert.InDelta(t, 1000, counts["Topeka"], 150)
+	assert.InDelta(t, 1000, counts["Linn"], 150)
+}
+
+func TestRoundRobinLocations(t *testing.T) {
+	path := writeLocationsFile(t, "locations.csv", "name,latitude,longitude\nA,1,1\nB,2,2\nC,3,3\n")
+	r, err := NewRoundRobinLocations(path)
+	require.NoError(t, err)
+
+	var names []string
+	for i := 0; i < 5; i++ {
+		location, err := r.GetRandomLocation(context.Background())
+		require.NoError(t, err)
+		names = append(names, location.Name)
+	}
+	assert.Equal(t, []string{"A", "B", "C", "A", "B"}, names)
+}
+
+func TestLoadLocationsFile_Errors(t *testing.T) {
+	tests := []struct {
+		name     string
+		file     string
+		content  string
+		expected string
+	}{
+		{name: "Missing column", file: "locations.csv", content: "name,latitude\nA,1\n", expected: "locations have no longitude column"},
+		{name: "Invalid latitude", file: "locations.csv", content: "latitude,longitude\nnorth,1\n", expected: `invalid latitude on line 2: "north"`},
+		{name: "Out of range", file: "locations.csv", content: "latitude,longitude\n91,1\n", expected: "location 1 is out of range"},
+		{name: "Negative weight", file: "locations.csv", content: "latitude,longitude,weight\n1,1,-1\n", expected: "location 1 has a negative weight"},
+		{name: "No locations", file: "locations.csv", content: "latitude,longitude\n", expected: "no locations"},
+		{name: "Not a point", file: "locations.json", content: `{"type": "FeatureCollection", "features": [{"geometry": {"type": "Polygon"}}]}`, expected: "feature 1 is not a Point"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := NewFileLocations(writeLocationsFile(t, tc.file, tc.content))
+			assert.ErrorContains(t, err, tc.expected)
+		})
+	}
+}
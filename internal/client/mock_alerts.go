@@ -0,0 +1,57 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: github.com/softstone1/fl/internal/client (interfaces: Alerts)
+//
+// Generated by this command:
+//
+//	mockgen -destination=mocks/mock_alerts_client.go -package=client github.com/softstone1/fl/internal/client Alerts
+//
+
+// Package client is a generated GoMock package.
+package client
+
+import (
+	context "context"
+	reflect "reflect"
+
+	model "github.com/softstone1/fl/internal/model"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockAlerts is a mock of Alerts interface.
+type MockAlerts struct {
+	ctrl     *gomock.Controller
+	recorder *MockAlertsMockRecorder
+	isgomock struct{}
+}
+
+// MockAlertsMockRecorder is the mock recorder for MockAlerts.
+type MockAlertsMockRecorder struct {
+	mock *MockAlerts
+}
+
+// NewMockAlerts creates a new mock instance.
+func NewMockAlerts(ctrl *gomock.Controller) *MockAlerts {
+	mock := &MockAlerts{ctrl: ctrl}
+	mock.recorder = &MockAlertsMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockAlerts) EXPECT() *MockAlertsMockRecorder {
+	return m.recorder
+}
+
+// GetActiveAlerts mocks base method.
+func (m *MockAlerts) GetActiveAlerts(ctx context.Context, lat, lng float64) ([]model.Alert, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetActiveAlerts", ctx, lat, lng)
+	ret0, _ := ret[0].([]model.Alert)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetActiveAlerts indicates an expected call of GetActiveAlerts.
+func (mr *MockAlertsMockRecorder) GetActiveAlerts(ctx, lat, lng any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetActiveAlerts", reflect.TypeOf((*MockAlerts)(nil).GetActiveAlerts), ctx, lat, lng)
+}
@@ -42,10 +42,10 @@ func (m *MockForecast) EXPECT() *MockForecastMockRecorder {
 }
 
 // GetForecastPeriods mocks base method.
-func (m *MockForecast) GetForecastPeriods(ctx context.Context, forecastURL string) ([]model.ForcastPeriod, error) {
+func (m *MockForecast) GetForecastPeriods(ctx context.Context, forecastURL string) (*model.Forecast, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "GetForecastPeriods", ctx, forecastURL)
-	ret0, _ := ret[0].([]model.ForcastPeriod)
+	ret0, _ := ret[0].(*model.Forecast)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
@@ -56,17 +56,47 @@ func (mr *MockForecastMockRecorder) GetForecastPeriods(ctx, forecastURL any) *go
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetForecastPeriods", reflect.TypeOf((*MockForecast)(nil).GetForecastPeriods), ctx, forecastURL)
 }
 
-// GetForecastURL mocks base method.
-func (m *MockForecast) GetForecastURL(ctx context.Context, lat, lng float64) (string, error) {
+// GetGridData mocks base method.
+func (m *MockForecast) GetGridData(ctx context.Context, gridDataURL string) (*model.GridData, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetForecastURL", ctx, lat, lng)
-	ret0, _ := ret[0].(string)
+	ret := m.ctrl.Call(m, "GetGridData", ctx, gridDataURL)
+	ret0, _ := ret[0].(*model.GridData)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// GetForecastURL indicates an expected call of GetForecastURL.
-func (mr *MockForecastMockRecorder) GetForecastURL(ctx, lat, lng any) *gomock.Call {
+// GetGridData indicates an expected call of GetGridData.
+func (mr *MockForecastMockRecorder) GetGridData(ctx, gridDataURL any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetForecastURL", reflect.TypeOf((*MockForecast)(nil).GetForecastURL), ctx, lat, lng)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetGridData", reflect.TypeOf((*MockForecast)(nil).GetGridData), ctx, gridDataURL)
+}
+
+// GetHourlyForecastPeriods mocks base method.
+func (m *MockForecast) GetHourlyForecastPeriods(ctx context.Context, forecastHourlyURL string) (*model.Forecast, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetHourlyForecastPeriods", ctx, forecastHourlyURL)
+	ret0, _ := ret[0].(*model.Forecast)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetHourlyForecastPeriods indicates an expected call of GetHourlyForecastPeriods.
+func (mr *MockForecastMockRecorder) GetHourlyForecastPeriods(ctx, forecastHourlyURL any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetHourlyForecastPeriods", reflect.TypeOf((*MockForecast)(nil).GetHourlyForecastPeriods), ctx, forecastHourlyURL)
+}
+
+// GetPointMetadata mocks base method.
+func (m *MockForecast) GetPointMetadata(ctx context.Context, lat, lng float64) (*model.PointMetadata, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPointMetadata", ctx, lat, lng)
+	ret0, _ := ret[0].(*model.PointMetadata)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPointMetadata indicates an expected call of GetPointMetadata.
+func (mr *MockForecastMockRecorder) GetPointMetadata(ctx, lat, lng any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPointMetadata", reflect.TypeOf((*MockForecast)(nil).GetPointMetadata), ctx, lat, lng)
 }
@@ -0,0 +1,72 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: github.com/softstone1/fl/internal/client (interfaces: Geocoder)
+//
+// Generated by this command:
+//
+//	mockgen -destination=mocks/mock_geocoder.go -package=client github.com/softstone1/fl/internal/client Geocoder
+//
+
+// Package client is a generated GoMock package.
+package client
+
+import (
+	context "context"
+	reflect "reflect"
+
+	model "github.com/softstone1/fl/internal/model"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockGeocoder is a mock of Geocoder interface.
+type MockGeocoder struct {
+	ctrl     *gomock.Controller
+	recorder *MockGeocoderMockRecorder
+	isgomock struct{}
+}
+
+// MockGeocoderMockRecorder is the mock recorder for MockGeocoder.
+type MockGeocoderMockRecorder struct {
+	mock *MockGeocoder
+}
+
+// NewMockGeocoder creates a new mock instance.
+func NewMockGeocoder(ctrl *gomock.Controller) *MockGeocoder {
+	mock := &MockGeocoder{ctrl: ctrl}
+	mock.recorder = &MockGeocoderMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockGeocoder) EXPECT() *MockGeocoderMockRecorder {
+	return m.recorder
+}
+
+// Geocode mocks base method.
+func (m *MockGeocoder) Geocode(ctx context.Context, query string) ([]model.Place, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Geocode", ctx, query)
+	ret0, _ := ret[0].([]model.Place)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Geocode indicates an expected call of Geocode.
+func (mr *MockGeocoderMockRecorder) Geocode(ctx, query any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Geocode", reflect.TypeOf((*MockGeocoder)(nil).Geocode), ctx, query)
+}
+
+// ReverseGeocode mocks base method.
+func (m *MockGeocoder) ReverseGeocode(ctx context.Context, lat, lng float64) (*model.RelativeLocation, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReverseGeocode", ctx, lat, lng)
+	ret0, _ := ret[0].(*model.RelativeLocation)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReverseGeocode indicates an expected call of ReverseGeocode.
+func (mr *MockGeocoderMockRecorder) ReverseGeocode(ctx, lat, lng any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReverseGeocode", reflect.TypeOf((*MockGeocoder)(nil).ReverseGeocode), ctx, lat, lng)
+}
@@ -0,0 +1,72 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: github.com/softstone1/fl/internal/client (interfaces: Observations)
+//
+// Generated by this command:
+//
+//	mockgen -destination=mocks/mock_observations_client.go -package=client github.com/softstone1/fl/internal/client Observations
+//
+
+// Package client is a generated GoMock package.
+package client
+
+import (
+	context "context"
+	reflect "reflect"
+
+	model "github.com/softstone1/fl/internal/model"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockObservations is a mock of Observations interface.
+type MockObservations struct {
+	ctrl     *gomock.Controller
+	recorder *MockObservationsMockRecorder
+	isgomock struct{}
+}
+
+// MockObservationsMockRecorder is the mock recorder for MockObservations.
+type MockObservationsMockRecorder struct {
+	mock *MockObservations
+}
+
+// NewMockObservations creates a new mock instance.
+func NewMockObservations(ctrl *gomock.Controller) *MockObservations {
+	mock := &MockObservations{ctrl: ctrl}
+	mock.recorder = &MockObservationsMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockObservations) EXPECT() *MockObservationsMockRecorder {
+	return m.recorder
+}
+
+// GetLatestObservation mocks base method.
+func (m *MockObservations) GetLatestObservation(ctx context.Context, stationID string) (*model.Observation, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetLatestObservation", ctx, stationID)
+	ret0, _ := ret[0].(*model.Observation)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetLatestObservation indicates an expected call of GetLatestObservation.
+func (mr *MockObservationsMockRecorder) GetLatestObservation(ctx, stationID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLatestObservation", reflect.TypeOf((*MockObservations)(nil).GetLatestObservation), ctx, stationID)
+}
+
+// GetStations mocks base method.
+func (m *MockObservations) GetStations(ctx context.Context, observationStationsURL string) ([]model.Station, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetStations", ctx, observationStationsURL)
+	ret0, _ := ret[0].([]model.Station)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetStations indicates an expected call of GetStations.
+func (mr *MockObservationsMockRecorder) GetStations(ctx, observationStationsURL any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetStations", reflect.TypeOf((*MockObservations)(nil).GetStations), ctx, observationStationsURL)
+}
@@ -0,0 +1,56 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: github.com/softstone1/fl/internal/client (interfaces: OpenMeteo)
+//
+// Generated by this command:
+//
+//	mockgen -destination=mocks/mock_open_meteo.go -package=client -self_package=github.com/softstone1/fl/internal/client github.com/softstone1/fl/internal/client OpenMeteo
+//
+
+// Package client is a generated GoMock package.
+package client
+
+import (
+	context "context"
+	reflect "reflect"
+
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockOpenMeteo is a mock of OpenMeteo interface.
+type MockOpenMeteo struct {
+	ctrl     *gomock.Controller
+	recorder *MockOpenMeteoMockRecorder
+	isgomock struct{}
+}
+
+// MockOpenMeteoMockRecorder is the mock recorder for MockOpenMeteo.
+type MockOpenMeteoMockRecorder struct {
+	mock *MockOpenMeteo
+}
+
+// NewMockOpenMeteo creates a new mock instance.
+func NewMockOpenMeteo(ctrl *gomock.Controller) *MockOpenMeteo {
+	mock := &MockOpenMeteo{ctrl: ctrl}
+	mock.recorder = &MockOpenMeteoMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockOpenMeteo) EXPECT() *MockOpenMeteoMockRecorder {
+	return m.recorder
+}
+
+// GetForecast mocks base method.
+func (m *MockOpenMeteo) GetForecast(ctx context.Context, lat, lng float64) (*OpenMeteoForecast, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetForecast", ctx, lat, lng)
+	ret0, _ := ret[0].(*OpenMeteoForecast)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetForecast indicates an expected call of GetForecast.
+func (mr *MockOpenMeteoMockRecorder) GetForecast(ctx, lat, lng any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetForecast", reflect.TypeOf((*MockOpenMeteo)(nil).GetForecast), ctx, lat, lng)
+}
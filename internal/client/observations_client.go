@@ -0,0 +1,98 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/go-resty/resty/v2"
+
+	"github.com/softstone1/fl/internal/model"
+)
+
+type Observations interface {
+	GetStations(ctx context.Context, observationStationsURL string) ([]model.Station, error)
+	GetLatestObservation(ctx context.Context, stationID string) (*model.Observation, error)
+}
+
+type observations struct {
+	client *resty.Client
+}
+
+// make sure observations implements the Observations interface
+var _ Observations = (*observations)(nil)
+
+// StationsResponse represents the top-level GeoJSON feature collection of stations
+type StationsResponse struct {
+	Features []StationFeature `json:"features"`
+}
+
+// StationFeature holds a single station
+type StationFeature struct {
+	Properties model.Station `json:"properties"`
+}
+
+// ObservationResponse represents the top-level JSON structure
+type ObservationResponse struct {
+	Properties ObservationProperties `json:"properties"`
+}
+
+// ObservationProperties holds the observation, whose station NWS gives as a URL
+type ObservationProperties struct {
+	model.Observation
+	Station string `json:"station"`
+}
+
+// NewObservations initializes a new Observations Client with a shared http.Client
+func NewObservations(client *resty.Client) *observations {
+	return &observations{
+		client: client,
+	}
+}
+
+// GetStations returns the observation stations of a location, nearest first, for a given observation stations URL
+func (o *observations) GetStations(ctx context.Context, observationStationsURL string) ([]model.Station, error) {
+	stationsResponse := &StationsResponse{}
+	resp, err := o.client.R().
+		SetResult(stationsResponse).
+		SetContext(ctx).
+		SetHeader("Accept", "application/geo+json").
+		Get(observationStationsURL)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch observation stations: %w", err)
+	}
+
+	if resp.StatusCode() != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode())
+	}
+
+	stations := make([]model.Station, 0, len(stationsResponse.Features))
+	for _, feature := range stationsResponse.Features {
+		stations = append(stations, feature.Properties)
+	}
+	return stations, nil
+}
+
+// GetLatestObservation returns the latest observation of a given station
+func (o *observations) GetLatestObservation(ctx context.Context, stationID string) (*model.Observation, error) {
+	url := fmt.Sprintf("%s/stations/%s/observations/latest", o.client.BaseURL, stationID)
+	observationResponse := &ObservationResponse{}
+	resp, err := o.client.R().
+		SetResult(observationResponse).
+		SetContext(ctx).
+		SetHeader("Accept", "application/geo+json").
+		Get(url)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch latest observation: %w", err)
+	}
+
+	if resp.StatusCode() != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode())
+	}
+
+	observation := observationResponse.Properties.Observation
+	observation.Station = model.Station{ID: stationID}
+	return &observation, nil
+}
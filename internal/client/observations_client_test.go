@@ -0,0 +1,65 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetStations(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/gridpoints/TOP/32,81/stations", r.URL.Path)
+		w.Header().Set("Content-Type", "application/geo+json")
+		w.Write([]byte(`{
+			"type": "FeatureCollection",
+			"features": [
+				{"properties": {"stationIdentifier": "KMHK", "name": "Manhattan Regional Airport"}},
+				{"properties": {"stationIdentifier": "KFRI", "name": "Marshall Army Airfield"}}
+			]
+		}`))
+	}))
+	defer srv.Close()
+
+	o := NewObservations(InitializeClient(Configuration{BaseURL: srv.URL, Timeout: time.Second}))
+	stations, err := o.GetStations(context.Background(), srv.URL+"/gridpoints/TOP/32,81/stations")
+
+	require.NoError(t, err)
+	require.Len(t, stations, 2)
+	assert.Equal(t, "KMHK", stations[0].ID)
+	assert.Equal(t, "Marshall Army Airfield", stations[1].Name)
+}
+
+func TestGetLatestObservation(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/stations/KMHK/observations/latest", r.URL.Path)
+		w.Header().Set("Content-Type", "application/geo+json")
+		w.Write([]byte(`{
+			"properties": {
+				"station": "https://api.weather.gov/stations/KMHK",
+				"timestamp": "2025-01-01T11:54:00+00:00",
+				"textDescription": "Clear",
+				"temperature": {"unitCode": "wmoUnit:degC", "value": -3.3},
+				"relativeHumidity": {"unitCode": "wmoUnit:percent", "value": 81.5},
+				"windSpeed": {"unitCode": "wmoUnit:km_h-1", "value": null},
+				"windDirection": {"unitCode": "wmoUnit:degree_(angle)", "value": null},
+				"barometricPressure": {"unitCode": "wmoUnit:Pa", "value": 102710}
+			}
+		}`))
+	}))
+	defer srv.Close()
+
+	o := NewObservations(InitializeClient(Configuration{BaseURL: srv.URL, Timeout: time.Second}))
+	observation, err := o.GetLatestObservation(context.Background(), "KMHK")
+
+	require.NoError(t, err)
+	assert.Equal(t, "KMHK", observation.Station.ID)
+	assert.Equal(t, "Clear", observation.TextDescription)
+	require.NotNil(t, observation.Temperature.Value)
+	assert.Equal(t, -3.3, *observation.Temperature.Value)
+	assert.Nil(t, observation.WindSpeed.Value)
+}
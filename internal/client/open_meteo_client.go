@@ -0,0 +1,83 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/go-resty/resty/v2"
+)
+
+// openMeteoHourlyVariables are the hourly variables requested from Open-Meteo
+const openMeteoHourlyVariables = "temperature_2m,precipitation_probability,weather_code,wind_speed_10m,wind_direction_10m,is_day"
+
+// openMeteoForecastDays is the number of days forecast, matching the 7 days of the NWS forecasts
+const openMeteoForecastDays = 7
+
+type OpenMeteo interface {
+	GetForecast(ctx context.Context, lat, lng float64) (*OpenMeteoForecast, error)
+}
+
+type openMeteo struct {
+	client *resty.Client
+}
+
+// make sure openMeteo implements the OpenMeteo interface
+var _ OpenMeteo = (*openMeteo)(nil)
+
+// OpenMeteoForecast is the hourly forecast of a location, in °C and km/h, with the location time zone
+type OpenMeteoForecast struct {
+	TimeZone         string          `json:"timezone"`
+	UTCOffsetSeconds int             `json:"utc_offset_seconds"`
+	Hourly           OpenMeteoHourly `json:"hourly"`
+}
+
+// OpenMeteoHourly holds one value per forecast hour in each variable, nil when Open-Meteo has none
+type OpenMeteoHourly struct {
+	// Time is the start of each hour in Unix seconds
+	Time                     []int64    `json:"time"`
+	Temperature              []*float64 `json:"temperature_2m"`
+	PrecipitationProbability []*float64 `json:"precipitation_probability"`
+	// WeatherCode is the WMO weather interpretation code
+	WeatherCode   []*int     `json:"weather_code"`
+	WindSpeed     []*float64 `json:"wind_speed_10m"`
+	WindDirection []*float64 `json:"wind_direction_10m"`
+	// IsDay is 1 during daylight and 0 at night
+	IsDay []*int `json:"is_day"`
+}
+
+// NewOpenMeteo initializes a new Open-Meteo Client with a shared http.Client
+func NewOpenMeteo(client *resty.Client) *openMeteo {
+	return &openMeteo{
+		client: client,
+	}
+}
+
+// GetForecast returns the hourly forecast of the coordinates for the next 7 days
+func (o *openMeteo) GetForecast(ctx context.Context, lat, lng float64) (*OpenMeteoForecast, error) {
+	url := fmt.Sprintf("%s/v1/forecast", o.client.BaseURL)
+	forecastResponse := &OpenMeteoForecast{}
+	resp, err := o.client.R().
+		SetResult(forecastResponse).
+		SetContext(ctx).
+		SetHeader("Accept", "application/json").
+		SetQueryParams(map[string]string{
+			"latitude":      strconv.FormatFloat(lat, 'f', -1, 64),
+			"longitude":     strconv.FormatFloat(lng, 'f', -1, 64),
+			"hourly":        openMeteoHourlyVariables,
+			"timezone":      "auto",
+			"timeformat":    "unixtime",
+			"forecast_days": strconv.Itoa(openMeteoForecastDays),
+		}).
+		Get(url)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch Open-Meteo forecast: %w", err)
+	}
+
+	if resp.StatusCode() != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode())
+	}
+	return forecastResponse, nil
+}
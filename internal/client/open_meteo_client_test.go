@@ -0,0 +1,65 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOpenMeteo_GetForecast(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v1/forecast", r.URL.Path)
+		assert.Equal(t, "48.8566", r.URL.Query().Get("latitude"))
+		assert.Equal(t, "2.3522", r.URL.Query().Get("longitude"))
+		assert.Equal(t, openMeteoHourlyVariables, r.URL.Query().Get("hourly"))
+		assert.Equal(t, "unixtime", r.URL.Query().Get("timeformat"))
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{
+			"timezone": "Europe/Paris",
+			"utc_offset_seconds": 7200,
+			"hourly": {
+				"time": [1751364000, 1751367600],
+				"temperature_2m": [21.4, null],
+				"precipitation_probability": [10, 35],
+				"weather_code": [2, 61],
+				"wind_speed_10m": [12.6, 14],
+				"wind_direction_10m": [200, 215],
+				"is_day": [1, 1]
+			}
+		}`))
+	}))
+	defer srv.Close()
+
+	o := NewOpenMeteo(InitializeClient(Configuration{BaseURL: srv.URL, Timeout: time.Second}))
+	forecast, err := o.GetForecast(context.Background(), 48.8566, 2.3522)
+
+	require.NoError(t, err)
+	assert.Equal(t, "Europe/Paris", forecast.TimeZone)
+	assert.Equal(t, 7200, forecast.UTCOffsetSeconds)
+	require.Len(t, forecast.Hourly.Time, 2)
+	assert.Equal(t, 21.4, *forecast.Hourly.Temperature[0])
+	assert.Nil(t, forecast.Hourly.Temperature[1])
+	assert.Equal(t, 61, *forecast.Hourly.WeatherCode[1])
+}
+
+func TestOpenMeteo_GetForecast_Error(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(`{"error": true, "reason": "Latitude must be in range of -90 to 90°."}`))
+	}))
+	defer srv.Close()
+
+	o := NewOpenMeteo(InitializeClient(Configuration{BaseURL: srv.URL, Timeout: time.Second}))
+	_, err := o.GetForecast(context.Background(), 91, 0)
+
+	var apiErr *APIError
+	require.ErrorAs(t, err, &apiErr)
+	assert.Equal(t, http.StatusBadRequest, apiErr.StatusCode)
+	assert.Equal(t, "Latitude must be in range of -90 to 90°.", apiErr.Body["reason"])
+}
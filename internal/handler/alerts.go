@@ -0,0 +1,54 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/softstone1/fl/internal/renderer"
+	"github.com/softstone1/fl/internal/service"
+)
+
+// GetAlerts get the active alerts for the lat/lng location, or a random one, with their times in the tz time zone
+// or else the location one
+func (f *Forecast) GetAlerts(w http.ResponseWriter, r *http.Request) {
+	location, err := parseCoordinates(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	zone, err := parseTimeZone(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	seed, err := parseSeed(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	region, err := parseRegion(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	ctx := r.Context()
+	resp, err := f.ForcastService.GetAlerts(ctx, service.AlertsQuery{Location: location, Seed: seed, Region: region, TimeZone: zone})
+	if errors.Is(err, service.ErrAlertsDisabled) {
+		http.Error(w, err.Error(), http.StatusNotImplemented)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), serviceErrorStatus(err))
+		return
+	}
+	writeSeed(w, resp.Location)
+	if wantsJSON(r) {
+		writeJSON(w, http.StatusOK, resp)
+		return
+	}
+	f.writeRendered(w, r, renderer.LocationAlerts, resp)
+}
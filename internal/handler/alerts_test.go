@@ -0,0 +1,113 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/softstone1/fl/internal/model"
+	"github.com/softstone1/fl/internal/service"
+	"go.uber.org/mock/gomock"
+)
+
+func TestGetAlerts(t *testing.T) {
+    ctrl := gomock.NewController(t)
+    defer ctrl.Finish()
+
+    mockForecastSvc := service.NewMockForecast(ctrl)
+    h := NewForecast(mockForecastSvc, newTestRenderer(t))
+
+    location := &model.Location{Latitude: 39.7456, Longitude: -97.0892}
+    alerts := &model.LocationAlerts{
+        Location: *location,
+        Alerts: []model.Alert{
+            {
+                Headline: "Winter Storm Warning issued by NWS Topeka",
+                Severity: "Severe",
+                Urgency:  "Expected",
+                Onset:    time.Date(2025, 1, 1, 18, 0, 0, 0, time.UTC),
+                Expires:  time.Date(2025, 1, 2, 6, 0, 0, 0, time.UTC),
+            },
+            {Event: "Wind Advisory", Severity: "Moderate", Urgency: "Immediate"},
+        },
+    }
+
+    tests := []struct {
+        name           string
+        target         string
+        mockSetup      func()
+        expectedStatus int
+        expectedBody   string
+    }{
+        {
+            name:   "Alerts are listed",
+            target: "/alerts?lat=39.7456&lng=-97.0892",
+            mockSetup: func() {
+                mockForecastSvc.
+                    EXPECT().
+                    GetAlerts(gomock.Any(), service.AlertsQuery{Location: location}).
+                    Return(alerts, nil)
+            },
+            expectedStatus: http.StatusOK,
+            expectedBody: "Alert: Winter Storm Warning issued by NWS Topeka (severity Severe, urgency Expected, from 2025-01-01T18:00:00Z, until 2025-01-02T06:00:00Z)\n" +
+                "Alert: Wind Advisory (severity Moderate, urgency Immediate)\n",
+        },
+        {
+            name:   "No alerts",
+            target: "/alerts",
+            mockSetup: func() {
+                mockForecastSvc.
+                    EXPECT().
+                    GetAlerts(gomock.Any(), service.AlertsQuery{}).
+                    Return(&model.LocationAlerts{}, nil)
+            },
+            expectedStatus: http.StatusOK,
+            expectedBody:   "No active alerts\n",
+        },
+        {
+            name:   "Disabled alerts return 501",
+            target: "/alerts",
+            mockSetup: func() {
+                mockForecastSvc.
+                    EXPECT().
+                    GetAlerts(gomock.Any(), service.AlertsQuery{}).
+                    Return(nil, service.ErrAlertsDisabled)
+            },
+            expectedStatus: http.StatusNotImplemented,
+            expectedBody:   "alerts are not enabled\n",
+        },
+        {
+            name:   "Service error returns 500",
+            target: "/alerts",
+            mockSetup: func() {
+                mockForecastSvc.
+                    EXPECT().
+                    GetAlerts(gomock.Any(), service.AlertsQuery{}).
+                    Return(nil, errors.New("some error"))
+            },
+            expectedStatus: http.StatusInternalServerError,
+            expectedBody:   "some error\n",
+        },
+    }
+
+    for _, tc := range tests {
+        t.Run(tc.name, func(t *testing.T) {
+            tc.mockSetup()
+
+            req := httptest.NewRequest(http.MethodGet, tc.target, nil)
+            rr := httptest.NewRecorder()
+
+            h.GetAlerts(rr, req)
+
+            if rr.Code != tc.expectedStatus {
+                t.Errorf("expected status %d, got %d", tc.expectedStatus, rr.Code)
+            }
+
+            if body := rr.Body.String(); body != tc.expectedBody {
+                t.Errorf("expected body %q, got %q", tc.expectedBody, body)
+            }
+        })
+    }
+}
@@ -0,0 +1,199 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/softstone1/fl/internal/model"
+	"github.com/softstone1/fl/internal/renderer"
+	"github.com/softstone1/fl/internal/service"
+)
+
+// maxBatchBodyBytes bounds the size of a batch request body
+const maxBatchBodyBytes = 1 << 20
+
+// batchItemRequest is a batch item given as an object, by lat/lng coordinates or by q place name
+type batchItemRequest struct {
+	Lat *float64 `json:"lat"`
+	Lng *float64 `json:"lng"`
+	Q   string   `json:"q"`
+}
+
+// batchItemResponse is the current forecast of the batch item at Index, or the error getting it
+// with the status its own request would have answered
+type batchItemResponse struct {
+	Index      int                      `json:"index"`
+	Status     int                      `json:"status"`
+	Forecast   *currentForecastResponse `json:"forecast,omitempty"`
+	Error      string                   `json:"error,omitempty"`
+	Candidates []model.Place            `json:"candidates,omitempty"`
+}
+
+// batchResponse lists the batch results in the order of the request items
+type batchResponse struct {
+	Results []batchItemResponse `json:"results"`
+}
+
+// GetForecastBatch get the current detailed forcasts of a JSON array of locations, each given as a place name,
+// {"q": place name} or {"lat": lat, "lng": lng}, in the given units and tz time zone. The results are returned
+// together in the order of the items, or as NDJSON lines as they complete when asked with format=ndjson or
+// an Accept header listing application/x-ndjson.
+func (f *Forecast) GetForecastBatch(w http.ResponseWriter, r *http.Request) {
+	units, err := parseUnits(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	zone, err := parseTimeZone(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	items, err := parseBatchItems(http.MaxBytesReader(w, r.Body, maxBatchBodyBytes))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	query := service.BatchQuery{Items: items, Units: units, TimeZone: zone}
+	locale := f.locale(w, r)
+
+	ctx := r.Context()
+	if wantsNDJSON(r) {
+		encoder := json.NewEncoder(w)
+		flusher, _ := w.(http.Flusher)
+		started := false
+		err = f.ForcastService.GetForecastBatch(ctx, query, func(result service.BatchResult) {
+			if !started {
+				w.Header().Set("Content-Type", "application/x-ndjson")
+				w.WriteHeader(http.StatusOK)
+				started = true
+			}
+			if err := encoder.Encode(f.batchItem(result, locale)); err != nil {
+				log.Printf("Failed to write NDJSON response: %v", err)
+				return
+			}
+			if flusher != nil {
+				flusher.Flush()
+			}
+		})
+		if err != nil && !started {
+			http.Error(w, err.Error(), batchErrorStatus(err))
+		}
+		return
+	}
+
+	results := make([]batchItemResponse, len(items))
+	err = f.ForcastService.GetForecastBatch(ctx, query, func(result service.BatchResult) {
+		results[result.Index] = f.batchItem(result, locale)
+	})
+	if err != nil {
+		http.Error(w, err.Error(), batchErrorStatus(err))
+		return
+	}
+	writeJSON(w, http.StatusOK, batchResponse{Results: results})
+}
+
+// batchItem returns the response to a batch result, with the forecast summary rendered in locale
+func (f *Forecast) batchItem(result service.BatchResult, locale string) batchItemResponse {
+	item := batchItemResponse{Index: result.Index, Status: http.StatusOK}
+	if result.Err != nil {
+		item.Status, item.Error = serviceErrorStatus(result.Err), result.Err.Error()
+		var ambiguous *service.AmbiguousPlaceError
+		if errors.As(result.Err, &ambiguous) {
+			item.Candidates = ambiguous.Candidates
+		}
+		return item
+	}
+	var summary strings.Builder
+	if err := f.Renderer.Render(&summary, locale, renderer.Summary, result.Forecast); err != nil {
+		item.Status, item.Error = http.StatusInternalServerError, err.Error()
+		return item
+	}
+	item.Forecast = &currentForecastResponse{CurrentForecast: result.Forecast, Text: summary.String()}
+	return item
+}
+
+// batchErrorStatus returns the status of the response to a batch the service rejected
+func batchErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, service.ErrBatchTooLarge):
+		return http.StatusRequestEntityTooLarge
+	case errors.Is(err, service.ErrEmptyBatch):
+		return http.StatusBadRequest
+	default:
+		return serviceErrorStatus(err)
+	}
+}
+
+// parseBatchItems decodes the JSON array of batch items
+func parseBatchItems(body io.Reader) ([]service.BatchItem, error) {
+	var raw []json.RawMessage
+	if err := json.NewDecoder(body).Decode(&raw); err != nil {
+		return nil, errors.New("invalid batch: expected a JSON array of locations")
+	}
+	items := make([]service.BatchItem, len(raw))
+	for i, message := range raw {
+		item, err := parseBatchItem(message)
+		if err != nil {
+			return nil, fmt.Errorf("invalid batch item %d: %w", i, err)
+		}
+		items[i] = item
+	}
+	return items, nil
+}
+
+// parseBatchItem decodes a batch item given as a place name or a batchItemRequest
+func parseBatchItem(message json.RawMessage) (service.BatchItem, error) {
+	if bytes.HasPrefix(bytes.TrimSpace(message), []byte(`"`)) {
+		var place string
+		if err := json.Unmarshal(message, &place); err != nil {
+			return service.BatchItem{}, err
+		}
+		if place = strings.TrimSpace(place); place == "" {
+			return service.BatchItem{}, errors.New("empty place name")
+		}
+		return service.BatchItem{Place: place}, nil
+	}
+
+	var request batchItemRequest
+	if err := json.Unmarshal(message, &request); err != nil {
+		return service.BatchItem{}, errors.New("expected a place name or an object with q or lat and lng")
+	}
+	place := strings.TrimSpace(request.Q)
+	hasCoordinates := request.Lat != nil || request.Lng != nil
+	switch {
+	case place != "" && hasCoordinates:
+		return service.BatchItem{}, errors.New("q and lat/lng cannot be given together")
+	case place != "":
+		return service.BatchItem{Place: place}, nil
+	case request.Lat == nil || request.Lng == nil:
+		return service.BatchItem{}, errors.New("expected q or lat and lng")
+	case *request.Lat < -90 || *request.Lat > 90:
+		return service.BatchItem{}, fmt.Errorf("invalid lat: %v", *request.Lat)
+	case *request.Lng < -180 || *request.Lng > 180:
+		return service.BatchItem{}, fmt.Errorf("invalid lng: %v", *request.Lng)
+	}
+	return service.BatchItem{Location: &model.Location{Latitude: *request.Lat, Longitude: *request.Lng}}, nil
+}
+
+// wantsNDJSON reports whether the client asked for results streamed as NDJSON, either with ?format=ndjson
+// or with an Accept header listing application/x-ndjson
+func wantsNDJSON(r *http.Request) bool {
+	if format := r.URL.Query().Get("format"); format != "" {
+		return format == "ndjson"
+	}
+	for _, accept := range r.Header.Values("Accept") {
+		for _, mediaType := range strings.Split(accept, ",") {
+			if strings.HasPrefix(strings.TrimSpace(mediaType), "application/x-ndjson") {
+				return true
+			}
+		}
+	}
+	return false
+}
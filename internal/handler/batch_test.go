@@ -0,0 +1,179 @@
+package handler
+
+import (
+    "context"
+    "fmt"
+    "net/http"
+    "net/http/httptest"
+    "strings"
+    "testing"
+    "time"
+
+    "github.com/softstone1/fl/internal/model"
+    "github.com/softstone1/fl/internal/service"
+    "go.uber.org/mock/gomock"
+)
+
+func TestGetForecastBatch(t *testing.T) {
+    ctrl := gomock.NewController(t)
+    defer ctrl.Finish()
+
+    mockForecastSvc := service.NewMockForecast(ctrl)
+    h := NewForecast(mockForecastSvc, newTestRenderer(t))
+
+    linn := &model.CurrentForecast{
+        Location: model.Location{Name: "Linn, KS", Latitude: 39.7456, Longitude: -97.0892},
+        Period:   model.ForcastPeriod{DetailedForecast: "sunny"},
+    }
+    candidates := []model.Place{{Name: "Portland", State: "OR"}, {Name: "Portland", State: "ME"}}
+    batchQuery := service.BatchQuery{Items: []service.BatchItem{
+        {Location: &model.Location{Latitude: 39.7456, Longitude: -97.0892}},
+        {Place: "Portland"},
+        {Place: "Gotham"},
+    }}
+    // the results complete out of order
+    emitResults := func(_ context.Context, _ service.BatchQuery, emit func(service.BatchResult)) error {
+        emit(service.BatchResult{Index: 2, Err: fmt.Errorf("Stage 1 - FindPlace error: %w", service.ErrPlaceNotFound)})
+        emit(service.BatchResult{Index: 0, Forecast: linn})
+        emit(service.BatchResult{Index: 1, Err: &service.AmbiguousPlaceError{Query: "Portland", Candidates: candidates}})
+        return nil
+    }
+    items := []batchItemResponse{
+        {Index: 0, Status: http.StatusOK, Forecast: &currentForecastResponse{CurrentForecast: linn, Text: "The weather in Linn, KS is: sunny"}},
+        {Index: 1, Status: http.StatusMultipleChoices, Error: `ambiguous place "Portland" matches 2 places`, Candidates: candidates},
+        {Index: 2, Status: http.StatusNotFound, Error: "Stage 1 - FindPlace error: place not found"},
+    }
+
+    tests := []struct {
+        name           string
+        target         string
+        body           string
+        accept         string
+        mockSetup      func()
+        expectedStatus int
+        expectedBody   string
+    }{
+        {
+            name:   "Results are returned in the order of the items",
+            target: "/forecast/batch",
+            body:   `[{"lat": 39.7456, "lng": -97.0892}, {"q": "Portland"}, "Gotham"]`,
+            mockSetup: func() {
+                mockForecastSvc.EXPECT().GetForecastBatch(gomock.Any(), batchQuery, gomock.Any()).DoAndReturn(emitResults)
+            },
+            expectedStatus: http.StatusOK,
+            expectedBody:   mustJSON(t, batchResponse{Results: items}),
+        },
+        {
+            name:   "NDJSON streams the results as they complete",
+            target: "/forecast/batch",
+            body:   `[{"lat": 39.7456, "lng": -97.0892}, {"q": "Portland"}, "Gotham"]`,
+            accept: "application/x-ndjson",
+            mockSetup: func() {
+                mockForecastSvc.EXPECT().GetForecastBatch(gomock.Any(), batchQuery, gomock.Any()).DoAndReturn(emitResults)
+            },
+            expectedStatus: http.StatusOK,
+            expectedBody:   mustJSON(t, items[2]) + mustJSON(t, items[0]) + mustJSON(t, items[1]),
+        },
+        {
+            name:   "Units and time zone are passed to the service",
+            target: "/forecast/batch?units=si&tz=UTC",
+            body:   `["Seattle, WA"]`,
+            mockSetup: func() {
+                mockForecastSvc.EXPECT().GetForecastBatch(gomock.Any(), service.BatchQuery{
+                    Items:    []service.BatchItem{{Place: "Seattle, WA"}},
+                    Units:    model.UnitsSI,
+                    TimeZone: time.UTC,
+                }, gomock.Any()).Return(nil)
+            },
+            expectedStatus: http.StatusOK,
+            expectedBody:   mustJSON(t, batchResponse{Results: []batchItemResponse{{}}}),
+        },
+        {
+            name:   "Too large batch returns 413",
+            target: "/forecast/batch",
+            body:   `["Seattle", "Denver"]`,
+            mockSetup: func() {
+                mockForecastSvc.EXPECT().GetForecastBatch(gomock.Any(), gomock.Any(), gomock.Any()).Return(
+                    fmt.Errorf("%w: 2 items, at most 1", service.ErrBatchTooLarge),
+                )
+            },
+            expectedStatus: http.StatusRequestEntityTooLarge,
+            expectedBody:   "batch too large: 2 items, at most 1\n",
+        },
+        {
+            name:   "Timed out batch returns 504",
+            target: "/forecast/batch",
+            body:   `["Seattle"]`,
+            mockSetup: func() {
+                mockForecastSvc.EXPECT().GetForecastBatch(gomock.Any(), gomock.Any(), gomock.Any()).Return(
+                    fmt.Errorf("failed to forecast batch: %w", context.DeadlineExceeded),
+                )
+            },
+            expectedStatus: http.StatusGatewayTimeout,
+            expectedBody:   "failed to forecast batch: context deadline exceeded\n",
+        },
+        {
+            name:   "Timed out item has status 504",
+            target: "/forecast/batch",
+            body:   `["Seattle"]`,
+            mockSetup: func() {
+                mockForecastSvc.EXPECT().GetForecastBatch(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(
+                    func(_ context.Context, _ service.BatchQuery, emit func(service.BatchResult)) error {
+                        emit(service.BatchResult{Index: 0, Err: fmt.Errorf("Stage 2 - GetPointMetadata error: %w", context.DeadlineExceeded)})
+                        return nil
+                    },
+                )
+            },
+            expectedStatus: http.StatusOK,
+            expectedBody: mustJSON(t, batchResponse{Results: []batchItemResponse{
+                {Index: 0, Status: http.StatusGatewayTimeout, Error: "Stage 2 - GetPointMetadata error: context deadline exceeded"},
+            }}),
+        },
+        {
+            name:           "Invalid body returns 400",
+            target:         "/forecast/batch",
+            body:           `{"q": "Seattle"}`,
+            mockSetup:      func() {},
+            expectedStatus: http.StatusBadRequest,
+            expectedBody:   "invalid batch: expected a JSON array of locations\n",
+        },
+        {
+            name:           "Item without location returns 400",
+            target:         "/forecast/batch",
+            body:           `["Seattle", {"lat": 39.7456}]`,
+            mockSetup:      func() {},
+            expectedStatus: http.StatusBadRequest,
+            expectedBody:   "invalid batch item 1: expected q or lat and lng\n",
+        },
+        {
+            name:           "Item out of range returns 400",
+            target:         "/forecast/batch",
+            body:           `[{"lat": 91, "lng": 0}]`,
+            mockSetup:      func() {},
+            expectedStatus: http.StatusBadRequest,
+            expectedBody:   "invalid batch item 0: invalid lat: 91\n",
+        },
+    }
+
+    for _, tc := range tests {
+        t.Run(tc.name, func(t *testing.T) {
+            tc.mockSetup()
+
+            req := httptest.NewRequest(http.MethodPost, tc.target, strings.NewReader(tc.body))
+            if tc.accept != "" {
+                req.Header.Set("Accept", tc.accept)
+            }
+            rr := httptest.NewRecorder()
+
+            h.GetForecastBatch(rr, req)
+
+            if rr.Code != tc.expectedStatus {
+                t.Errorf("expected status %d, got %d", tc.expectedStatus, rr.Code)
+            }
+
+            if body := rr.Body.String(); body != tc.expectedBody {
+                t.Errorf("expected body %q, got %q", tc.expectedBody, body)
+            }
+        })
+    }
+}
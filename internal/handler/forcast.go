@@ -1,30 +1,211 @@
 package handler
 
 import (
+	"fmt"
 	"net/http"
+	"strconv"
+	"strings"
+	"time"
 
+	"github.com/softstone1/fl/internal/model"
+	"github.com/softstone1/fl/internal/renderer"
 	"github.com/softstone1/fl/internal/service"
 )
 
 
 type Forecast struct {
 	ForcastService service.Forecast
+	Renderer       *renderer.Renderer
 }
 
-func NewForecast(forcastService service.Forecast) *Forecast {
+func NewForecast(forcastService service.Forecast, renderer *renderer.Renderer) *Forecast {
 	return &Forecast{
 		ForcastService: forcastService,
+		Renderer:       renderer,
 	}
 }
 
-// GetRandomForecast get current detailed forcast for a random location
+// currentForecastResponse is the JSON current forecast, with its summary rendered in the client locale
+type currentForecastResponse struct {
+	*model.CurrentForecast
+	Text string `json:"text"`
+}
+
+// GetRandomForecast get current detailed forcast for a random location, or the one picked with seed,
+// in the given units and tz time zone, compared between the providers with consensus=true
 func (f *Forecast) GetRandomForecast(w http.ResponseWriter, r *http.Request) {
+	seed, err := parseSeed(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	region, err := parseRegion(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	units, err := parseUnits(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	zone, err := parseTimeZone(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	consensus, err := parseConsensus(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	ctx := r.Context()
+	resp, err := f.ForcastService.GetRandomForecast(ctx, service.CurrentQuery{Seed: seed, Region: region, Units: units, TimeZone: zone, Consensus: consensus})
+	if err != nil {
+		http.Error(w, err.Error(), serviceErrorStatus(err))
+		return
+	}
+	f.writeCurrentForecast(w, r, resp)
+}
+
+// writeCurrentForecast writes the current forecast as JSON, with its rendered summary, or as text
+func (f *Forecast) writeCurrentForecast(w http.ResponseWriter, r *http.Request, resp *model.CurrentForecast) {
+	writeSeed(w, resp.Location)
+	if wantsJSON(r) {
+		var summary strings.Builder
+		if err := f.Renderer.Render(&summary, f.locale(w, r), renderer.Summary, resp); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		writeJSON(w, http.StatusOK, currentForecastResponse{CurrentForecast: resp, Text: summary.String()})
+		return
+	}
+	f.writeRendered(w, r, renderer.Current, resp)
+}
+
+// GetForecastPeriods get the upcoming forcast periods for the lat/lng location, or a random one.
+// The periods can be filtered with from/to (RFC 3339), limit and daytime=only, converted with units=us|si
+// and their times set in the tz time zone instead of the location one.
+func (f *Forecast) GetForecastPeriods(w http.ResponseWriter, r *http.Request) {
+	query, err := parsePeriodsQuery(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
 	ctx := r.Context()
-	resp, err := f.ForcastService.GetRandomForecast(ctx)
+	resp, err := f.ForcastService.GetForecastPeriods(ctx, query)
+	if err != nil {
+		http.Error(w, err.Error(), serviceErrorStatus(err))
+		return
+	}
+	writeSeed(w, resp.Location)
+	if wantsJSON(r) {
+		writeJSON(w, http.StatusOK, resp)
+		return
+	}
+	f.writeRendered(w, r, renderer.Periods, resp)
+}
+
+// GetHourlyForecast get the hourly forcast periods for the lat/lng location, or a random one,
+// up to the given number of hours ahead, in the given units and tz time zone
+func (f *Forecast) GetHourlyForecast(w http.ResponseWriter, r *http.Request) {
+	location, err := parseCoordinates(r)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
-	w.Header().Set("Content-Type", "plain/text")
-	w.Write([]byte(resp))
+	units, err := parseUnits(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	zone, err := parseTimeZone(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	horizon, err := parseHours(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	seed, err := parseSeed(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	region, err := parseRegion(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	query := service.HourlyQuery{Location: location, Seed: seed, Region: region, Horizon: horizon, Units: units, TimeZone: zone}
+
+	ctx := r.Context()
+	resp, err := f.ForcastService.GetHourlyForecast(ctx, query)
+	if err != nil {
+		http.Error(w, err.Error(), serviceErrorStatus(err))
+		return
+	}
+	writeSeed(w, resp.Location)
+	if wantsJSON(r) {
+		writeJSON(w, http.StatusOK, resp)
+		return
+	}
+	f.writeRendered(w, r, renderer.Hourly, resp)
+}
+
+// parsePeriodsQuery reads the location and filters of GetForecastPeriods
+func parsePeriodsQuery(r *http.Request) (service.PeriodsQuery, error) {
+	location, err := parseCoordinates(r)
+	if err != nil {
+		return service.PeriodsQuery{}, err
+	}
+	units, err := parseUnits(r)
+	if err != nil {
+		return service.PeriodsQuery{}, err
+	}
+	zone, err := parseTimeZone(r)
+	if err != nil {
+		return service.PeriodsQuery{}, err
+	}
+	seed, err := parseSeed(r)
+	if err != nil {
+		return service.PeriodsQuery{}, err
+	}
+	region, err := parseRegion(r)
+	if err != nil {
+		return service.PeriodsQuery{}, err
+	}
+	query := service.PeriodsQuery{Location: location, Seed: seed, Region: region, Units: units, TimeZone: zone}
+
+	params := r.URL.Query()
+	if from := params.Get("from"); from != "" {
+		if query.From, err = time.Parse(time.RFC3339, from); err != nil {
+			return service.PeriodsQuery{}, fmt.Errorf("invalid from: %q", from)
+		}
+	}
+	if to := params.Get("to"); to != "" {
+		if query.To, err = time.Parse(time.RFC3339, to); err != nil {
+			return service.PeriodsQuery{}, fmt.Errorf("invalid to: %q", to)
+		}
+	}
+	if !query.From.IsZero() && !query.To.IsZero() && query.From.After(query.To) {
+		return service.PeriodsQuery{}, fmt.Errorf("from must not be after to")
+	}
+	if limit := params.Get("limit"); limit != "" {
+		if query.Limit, err = strconv.Atoi(limit); err != nil || query.Limit < 0 {
+			return service.PeriodsQuery{}, fmt.Errorf("invalid limit: %q", limit)
+		}
+	}
+	switch daytime := params.Get("daytime"); daytime {
+	case "":
+	case "only":
+		query.DaytimeOnly = true
+	default:
+		return service.PeriodsQuery{}, fmt.Errorf("invalid daytime: %q", daytime)
+	}
+	return query, nil
 }
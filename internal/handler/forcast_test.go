@@ -1,11 +1,17 @@
 package handler
 
 import (
+	"encoding/json"
 	"errors"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
+	"github.com/softstone1/fl/internal/client"
+	"github.com/softstone1/fl/internal/model"
+	"github.com/softstone1/fl/internal/renderer"
 	"github.com/softstone1/fl/internal/service"
 	"go.uber.org/mock/gomock"
 )
@@ -15,32 +21,97 @@ func TestGetRandomForecast(t *testing.T) {
     defer ctrl.Finish()
 
     mockForecastSvc := service.NewMockForecast(ctrl)
-    h := NewForecast(mockForecastSvc)
+    h := NewForecast(mockForecastSvc, newTestRenderer(t))
+
+    jsonForecast := &model.CurrentForecast{
+        Location: model.Location{Name: "Test Location"},
+        Period:   model.ForcastPeriod{Temperature: 72, TemperatureUnit: "F", ShortForecast: "Sunny", DetailedForecast: "Sunny"},
+    }
+    sunny := &model.CurrentForecast{
+        Location: model.Location{Name: "Test Location"},
+        Period:   model.ForcastPeriod{DetailedForecast: "sunny"},
+    }
+
+    value := func(v float64) *float64 { return &v }
+    observation := &model.Observation{
+        Station:            model.Station{ID: "KMHK", Name: "Manhattan Regional Airport"},
+        Timestamp:          time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC),
+        TextDescription:    "Clear",
+        Temperature:        model.QuantitativeValue{Value: value(21.1), UnitCode: "wmoUnit:degC"},
+        RelativeHumidity:   model.QuantitativeValue{Value: value(65.234), UnitCode: "wmoUnit:percent"},
+        WindSpeed:          model.QuantitativeValue{Value: value(18.36), UnitCode: "wmoUnit:km_h-1"},
+        WindDirection:      model.QuantitativeValue{Value: value(270), UnitCode: "wmoUnit:degree_(angle)"},
+        BarometricPressure: model.QuantitativeValue{UnitCode: "wmoUnit:Pa"},
+    }
 
     tests := []struct {
         name           string
         mockSetup      func()
+        accept         string
+        acceptLanguage string
         expectedStatus int
         expectedBody   string
+        // Content-Language of the successful responses, en when empty
+        expectedLanguage string
     }{
         {
             name: "Success returns 200 with 'sunny'",
             mockSetup: func() {
                 mockForecastSvc.
                     EXPECT().
-                    GetRandomForecast(gomock.Any()).
-                    Return("sunny", nil)
+                    GetRandomForecast(gomock.Any(), service.CurrentQuery{}).
+                    Return(sunny, nil)
+            },
+            expectedStatus: http.StatusOK,
+            expectedBody:   "The weather in Test Location is: sunny",
+        },
+        {
+            name: "Accept-Language selects the locale",
+            mockSetup: func() {
+                mockForecastSvc.
+                    EXPECT().
+                    GetRandomForecast(gomock.Any(), service.CurrentQuery{}).
+                    Return(sunny, nil)
+            },
+            acceptLanguage:   "fr-FR, es-MX;q=0.8, en;q=0.5",
+            expectedStatus:   http.StatusOK,
+            expectedBody:     "El tiempo en Test Location es: sunny",
+            expectedLanguage: "es",
+        },
+        {
+            name: "Success returns JSON when accepted",
+            mockSetup: func() {
+                mockForecastSvc.
+                    EXPECT().
+                    GetRandomForecast(gomock.Any(), service.CurrentQuery{}).
+                    Return(jsonForecast, nil)
+            },
+            accept:         "application/json",
+            expectedStatus: http.StatusOK,
+            expectedBody: mustJSON(t, currentForecastResponse{
+                CurrentForecast: jsonForecast,
+                Text:            "The weather in Test Location is: Sunny",
+            }),
+        },
+        {
+            name: "Observation is listed below the forecast",
+            mockSetup: func() {
+                mockForecastSvc.
+                    EXPECT().
+                    GetRandomForecast(gomock.Any(), service.CurrentQuery{}).
+                    Return(&model.CurrentForecast{Location: sunny.Location, Period: sunny.Period, Observation: observation}, nil)
             },
             expectedStatus: http.StatusOK,
-            expectedBody:   "sunny",
+            expectedBody: "The weather in Test Location is: sunny\n" +
+                "Observed at Manhattan Regional Airport (2025-01-01T12:00:00Z): Clear, 21.1°C, humidity 65.2%, wind 18.4 km/h from 270°\n",
         },
         {
             name: "Service error returns 500",
             mockSetup: func() {
                 mockForecastSvc.
                     EXPECT().
-                    GetRandomForecast(gomock.Any()).
-                    Return("", errors.New("some error"))
+                    GetRandomForecast(gomock.Any(), service.CurrentQuery{}).
+                    Return(nil, errors.New("some error"))
             },
             expectedStatus: http.StatusInternalServerError,
             expectedBody: "some error\n",
@@ -52,6 +123,12 @@ func TestGetRandomForecast(t *testing.T) {
             tc.mockSetup()
 
             req := httptest.NewRequest(http.MethodGet, "/forecast", nil)
+            if tc.accept != "" {
+                req.Header.Set("Accept", tc.accept)
+            }
+            if tc.acceptLanguage != "" {
+                req.Header.Set("Accept-Language", tc.acceptLanguage)
+            }
             rr := httptest.NewRecorder()
 
             h.GetRandomForecast(rr, req)
@@ -63,6 +140,381 @@ func TestGetRandomForecast(t *testing.T) {
             if body := rr.Body.String(); body != tc.expectedBody {
                 t.Errorf("expected body %q, got %q", tc.expectedBody, body)
             }
+
+            if tc.expectedStatus == http.StatusOK {
+                expectedLanguage := tc.expectedLanguage
+                if expectedLanguage == "" {
+                    expectedLanguage = "en"
+                }
+                if language := rr.Header().Get("Content-Language"); language != expectedLanguage {
+                    t.Errorf("expected Content-Language %q, got %q", expectedLanguage, language)
+                }
+                if vary := rr.Header().Get("Vary"); vary != "Accept-Language" {
+                    t.Errorf("expected Vary %q, got %q", "Accept-Language", vary)
+                }
+            }
+        })
+    }
+}
+
+func TestGetRandomForecast_Seed(t *testing.T) {
+    ctrl := gomock.NewController(t)
+    defer ctrl.Finish()
+
+    mockForecastSvc := service.NewMockForecast(ctrl)
+    h := NewForecast(mockForecastSvc, newTestRenderer(t))
+
+    seed := int64(123)
+    mockForecastSvc.
+        EXPECT().
+        GetRandomForecast(gomock.Any(), service.CurrentQuery{Seed: &seed}).
+        Return(&model.CurrentForecast{
+            Location: model.Location{Name: "Test Location", Seed: &seed},
+            Period:   model.ForcastPeriod{DetailedForecast: "sunny"},
+        }, nil)
+
+    req := httptest.NewRequest(http.MethodGet, "/forecast?seed=123", nil)
+    rr := httptest.NewRecorder()
+
+    h.GetRandomForecast(rr, req)
+
+    if rr.Code != http.StatusOK {
+        t.Errorf("expected status %d, got %d", http.StatusOK, rr.Code)
+    }
+    if header := rr.Header().Get("X-Seed"); header != "123" {
+        t.Errorf("expected X-Seed %q, got %q", "123", header)
+    }
+
+    req = httptest.NewRequest(http.MethodGet, "/forecast?seed=abc", nil)
+    rr = httptest.NewRecorder()
+
+    h.GetRandomForecast(rr, req)
+
+    if rr.Code != http.StatusBadRequest {
+        t.Errorf("expected status %d, got %d", http.StatusBadRequest, rr.Code)
+    }
+    if body, expected := rr.Body.String(), "invalid seed: \"abc\"\n"; body != expected {
+        t.Errorf("expected body %q, got %q", expected, body)
+    }
+}
+
+func TestGetRandomForecast_Region(t *testing.T) {
+    ctrl := gomock.NewController(t)
+    defer ctrl.Finish()
+
+    mockForecastSvc := service.NewMockForecast(ctrl)
+    h := NewForecast(mockForecastSvc, newTestRenderer(t))
+
+    colorado := &service.Region{
+        States: []string{"CO", "WY"},
+        Boxes:  []client.BoundingBox{{South: 37, West: -109, North: 41, East: -102}},
+    }
+
+    tests := []struct {
+        name           string
+        target         string
+        mockSetup      func()
+        expectedStatus int
+        expectedBody   string
+    }{
+        {
+            name:   "Region and bbox are passed to the service",
+            target: "/forecast?region=co,Wyoming&bbox=37,-109,41,-102",
+            mockSetup: func() {
+                mockForecastSvc.
+                    EXPECT().
+                    GetRandomForecast(gomock.Any(), service.CurrentQuery{Region: colorado}).
+                    Return(&model.CurrentForecast{
+                        Location: model.Location{Name: "Boulder, CO"},
+                        Period:   model.ForcastPeriod{DetailedForecast: "sunny"},
+                    }, nil)
+            },
+            expectedStatus: http.StatusOK,
+            expectedBody:   "The weather in Boulder, CO is: sunny",
+        },
+        {
+            name:   "No location in the region returns 404",
+            target: "/forecast?region=CO",
+            mockSetup: func() {
+                mockForecastSvc.
+                    EXPECT().
+                    GetRandomForecast(gomock.Any(), service.CurrentQuery{Region: &service.Region{States: []string{"CO"}}}).
+                    Return(nil, fmt.Errorf("Stage 1 - FetchLocation error: %w", service.ErrNoLocationInRegion))
+            },
+            expectedStatus: http.StatusNotFound,
+            expectedBody:   "Stage 1 - FetchLocation error: no random location found in the region\n",
+        },
+        {
+            name:           "Unknown state returns 400",
+            target:         "/forecast?region=Atlantis",
+            mockSetup:      func() {},
+            expectedStatus: http.StatusBadRequest,
+            expectedBody:   "invalid region: unknown US state \"Atlantis\"\n",
+        },
+        {
+            name:           "Invalid bbox returns 400",
+            target:         "/forecast?bbox=41,-109,37,-102",
+            mockSetup:      func() {},
+            expectedStatus: http.StatusBadRequest,
+            expectedBody:   "invalid bbox: invalid bounding box latitudes in \"41,-109,37,-102\"\n",
+        },
+    }
+
+    for _, tc := range tests {
+        t.Run(tc.name, func(t *testing.T) {
+            tc.mockSetup()
+
+            req := httptest.NewRequest(http.MethodGet, tc.target, nil)
+            rr := httptest.NewRecorder()
+
+            h.GetRandomForecast(rr, req)
+
+            if rr.Code != tc.expectedStatus {
+                t.Errorf("expected status %d, got %d", tc.expectedStatus, rr.Code)
+            }
+
+            if body := rr.Body.String(); body != tc.expectedBody {
+                t.Errorf("expected body %q, got %q", tc.expectedBody, body)
+            }
+        })
+    }
+}
+
+// newTestRenderer returns a renderer with the bundled templates
+func newTestRenderer(t *testing.T) *renderer.Renderer {
+    t.Helper()
+    r, err := renderer.New("")
+    if err != nil {
+        t.Fatalf("failed to load templates: %v", err)
+    }
+    return r
+}
+
+// mustJSON returns the JSON encoding of v as written by writeJSON
+func mustJSON(t *testing.T, v any) string {
+    t.Helper()
+    data, err := json.Marshal(v)
+    if err != nil {
+        t.Fatalf("failed to encode %v: %v", v, err)
+    }
+    return string(data) + "\n"
+}
+
+func TestGetForecastPeriods(t *testing.T) {
+    ctrl := gomock.NewController(t)
+    defer ctrl.Finish()
+
+    mockForecastSvc := service.NewMockForecast(ctrl)
+    h := NewForecast(mockForecastSvc, newTestRenderer(t))
+
+    periods := &model.ForecastPeriods{
+        Periods: []model.ForcastPeriod{
+            {Name: "Tonight", DetailedForecast: "Clear."},
+            {Name: "Thursday", DetailedForecast: "Sunny."},
+        },
+    }
+
+    tests := []struct {
+        name           string
+        target         string
+        mockSetup      func()
+        expectedStatus int
+        expectedBody   string
+    }{
+        {
+            name:   "Random location lists periods",
+            target: "/forecast/periods",
+            mockSetup: func() {
+                mockForecastSvc.
+                    EXPECT().
+                    GetForecastPeriods(gomock.Any(), service.PeriodsQuery{}).
+                    Return(periods, nil)
+            },
+            expectedStatus: http.StatusOK,
+            expectedBody:   "Tonight: Clear.\nThursday: Sunny.\n",
+        },
+        {
+            name:   "Filters are passed to the service",
+            target: "/forecast/periods?lat=39.7456&lng=-97.0892&from=2025-01-02T00:00:00Z&to=2025-01-03T00:00:00Z&limit=2&daytime=only&format=json",
+            mockSetup: func() {
+                mockForecastSvc.
+                    EXPECT().
+                    GetForecastPeriods(gomock.Any(), service.PeriodsQuery{
+                        Location:    &model.Location{Latitude: 39.7456, Longitude: -97.0892},
+                        From:        time.Date(2025, 1, 2, 0, 0, 0, 0, time.UTC),
+                        To:          time.Date(2025, 1, 3, 0, 0, 0, 0, time.UTC),
+                        Limit:       2,
+                        DaytimeOnly: true,
+                    }).
+                    Return(periods, nil)
+            },
+            expectedStatus: http.StatusOK,
+            expectedBody:   mustJSON(t, periods),
+        },
+        {
+            name:           "Missing lng returns 400",
+            target:         "/forecast/periods?lat=39.7456",
+            mockSetup:      func() {},
+            expectedStatus: http.StatusBadRequest,
+            expectedBody:   "lat and lng must be given together\n",
+        },
+        {
+            name:           "Invalid limit returns 400",
+            target:         "/forecast/periods?limit=-1",
+            mockSetup:      func() {},
+            expectedStatus: http.StatusBadRequest,
+            expectedBody:   "invalid limit: \"-1\"\n",
+        },
+        {
+            name:           "From after to returns 400",
+            target:         "/forecast/periods?from=2025-01-03T00:00:00Z&to=2025-01-02T00:00:00Z",
+            mockSetup:      func() {},
+            expectedStatus: http.StatusBadRequest,
+            expectedBody:   "from must not be after to\n",
+        },
+        {
+            name:           "Invalid daytime returns 400",
+            target:         "/forecast/periods?daytime=never",
+            mockSetup:      func() {},
+            expectedStatus: http.StatusBadRequest,
+            expectedBody:   "invalid daytime: \"never\"\n",
+        },
+    }
+
+    for _, tc := range tests {
+        t.Run(tc.name, func(t *testing.T) {
+            tc.mockSetup()
+
+            req := httptest.NewRequest(http.MethodGet, tc.target, nil)
+            rr := httptest.NewRecorder()
+
+            h.GetForecastPeriods(rr, req)
+
+            if rr.Code != tc.expectedStatus {
+                t.Errorf("expected status %d, got %d", tc.expectedStatus, rr.Code)
+            }
+
+            if body := rr.Body.String(); body != tc.expectedBody {
+                t.Errorf("expected body %q, got %q", tc.expectedBody, body)
+            }
+        })
+    }
+}
+
+func TestGetHourlyForecast(t *testing.T) {
+    ctrl := gomock.NewController(t)
+    defer ctrl.Finish()
+
+    mockForecastSvc := service.NewMockForecast(ctrl)
+    h := NewForecast(mockForecastSvc, newTestRenderer(t))
+
+    hourly := &model.ForecastPeriods{
+        Periods: []model.ForcastPeriod{
+            {
+                StartTime:       time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC),
+                Temperature:     41,
+                TemperatureUnit: "F",
+                WindSpeed:       "5 mph",
+                WindDirection:   "S",
+                ShortForecast:   "Sunny",
+            },
+        },
+    }
+
+    tests := []struct {
+        name           string
+        target         string
+        mockSetup      func()
+        expectedStatus int
+        expectedBody   string
+    }{
+        {
+            name:   "Hours set the horizon",
+            target: "/forecast/hourly?hours=6",
+            mockSetup: func() {
+                mockForecastSvc.
+                    EXPECT().
+                    GetHourlyForecast(gomock.Any(), service.HourlyQuery{Horizon: 6 * time.Hour}).
+                    Return(hourly, nil)
+            },
+            expectedStatus: http.StatusOK,
+            expectedBody:   "2025-01-01T12:00:00Z: Sunny, 41°F, wind 5 mph S\n",
+        },
+        {
+            name:   "Units are passed to the service",
+            target: "/forecast/hourly?hours=6&units=si",
+            mockSetup: func() {
+                mockForecastSvc.
+                    EXPECT().
+                    GetHourlyForecast(gomock.Any(), service.HourlyQuery{Horizon: 6 * time.Hour, Units: model.UnitsSI}).
+                    Return(hourly, nil)
+            },
+            expectedStatus: http.StatusOK,
+            expectedBody:   "2025-01-01T12:00:00Z: Sunny, 41°F, wind 5 mph S\n",
+        },
+        {
+            name:   "Time zone is passed to the service",
+            target: "/forecast/hourly?tz=UTC",
+            mockSetup: func() {
+                mockForecastSvc.
+                    EXPECT().
+                    GetHourlyForecast(gomock.Any(), service.HourlyQuery{TimeZone: time.UTC}).
+                    Return(hourly, nil)
+            },
+            expectedStatus: http.StatusOK,
+            expectedBody:   "2025-01-01T12:00:00Z: Sunny, 41°F, wind 5 mph S\n",
+        },
+        {
+            name:           "Invalid time zone returns 400",
+            target:         "/forecast/hourly?tz=Mars/Olympus_Mons",
+            mockSetup:      func() {},
+            expectedStatus: http.StatusBadRequest,
+            expectedBody:   "invalid tz: \"Mars/Olympus_Mons\"\n",
+        },
+        {
+            name:           "Invalid units return 400",
+            target:         "/forecast/hourly?units=kelvin",
+            mockSetup:      func() {},
+            expectedStatus: http.StatusBadRequest,
+            expectedBody:   "invalid units: \"kelvin\"\n",
+        },
+        {
+            name:           "Hours beyond the NWS horizon return 400",
+            target:         "/forecast/hourly?hours=200",
+            mockSetup:      func() {},
+            expectedStatus: http.StatusBadRequest,
+            expectedBody:   "invalid hours: \"200\"\n",
+        },
+        {
+            name:   "Service error returns 500",
+            target: "/forecast/hourly",
+            mockSetup: func() {
+                mockForecastSvc.
+                    EXPECT().
+                    GetHourlyForecast(gomock.Any(), service.HourlyQuery{}).
+                    Return(nil, errors.New("some error"))
+            },
+            expectedStatus: http.StatusInternalServerError,
+            expectedBody:   "some error\n",
+        },
+    }
+
+    for _, tc := range tests {
+        t.Run(tc.name, func(t *testing.T) {
+            tc.mockSetup()
+
+            req := httptest.NewRequest(http.MethodGet, tc.target, nil)
+            rr := httptest.NewRecorder()
+
+            h.GetHourlyForecast(rr, req)
+
+            if rr.Code != tc.expectedStatus {
+                t.Errorf("expected status %d, got %d", tc.expectedStatus, rr.Code)
+            }
+
+            if body := rr.Body.String(); body != tc.expectedBody {
+                t.Errorf("expected body %q, got %q", tc.expectedBody, body)
+            }
         })
     }
-}
\ No newline at end of file
+}
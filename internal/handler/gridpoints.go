@@ -0,0 +1,71 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/softstone1/fl/internal/renderer"
+	"github.com/softstone1/fl/internal/service"
+)
+
+// GetGridpoints get the raw gridpoint layers for the lat/lng location, or a random one, resampled hourly
+// up to the given number of hours ahead. The layers are selected with a comma separated layers list,
+// converted with units=us|si and their times set in the tz time zone instead of the location one.
+func (f *Forecast) GetGridpoints(w http.ResponseWriter, r *http.Request) {
+	location, err := parseCoordinates(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	horizon, err := parseHours(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	units, err := parseUnits(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	zone, err := parseTimeZone(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	seed, err := parseSeed(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	region, err := parseRegion(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	query := service.GridpointsQuery{Location: location, Seed: seed, Region: region, Horizon: horizon, Units: units, TimeZone: zone}
+	if layers := r.URL.Query().Get("layers"); layers != "" {
+		for _, layer := range strings.Split(layers, ",") {
+			if layer = strings.TrimSpace(layer); layer != "" {
+				query.Layers = append(query.Layers, layer)
+			}
+		}
+	}
+
+	ctx := r.Context()
+	resp, err := f.ForcastService.GetGridpoints(ctx, query)
+	if errors.Is(err, service.ErrUnknownGridLayer) {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), serviceErrorStatus(err))
+		return
+	}
+	writeSeed(w, resp.Location)
+	if wantsJSON(r) {
+		writeJSON(w, http.StatusOK, resp)
+		return
+	}
+	f.writeRendered(w, r, renderer.Gridpoints, resp)
+}
@@ -0,0 +1,97 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/softstone1/fl/internal/model"
+	"github.com/softstone1/fl/internal/service"
+	"go.uber.org/mock/gomock"
+)
+
+func TestGetGridpoints(t *testing.T) {
+    ctrl := gomock.NewController(t)
+    defer ctrl.Finish()
+
+    mockForecastSvc := service.NewMockForecast(ctrl)
+    h := NewForecast(mockForecastSvc, newTestRenderer(t))
+
+    value := func(v float64) *float64 { return &v }
+    start := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+    gridpoints := &model.GridSeriesData{
+        Times: []time.Time{start, start.Add(time.Hour)},
+        Layers: []model.GridSeries{
+            {Name: "temperature", UnitCode: "wmoUnit:degC", Values: []*float64{value(1.25), nil}},
+            {Name: "windSpeed", UnitCode: "wmoUnit:km_h-1", Values: []*float64{value(9.26), value(11.111)}},
+        },
+    }
+
+    tests := []struct {
+        name           string
+        target         string
+        mockSetup      func()
+        expectedStatus int
+        expectedBody   string
+    }{
+        {
+            name:   "Layers are listed as CSV",
+            target: "/forecast/gridpoints?lat=39.7456&lng=-97.0892&layers=temperature,%20windSpeed&hours=2&units=si",
+            mockSetup: func() {
+                mockForecastSvc.
+                    EXPECT().
+                    GetGridpoints(gomock.Any(), service.GridpointsQuery{
+                        Location: &model.Location{Latitude: 39.7456, Longitude: -97.0892},
+                        Layers:   []string{"temperature", "windSpeed"},
+                        Horizon:  2 * time.Hour,
+                        Units:    model.UnitsSI,
+                    }).
+                    Return(gridpoints, nil)
+            },
+            expectedStatus: http.StatusOK,
+            expectedBody: "time,temperature (°C),windSpeed (km/h)\n" +
+                "2025-01-01T12:00:00Z,1.25,9.26\n" +
+                "2025-01-01T13:00:00Z,,11.11\n",
+        },
+        {
+            name:   "Unknown layer returns 400",
+            target: "/forecast/gridpoints?layers=pollen",
+            mockSetup: func() {
+                mockForecastSvc.
+                    EXPECT().
+                    GetGridpoints(gomock.Any(), service.GridpointsQuery{Layers: []string{"pollen"}}).
+                    Return(nil, fmt.Errorf("%w: %q", service.ErrUnknownGridLayer, "pollen"))
+            },
+            expectedStatus: http.StatusBadRequest,
+            expectedBody:   "unknown gridpoint layer: \"pollen\"\n",
+        },
+        {
+            name:           "Invalid hours return 400",
+            target:         "/forecast/gridpoints?hours=0",
+            mockSetup:      func() {},
+            expectedStatus: http.StatusBadRequest,
+            expectedBody:   "invalid hours: \"0\"\n",
+        },
+    }
+
+    for _, tc := range tests {
+        t.Run(tc.name, func(t *testing.T) {
+            tc.mockSetup()
+
+            req := httptest.NewRequest(http.MethodGet, tc.target, nil)
+            rr := httptest.NewRecorder()
+
+            h.GetGridpoints(rr, req)
+
+            if rr.Code != tc.expectedStatus {
+                t.Errorf("expected status %d, got %d", tc.expectedStatus, rr.Code)
+            }
+
+            if body := rr.Body.String(); body != tc.expectedBody {
+                t.Errorf("expected body %q, got %q", tc.expectedBody, body)
+            }
+        })
+    }
+}
@@ -0,0 +1,77 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/softstone1/fl/internal/model"
+	"github.com/softstone1/fl/internal/service"
+)
+
+// GetForecast get current detailed forcast for the q place name, e.g. Seattle,WA, the lat/lng location
+// or a random one, in the given units and tz time zone. Ambiguous place names list their candidates.
+// With consensus=true the forecasts of every provider of the location are compared.
+func (f *Forecast) GetForecast(w http.ResponseWriter, r *http.Request) {
+	location, err := parseCoordinates(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	place := strings.TrimSpace(r.URL.Query().Get("q"))
+	if place != "" && location != nil {
+		http.Error(w, "q and lat/lng cannot be given together", http.StatusBadRequest)
+		return
+	}
+	units, err := parseUnits(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	zone, err := parseTimeZone(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	seed, err := parseSeed(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	region, err := parseRegion(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	consensus, err := parseConsensus(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	query := service.CurrentQuery{Location: location, Place: place, Seed: seed, Region: region, Units: units, TimeZone: zone, Consensus: consensus}
+
+	ctx := r.Context()
+	resp, err := f.ForcastService.GetRandomForecast(ctx, query)
+	var ambiguous *service.AmbiguousPlaceError
+	switch {
+	case errors.As(err, &ambiguous):
+		writeJSON(w, http.StatusMultipleChoices, placeCandidatesResponse{Query: ambiguous.Query, Candidates: ambiguous.Candidates})
+		return
+	case errors.Is(err, service.ErrPlaceNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	case errors.Is(err, service.ErrGeocodingDisabled):
+		http.Error(w, err.Error(), http.StatusNotImplemented)
+		return
+	case err != nil:
+		http.Error(w, err.Error(), serviceErrorStatus(err))
+		return
+	}
+	f.writeCurrentForecast(w, r, resp)
+}
+
+// placeCandidatesResponse lists the places matching an ambiguous place name
+type placeCandidatesResponse struct {
+	Query      string        `json:"query"`
+	Candidates []model.Place `json:"candidates"`
+}
@@ -0,0 +1,182 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/softstone1/fl/internal/model"
+	"github.com/softstone1/fl/internal/service"
+	"go.uber.org/mock/gomock"
+)
+
+func TestGetForecast(t *testing.T) {
+    ctrl := gomock.NewController(t)
+    defer ctrl.Finish()
+
+    mockForecastSvc := service.NewMockForecast(ctrl)
+    h := NewForecast(mockForecastSvc, newTestRenderer(t))
+
+    seattle := &model.CurrentForecast{
+        Location: model.Location{Name: "Seattle, WA"},
+        Period:   model.ForcastPeriod{DetailedForecast: "rainy"},
+    }
+    spread := 10.0
+    compared := &model.CurrentForecast{
+        Location: model.Location{Name: "Seattle, WA"},
+        Period:   model.ForcastPeriod{DetailedForecast: "rainy", Temperature: 50},
+        Source:   "nws",
+        Consensus: &model.Consensus{
+            Forecasts: []model.SourcePeriod{
+                {Source: "nws", Period: &model.ForcastPeriod{Temperature: 50}},
+                {Source: "open-meteo", Period: &model.ForcastPeriod{Temperature: 53}},
+            },
+            TemperatureSpread:   3,
+            PrecipitationSpread: &spread,
+        },
+    }
+    candidates := []model.Place{
+        {Name: "Portland", State: "OR", Latitude: 45.5152, Longitude: -122.6784, Population: 652503},
+        {Name: "Portland", State: "ME", Latitude: 43.6591, Longitude: -70.2568, Population: 68408},
+    }
+
+    tests := []struct {
+        name           string
+        target         string
+        mockSetup      func()
+        expectedStatus int
+        expectedBody   string
+    }{
+        {
+            name:   "Place name",
+            target: "/forecast?q=Seattle,WA",
+            mockSetup: func() {
+                mockForecastSvc.
+                    EXPECT().
+                    GetRandomForecast(gomock.Any(), service.CurrentQuery{Place: "Seattle,WA"}).
+                    Return(seattle, nil)
+            },
+            expectedStatus: http.StatusOK,
+            expectedBody:   "The weather in Seattle, WA is: rainy",
+        },
+        {
+            name:   "Coordinates",
+            target: "/forecast?lat=47.6062&lng=-122.3321",
+            mockSetup: func() {
+                mockForecastSvc.
+                    EXPECT().
+                    GetRandomForecast(gomock.Any(), service.CurrentQuery{Location: &model.Location{Latitude: 47.6062, Longitude: -122.3321}}).
+                    Return(seattle, nil)
+            },
+            expectedStatus: http.StatusOK,
+            expectedBody:   "The weather in Seattle, WA is: rainy",
+        },
+        {
+            name:   "Ambiguous place lists the candidates",
+            target: "/forecast?q=Portland",
+            mockSetup: func() {
+                mockForecastSvc.
+                    EXPECT().
+                    GetRandomForecast(gomock.Any(), service.CurrentQuery{Place: "Portland"}).
+                    Return(nil, &service.AmbiguousPlaceError{Query: "Portland", Candidates: candidates})
+            },
+            expectedStatus: http.StatusMultipleChoices,
+            expectedBody:   mustJSON(t, placeCandidatesResponse{Query: "Portland", Candidates: candidates}),
+        },
+        {
+            name:   "Unknown place returns 404",
+            target: "/forecast?q=Gotham",
+            mockSetup: func() {
+                mockForecastSvc.
+                    EXPECT().
+                    GetRandomForecast(gomock.Any(), service.CurrentQuery{Place: "Gotham"}).
+                    Return(nil, fmt.Errorf("%w: %q", service.ErrPlaceNotFound, "Gotham"))
+            },
+            expectedStatus: http.StatusNotFound,
+            expectedBody:   "place not found: \"Gotham\"\n",
+        },
+        {
+            name:   "Disabled place lookup returns 501",
+            target: "/forecast?q=Seattle",
+            mockSetup: func() {
+                mockForecastSvc.
+                    EXPECT().
+                    GetRandomForecast(gomock.Any(), service.CurrentQuery{Place: "Seattle"}).
+                    Return(nil, service.ErrGeocodingDisabled)
+            },
+            expectedStatus: http.StatusNotImplemented,
+            expectedBody:   "place lookup is not enabled\n",
+        },
+        {
+            name:   "Consensus of the providers",
+            target: "/forecast?q=Seattle,WA&consensus=true&format=json",
+            mockSetup: func() {
+                mockForecastSvc.
+                    EXPECT().
+                    GetRandomForecast(gomock.Any(), service.CurrentQuery{Place: "Seattle,WA", Consensus: true}).
+                    Return(compared, nil)
+            },
+            expectedStatus: http.StatusOK,
+            expectedBody:   mustJSON(t, currentForecastResponse{CurrentForecast: compared, Text: "The weather in Seattle, WA is: rainy"}),
+        },
+        {
+            name:   "Consensus with a single provider returns 400",
+            target: "/forecast?q=Seattle&consensus=true",
+            mockSetup: func() {
+                mockForecastSvc.
+                    EXPECT().
+                    GetRandomForecast(gomock.Any(), service.CurrentQuery{Place: "Seattle", Consensus: true}).
+                    Return(nil, service.ErrConsensusUnavailable)
+            },
+            expectedStatus: http.StatusBadRequest,
+            expectedBody:   "consensus needs more than one forecast provider\n",
+        },
+        {
+            name:           "Invalid consensus returns 400",
+            target:         "/forecast?q=Seattle&consensus=maybe",
+            mockSetup:      func() {},
+            expectedStatus: http.StatusBadRequest,
+            expectedBody:   "invalid consensus: \"maybe\"\n",
+        },
+        {
+            name:           "Place and coordinates are exclusive",
+            target:         "/forecast?q=Seattle&lat=47.6062&lng=-122.3321",
+            mockSetup:      func() {},
+            expectedStatus: http.StatusBadRequest,
+            expectedBody:   "q and lat/lng cannot be given together\n",
+        },
+        {
+            name:   "Service error returns 500",
+            target: "/forecast?q=Seattle",
+            mockSetup: func() {
+                mockForecastSvc.
+                    EXPECT().
+                    GetRandomForecast(gomock.Any(), service.CurrentQuery{Place: "Seattle"}).
+                    Return(nil, errors.New("some error"))
+            },
+            expectedStatus: http.StatusInternalServerError,
+            expectedBody:   "some error\n",
+        },
+    }
+
+    for _, tc := range tests {
+        t.Run(tc.name, func(t *testing.T) {
+            tc.mockSetup()
+
+            req := httptest.NewRequest(http.MethodGet, tc.target, nil)
+            rr := httptest.NewRecorder()
+
+            h.GetForecast(rr, req)
+
+            if rr.Code != tc.expectedStatus {
+                t.Errorf("expected status %d, got %d", tc.expectedStatus, rr.Code)
+            }
+
+            if body := rr.Body.String(); body != tc.expectedBody {
+                t.Errorf("expected body %q, got %q", tc.expectedBody, body)
+            }
+        })
+    }
+}
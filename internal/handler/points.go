@@ -0,0 +1,30 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/softstone1/fl/internal/renderer"
+	"github.com/softstone1/fl/internal/service"
+)
+
+// GetPoint get the NWS point metadata, such as the forecast grid cell, nearest city and time zone,
+// for the lat,lng coordinates of the URL
+func (f *Forecast) GetPoint(w http.ResponseWriter, r *http.Request) {
+	location, err := parsePointCoordinates(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	ctx := r.Context()
+	resp, err := f.ForcastService.GetPoint(ctx, service.PointQuery{Location: location})
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if wantsJSON(r) {
+		writeJSON(w, http.StatusOK, resp)
+		return
+	}
+	f.writeRendered(w, r, renderer.Point, resp)
+}
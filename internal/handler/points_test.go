@@ -0,0 +1,114 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/softstone1/fl/internal/model"
+	"github.com/softstone1/fl/internal/service"
+	"go.uber.org/mock/gomock"
+)
+
+func TestGetPoint(t *testing.T) {
+    ctrl := gomock.NewController(t)
+    defer ctrl.Finish()
+
+    mockForecastSvc := service.NewMockForecast(ctrl)
+    h := NewForecast(mockForecastSvc, newTestRenderer(t))
+    router := chi.NewRouter()
+    router.Get("/points/{coordinates}", h.GetPoint)
+
+    location := &model.Location{Latitude: 39.7456, Longitude: -97.0892}
+    point := &model.Point{
+        Location: model.Location{Latitude: 39.7456, Longitude: -97.0892, TimeZone: "America/Chicago"},
+        Metadata: model.PointMetadata{
+            GridID:           "TOP",
+            GridX:            32,
+            GridY:            81,
+            RelativeLocation: model.RelativeLocation{City: "Linn", State: "KS"},
+            TimeZone:         "America/Chicago",
+            RadarStation:     "KTWX",
+        },
+    }
+
+    tests := []struct {
+        name           string
+        target         string
+        mockSetup      func()
+        expectedStatus int
+        expectedBody   string
+    }{
+        {
+            name:   "Point metadata is rendered",
+            target: "/points/39.7456,-97.0892",
+            mockSetup: func() {
+                mockForecastSvc.
+                    EXPECT().
+                    GetPoint(gomock.Any(), service.PointQuery{Location: location}).
+                    Return(point, nil)
+            },
+            expectedStatus: http.StatusOK,
+            expectedBody:   "Grid TOP 32,81 near Linn, KS\nTime zone: America/Chicago\nRadar station: KTWX\n",
+        },
+        {
+            name:   "JSON point metadata",
+            target: "/points/39.7456,-97.0892?format=json",
+            mockSetup: func() {
+                mockForecastSvc.
+                    EXPECT().
+                    GetPoint(gomock.Any(), service.PointQuery{Location: location}).
+                    Return(point, nil)
+            },
+            expectedStatus: http.StatusOK,
+            expectedBody:   mustJSON(t, point),
+        },
+        {
+            name:           "Missing lng",
+            target:         "/points/39.7456",
+            mockSetup:      func() {},
+            expectedStatus: http.StatusBadRequest,
+            expectedBody:   "invalid coordinates: \"39.7456\"\n",
+        },
+        {
+            name:           "Out of range lat",
+            target:         "/points/91,-97.0892",
+            mockSetup:      func() {},
+            expectedStatus: http.StatusBadRequest,
+            expectedBody:   "invalid lat: \"91\"\n",
+        },
+        {
+            name:   "Service error returns 500",
+            target: "/points/39.7456,-97.0892",
+            mockSetup: func() {
+                mockForecastSvc.
+                    EXPECT().
+                    GetPoint(gomock.Any(), service.PointQuery{Location: location}).
+                    Return(nil, errors.New("some error"))
+            },
+            expectedStatus: http.StatusInternalServerError,
+            expectedBody:   "some error\n",
+        },
+    }
+
+    for _, tc := range tests {
+        t.Run(tc.name, func(t *testing.T) {
+            tc.mockSetup()
+
+            req := httptest.NewRequest(http.MethodGet, tc.target, nil)
+            rr := httptest.NewRecorder()
+
+            router.ServeHTTP(rr, req)
+
+            if rr.Code != tc.expectedStatus {
+                t.Errorf("expected status %d, got %d", tc.expectedStatus, rr.Code)
+            }
+
+            if body := rr.Body.String(); body != tc.expectedBody {
+                t.Errorf("expected body %q, got %q", tc.expectedBody, body)
+            }
+        })
+    }
+}
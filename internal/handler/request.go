@@ -0,0 +1,137 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/softstone1/fl/internal/model"
+	"github.com/softstone1/fl/internal/service"
+)
+
+// parseCoordinates reads the optional lat and lng query parameters, returning nil when both are missing
+func parseCoordinates(r *http.Request) (*model.Location, error) {
+	query := r.URL.Query()
+	latParam, lngParam := query.Get("lat"), query.Get("lng")
+	if latParam == "" && lngParam == "" {
+		return nil, nil
+	}
+	if latParam == "" || lngParam == "" {
+		return nil, fmt.Errorf("lat and lng must be given together")
+	}
+	return parseLatLng(latParam, lngParam)
+}
+
+// parsePointCoordinates reads the lat,lng coordinates URL parameter
+func parsePointCoordinates(r *http.Request) (*model.Location, error) {
+	coordinatesParam := chi.URLParam(r, "coordinates")
+	latParam, lngParam, found := strings.Cut(coordinatesParam, ",")
+	if !found {
+		return nil, fmt.Errorf("invalid coordinates: %q", coordinatesParam)
+	}
+	return parseLatLng(latParam, lngParam)
+}
+
+// parseLatLng parses and range checks the lat and lng of a location
+func parseLatLng(latParam, lngParam string) (*model.Location, error) {
+	lat, err := strconv.ParseFloat(latParam, 64)
+	if err != nil || lat < -90 || lat > 90 {
+		return nil, fmt.Errorf("invalid lat: %q", latParam)
+	}
+	lng, err := strconv.ParseFloat(lngParam, 64)
+	if err != nil || lng < -180 || lng > 180 {
+		return nil, fmt.Errorf("invalid lng: %q", lngParam)
+	}
+	return &model.Location{Latitude: lat, Longitude: lng}, nil
+}
+
+// parseSeed reads the optional seed query parameter, returning nil when it is missing
+func parseSeed(r *http.Request) (*int64, error) {
+	seedParam := r.URL.Query().Get("seed")
+	if seedParam == "" {
+		return nil, nil
+	}
+	seed, err := strconv.ParseInt(seedParam, 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid seed: %q", seedParam)
+	}
+	return &seed, nil
+}
+
+// parseRegion reads the optional region (US state codes or names) and bbox (south,west,north,east bounding
+// boxes separated by semicolons) query parameters restricting random locations, returning nil when both are missing
+func parseRegion(r *http.Request) (*service.Region, error) {
+	query := r.URL.Query()
+	regionParam, bboxParam := query.Get("region"), query.Get("bbox")
+	if regionParam == "" && bboxParam == "" {
+		return nil, nil
+	}
+	states, err := service.ParseRegion(regionParam, "")
+	if err != nil {
+		return nil, fmt.Errorf("invalid region: %w", err)
+	}
+	boxes, err := service.ParseRegion("", bboxParam)
+	if err != nil {
+		return nil, fmt.Errorf("invalid bbox: %w", err)
+	}
+	return &service.Region{States: states.States, Boxes: boxes.Boxes}, nil
+}
+
+// maxHourlyForecastHours is how far ahead NWS provides hourly forecasts
+const maxHourlyForecastHours = 156
+
+// parseHours reads the optional hours query parameter, returning zero when it is missing
+func parseHours(r *http.Request) (time.Duration, error) {
+	hoursParam := r.URL.Query().Get("hours")
+	if hoursParam == "" {
+		return 0, nil
+	}
+	hours, err := strconv.Atoi(hoursParam)
+	if err != nil || hours < 1 || hours > maxHourlyForecastHours {
+		return 0, fmt.Errorf("invalid hours: %q", hoursParam)
+	}
+	return time.Duration(hours) * time.Hour, nil
+}
+
+// parseUnits reads the optional units query parameter, returning an empty unit system when it is missing
+func parseUnits(r *http.Request) (model.Units, error) {
+	unitsParam := r.URL.Query().Get("units")
+	if unitsParam == "" {
+		return "", nil
+	}
+	units, err := model.ParseUnits(unitsParam)
+	if err != nil {
+		return "", fmt.Errorf("invalid units: %q", unitsParam)
+	}
+	return units, nil
+}
+
+// parseTimeZone reads the optional tz query parameter, an IANA time zone name such as America/Chicago or UTC,
+// returning nil when it is missing
+func parseTimeZone(r *http.Request) (*time.Location, error) {
+	tzParam := r.URL.Query().Get("tz")
+	if tzParam == "" {
+		return nil, nil
+	}
+	zone, err := time.LoadLocation(tzParam)
+	if err != nil || tzParam == "Local" {
+		return nil, fmt.Errorf("invalid tz: %q", tzParam)
+	}
+	return zone, nil
+}
+
+// parseConsensus reads the optional consensus query parameter asking to compare the forecasts of every provider
+func parseConsensus(r *http.Request) (bool, error) {
+	consensusParam := r.URL.Query().Get("consensus")
+	if consensusParam == "" {
+		return false, nil
+	}
+	consensus, err := strconv.ParseBool(consensusParam)
+	if err != nil {
+		return false, fmt.Errorf("invalid consensus: %q", consensusParam)
+	}
+	return consensus, nil
+}
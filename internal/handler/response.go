@@ -0,0 +1,93 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/softstone1/fl/internal/model"
+	"github.com/softstone1/fl/internal/service"
+)
+
+// wantsJSON reports whether the client asked for a JSON response, either with ?format=json
+// or with an Accept header listing application/json
+func wantsJSON(r *http.Request) bool {
+	if format := r.URL.Query().Get("format"); format != "" {
+		return format == "json"
+	}
+	for _, accept := range r.Header.Values("Accept") {
+		for _, mediaType := range strings.Split(accept, ",") {
+			if strings.HasPrefix(strings.TrimSpace(mediaType), "application/json") {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// writeJSON writes v as the JSON response body
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		log.Printf("Failed to write JSON response: %v", err)
+	}
+}
+
+// writeText writes the plain text response body
+func writeText(w http.ResponseWriter, text string) {
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.Write([]byte(text))
+}
+
+// serviceErrorStatus returns the status of the response to a service error
+func serviceErrorStatus(err error) int {
+	var ambiguous *service.AmbiguousPlaceError
+	switch {
+	case errors.As(err, &ambiguous):
+		return http.StatusMultipleChoices
+	case errors.Is(err, service.ErrNoLocationInRegion), errors.Is(err, service.ErrPlaceNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, service.ErrGeocodingDisabled):
+		return http.StatusNotImplemented
+	case errors.Is(err, service.ErrConsensusUnavailable):
+		return http.StatusBadRequest
+	case errors.Is(err, context.DeadlineExceeded):
+		// the upstream APIs did not answer in time, which is not a bug of the service
+		return http.StatusGatewayTimeout
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// writeSeed sets the X-Seed header to the seed replaying the random location, when it is one
+func writeSeed(w http.ResponseWriter, location model.Location) {
+	if location.Seed != nil {
+		w.Header().Set("X-Seed", strconv.FormatInt(*location.Seed, 10))
+	}
+}
+
+// locale returns the locale of the text responses, negotiated from the Accept-Language headers.
+// It sets the Content-Language of the response, and lists Accept-Language in its Vary header so that
+// shared caches keep a response per language.
+func (f *Forecast) locale(w http.ResponseWriter, r *http.Request) string {
+	locale := f.Renderer.Locale(strings.Join(r.Header.Values("Accept-Language"), ","))
+	w.Header().Add("Vary", "Accept-Language")
+	w.Header().Set("Content-Language", locale)
+	return locale
+}
+
+// writeRendered renders the template called name with data as the plain text response body
+func (f *Forecast) writeRendered(w http.ResponseWriter, r *http.Request, name string, data any) {
+	locale := f.locale(w, r)
+	var b strings.Builder
+	if err := f.Renderer.Render(&b, locale, name, data); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	writeText(w, b.String())
+}
@@ -0,0 +1,20 @@
+package model
+
+import "time"
+
+// Alert is an active NWS weather alert. Onset and Expires are zero when NWS does not provide them.
+type Alert struct {
+	ID       string    `json:"id"`
+	Event    string    `json:"event"`
+	Headline string    `json:"headline"`
+	Severity string    `json:"severity"`
+	Urgency  string    `json:"urgency"`
+	Onset    time.Time `json:"onset"`
+	Expires  time.Time `json:"expires"`
+}
+
+// LocationAlerts is the list of active alerts at a location
+type LocationAlerts struct {
+	Location Location `json:"location"`
+	Alerts   []Alert  `json:"alerts"`
+}
@@ -3,9 +3,70 @@ package model
 import "time"
 
 type ForcastPeriod struct {
-	Number           int       `json:"number"`
-	Name             string    `json:"name"`
-	StartTime        time.Time `json:"startTime"`
-	EndTime          time.Time `json:"endTime"`
-	DetailedForecast string    `json:"detailedForecast"`
+	Number                     int               `json:"number"`
+	Name                       string            `json:"name"`
+	StartTime                  time.Time         `json:"startTime"`
+	EndTime                    time.Time         `json:"endTime"`
+	IsDaytime                  bool              `json:"isDaytime"`
+	Temperature                float64           `json:"temperature"`
+	TemperatureUnit            string            `json:"temperatureUnit"`
+	ProbabilityOfPrecipitation QuantitativeValue `json:"probabilityOfPrecipitation"`
+	WindSpeed                  string            `json:"windSpeed"`
+	WindDirection              string            `json:"windDirection"`
+	Icon                       string            `json:"icon"`
+	ShortForecast              string            `json:"shortForecast"`
+	DetailedForecast           string            `json:"detailedForecast"`
+}
+
+// QuantitativeValue is a measurement with its unit, e.g. {"unitCode": "wmoUnit:percent", "value": 20}.
+// Value is nil when NWS has no value.
+type QuantitativeValue struct {
+	Value    *float64 `json:"value"`
+	UnitCode string   `json:"unitCode"`
+}
+
+// Forecast holds the forecast periods together with when NWS last updated and generated them
+type Forecast struct {
+	UpdateTime  time.Time       `json:"updateTime"`
+	GeneratedAt time.Time       `json:"generatedAt"`
+	Periods     []ForcastPeriod `json:"periods"`
+}
+
+// CurrentForecast is the forecast period covering the current time at a location
+type CurrentForecast struct {
+	Location Location      `json:"location"`
+	Period   ForcastPeriod `json:"period"`
+	Alerts   []Alert       `json:"alerts"`
+	// Observation is what the nearest station with a usable observation measured, nil when none did
+	Observation *Observation `json:"observation"`
+	// Source is the provider of the forecast, e.g. "nws" or "open-meteo"
+	Source string `json:"source"`
+	// Consensus compares the current periods of every provider of the location, when asked for
+	Consensus *Consensus `json:"consensus,omitempty"`
+}
+
+// ForecastPeriods is a list of forecast periods at a location
+type ForecastPeriods struct {
+	Location Location        `json:"location"`
+	Periods  []ForcastPeriod `json:"periods"`
+	Alerts   []Alert         `json:"alerts"`
+	// Source is the provider of the periods, e.g. "nws" or "open-meteo"
+	Source string `json:"source"`
+}
+
+// Consensus is the current period forecast by several providers, with how far apart their forecasts are
+type Consensus struct {
+	Forecasts []SourcePeriod `json:"forecasts"`
+	// TemperatureSpread is the difference between the highest and lowest forecast temperatures
+	TemperatureSpread float64 `json:"temperatureSpread"`
+	// PrecipitationSpread is the difference between the highest and lowest chances of precipitation,
+	// in percent, nil when fewer than two providers gave one
+	PrecipitationSpread *float64 `json:"precipitationSpread"`
+}
+
+// SourcePeriod is the current period forecast by Source, or the error forecasting it
+type SourcePeriod struct {
+	Source string         `json:"source"`
+	Period *ForcastPeriod `json:"period,omitempty"`
+	Error  string         `json:"error,omitempty"`
 }
@@ -0,0 +1,38 @@
+package model
+
+import "time"
+
+// GridValue is the value of a gridpoint layer over the interval starting at StartTime
+type GridValue struct {
+	StartTime time.Time     `json:"startTime"`
+	Duration  time.Duration `json:"duration"`
+	Value     *float64      `json:"value"`
+}
+
+// GridLayer is a raw numerical gridpoint series, e.g. temperature, with its unit
+type GridLayer struct {
+	UnitCode string      `json:"unitCode"`
+	Values   []GridValue `json:"values"`
+}
+
+// GridData holds the raw numerical gridpoint layers NWS forecasts are derived from, by layer name
+type GridData struct {
+	UpdateTime time.Time            `json:"updateTime"`
+	Layers     map[string]GridLayer `json:"layers"`
+}
+
+// GridSeries is the hourly value of a gridpoint layer at each of the GridSeriesData times.
+// Values are nil when NWS has none for an hour.
+type GridSeries struct {
+	Name     string     `json:"name"`
+	UnitCode string     `json:"unitCode"`
+	Values   []*float64 `json:"values"`
+}
+
+// GridSeriesData is a set of gridpoint layers resampled hourly at a location
+type GridSeriesData struct {
+	Location   Location     `json:"location"`
+	UpdateTime time.Time    `json:"updateTime"`
+	Times      []time.Time  `json:"times"`
+	Layers     []GridSeries `json:"layers"`
+}
@@ -1,8 +1,11 @@
 package model
 
 type Location struct {
-	Name      string
-	Latitude  float64
-	Longitude float64
+	Name      string  `json:"name"`
+	Latitude  float64 `json:"latitude"`
+	Longitude float64 `json:"longitude"`
+	// TimeZone is the IANA time zone of the location, e.g. America/Chicago, when known
+	TimeZone string `json:"timeZone,omitempty"`
+	// Seed replays the pick of a random location, see the seed query parameter
+	Seed *int64 `json:"seed,omitempty"`
 }
-
@@ -0,0 +1,22 @@
+package model
+
+import "time"
+
+// Station is an NWS observation station
+type Station struct {
+	ID   string `json:"stationIdentifier"`
+	Name string `json:"name"`
+}
+
+// Observation is the latest measurement of an observation station.
+// The values NWS does not provide have a nil Value.
+type Observation struct {
+	Station            Station           `json:"station"`
+	Timestamp          time.Time         `json:"timestamp"`
+	TextDescription    string            `json:"textDescription"`
+	Temperature        QuantitativeValue `json:"temperature"`
+	RelativeHumidity   QuantitativeValue `json:"relativeHumidity"`
+	WindSpeed          QuantitativeValue `json:"windSpeed"`
+	WindDirection      QuantitativeValue `json:"windDirection"`
+	BarometricPressure QuantitativeValue `json:"barometricPressure"`
+}
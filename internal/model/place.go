@@ -0,0 +1,19 @@
+package model
+
+// Place is a populated place of a gazetteer
+type Place struct {
+	Name       string  `json:"name"`
+	State      string  `json:"state"`
+	Latitude   float64 `json:"latitude"`
+	Longitude  float64 `json:"longitude"`
+	Population int     `json:"population,omitempty"`
+}
+
+// Location returns the location of the place, named after the place and its state
+func (p Place) Location() Location {
+	return Location{
+		Name:      p.Name + ", " + p.State,
+		Latitude:  p.Latitude,
+		Longitude: p.Longitude,
+	}
+}
@@ -0,0 +1,49 @@
+package model
+
+import "math"
+
+// CoordinatePrecision is the number of decimals NWS resolves points to
+const CoordinatePrecision = 4
+
+// RoundCoordinate rounds a latitude or longitude to CoordinatePrecision decimals
+func RoundCoordinate(value float64) float64 {
+	scale := math.Pow10(CoordinatePrecision)
+	rounded := math.Round(value*scale) / scale
+	if rounded == 0 {
+		// drop the sign of -0
+		return 0
+	}
+	return rounded
+}
+
+// PointMetadata is what NWS knows about a point: its forecast grid cell, the URLs of the forecasts
+// and observation stations derived from it, its time zone, radar station and zones
+type PointMetadata struct {
+	GridID              string           `json:"gridId"`
+	GridX               int              `json:"gridX"`
+	GridY               int              `json:"gridY"`
+	Forecast            string           `json:"forecast"`
+	ForecastHourly      string           `json:"forecastHourly"`
+	ForecastGridData    string           `json:"forecastGridData"`
+	ObservationStations string           `json:"observationStations"`
+	RelativeLocation    RelativeLocation `json:"relativeLocation"`
+	ForecastZone        string           `json:"forecastZone"`
+	County              string           `json:"county"`
+	FireWeatherZone     string           `json:"fireWeatherZone"`
+	TimeZone            string           `json:"timeZone"`
+	RadarStation        string           `json:"radarStation"`
+}
+
+// RelativeLocation is the city nearest to a point, with the distance and bearing of the point from it
+type RelativeLocation struct {
+	City     string            `json:"city"`
+	State    string            `json:"state"`
+	Distance QuantitativeValue `json:"distance"`
+	Bearing  QuantitativeValue `json:"bearing"`
+}
+
+// Point is the metadata of the point at a location
+type Point struct {
+	Location Location      `json:"location"`
+	Metadata PointMetadata `json:"metadata"`
+}
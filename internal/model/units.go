@@ -0,0 +1,23 @@
+package model
+
+import "fmt"
+
+// Units is the unit system of the temperatures, wind speeds and precipitation amounts in a response
+type Units string
+
+const (
+	// UnitsUS is the US customary system: °F, mph and inches
+	UnitsUS Units = "us"
+	// UnitsSI is the metric system: °C, km/h and millimeters
+	UnitsSI Units = "si"
+)
+
+// ParseUnits returns the unit system called name
+func ParseUnits(name string) (Units, error) {
+	switch units := Units(name); units {
+	case UnitsUS, UnitsSI:
+		return units, nil
+	default:
+		return "", fmt.Errorf("unknown unit system: %q", name)
+	}
+}
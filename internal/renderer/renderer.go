@@ -0,0 +1,235 @@
+package renderer
+
+import (
+	"embed"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/softstone1/fl/internal/model"
+)
+
+// DefaultLocale is used when the client accepts none of the available locales
+const DefaultLocale = "en"
+
+//go:embed templates/*.tmpl
+var bundledTemplates embed.FS
+
+// Template names, each rendering a text response
+const (
+	// Summary renders the one sentence description of a model.CurrentForecast
+	Summary = "summary"
+	// Current renders a model.CurrentForecast with its observation and alerts
+	Current = "current"
+	// Periods renders a model.ForecastPeriods from the standard forecast
+	Periods = "periods"
+	// Hourly renders a model.ForecastPeriods from the hourly forecast
+	Hourly = "hourly"
+	// LocationAlerts renders a model.LocationAlerts
+	LocationAlerts = "locationAlerts"
+	// Gridpoints renders a model.GridSeriesData as CSV
+	Gridpoints = "gridpoints"
+	// Point renders a model.Point
+	Point = "point"
+)
+
+// Renderer renders the text responses with a set of templates per locale
+type Renderer struct {
+	templates map[string]*template.Template
+}
+
+// New loads the bundled templates, then the *.tmpl files of overridesDir when it is not empty.
+// A file named after a bundled locale, e.g. en.tmpl, redefines some or all of its templates;
+// any other file adds a locale whose missing templates fall back to the default locale.
+func New(overridesDir string) (*Renderer, error) {
+	r := &Renderer{templates: make(map[string]*template.Template)}
+
+	bundled, err := bundledTemplates.ReadDir("templates")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read bundled templates: %w", err)
+	}
+	for _, entry := range bundled {
+		content, err := bundledTemplates.ReadFile("templates/" + entry.Name())
+		if err != nil {
+			return nil, fmt.Errorf("failed to read bundled templates: %w", err)
+		}
+		locale := strings.TrimSuffix(entry.Name(), ".tmpl")
+		tmpl, err := template.New(locale).Funcs(funcs).Parse(string(content))
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse bundled %s templates: %w", locale, err)
+		}
+		r.templates[locale] = tmpl
+	}
+
+	if overridesDir == "" {
+		return r, nil
+	}
+	paths, err := filepath.Glob(filepath.Join(overridesDir, "*.tmpl"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list template overrides: %w", err)
+	}
+	for _, path := range paths {
+		if err := r.override(path); err != nil {
+			return nil, err
+		}
+	}
+	return r, nil
+}
+
+// override parses the template file at path on top of the templates of its locale
+func (r *Renderer) override(path string) error {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read template override: %w", err)
+	}
+	locale := strings.ToLower(strings.TrimSuffix(filepath.Base(path), ".tmpl"))
+	base, ok := r.templates[locale]
+	if !ok {
+		base = r.templates[DefaultLocale]
+	}
+	tmpl, err := base.Clone()
+	if err != nil {
+		return fmt.Errorf("failed to clone %s templates: %w", locale, err)
+	}
+	if _, err := tmpl.Parse(string(content)); err != nil {
+		return fmt.Errorf("failed to parse template override %s: %w", path, err)
+	}
+	r.templates[locale] = tmpl
+	return nil
+}
+
+// Locales returns the available locales
+func (r *Renderer) Locales() []string {
+	locales := make([]string, 0, len(r.templates))
+	for locale := range r.templates {
+		locales = append(locales, locale)
+	}
+	sort.Strings(locales)
+	return locales
+}
+
+// Locale returns the available locale the Accept-Language header value prefers,
+// matching "es-MX" with "es" when there is no "es-mx" locale, and DefaultLocale when none matches
+func (r *Renderer) Locale(acceptLanguage string) string {
+	best, bestQuality := DefaultLocale, 0.0
+	for _, entry := range strings.Split(acceptLanguage, ",") {
+		tag, quality := parseLanguage(entry)
+		if quality <= bestQuality {
+			continue
+		}
+		if _, ok := r.templates[tag]; ok {
+			best, bestQuality = tag, quality
+			continue
+		}
+		if base, _, found := strings.Cut(tag, "-"); found {
+			if _, ok := r.templates[base]; ok {
+				best, bestQuality = base, quality
+			}
+		}
+	}
+	return best
+}
+
+// parseLanguage returns the lower case language tag of an Accept-Language entry and its quality
+func parseLanguage(entry string) (string, float64) {
+	tag, params, _ := strings.Cut(entry, ";")
+	quality := 1.0
+	if q, found := strings.CutPrefix(strings.TrimSpace(params), "q="); found {
+		var err error
+		if quality, err = strconv.ParseFloat(q, 64); err != nil {
+			return "", 0
+		}
+	}
+	return strings.ToLower(strings.TrimSpace(tag)), quality
+}
+
+// Render writes the template called name of locale, or of DefaultLocale when locale is not available
+func (r *Renderer) Render(w io.Writer, locale, name string, data any) error {
+	tmpl, ok := r.templates[locale]
+	if !ok {
+		tmpl = r.templates[DefaultLocale]
+	}
+	if err := tmpl.ExecuteTemplate(w, name, data); err != nil {
+		return fmt.Errorf("failed to render %s: %w", name, err)
+	}
+	return nil
+}
+
+// funcs are the functions available to the templates
+var funcs = template.FuncMap{
+	"rfc3339":  func(t time.Time) string { return t.Format(time.RFC3339) },
+	"quantity": formatQuantity,
+	"number":   formatNumber,
+	"unit":     unitSymbol,
+	"label":    label,
+	"join":     join,
+}
+
+// quantityUnits maps the NWS unit codes to their symbols
+var quantityUnits = map[string]string{
+	"wmoUnit:degC":           "°C",
+	"wmoUnit:degF":           "°F",
+	"wmoUnit:percent":        "%",
+	"wmoUnit:km_h-1":         " km/h",
+	"wmoUnit:mi_h-1":         " mph",
+	"wmoUnit:m_s-1":          " m/s",
+	"wmoUnit:degree_(angle)": "°",
+	"wmoUnit:Pa":             " Pa",
+	"wmoUnit:mm":             " mm",
+	"wmoUnit:in":             " in",
+}
+
+// formatQuantity formats the value with one decimal at most followed by its unit, empty when it has no value
+func formatQuantity(q model.QuantitativeValue) string {
+	if q.Value == nil {
+		return ""
+	}
+	value := strconv.FormatFloat(math.Round(*q.Value*10)/10, 'f', -1, 64)
+	unit, ok := quantityUnits[q.UnitCode]
+	if !ok {
+		unit = " " + unitSymbol(q.UnitCode)
+	}
+	return value + unit
+}
+
+// formatNumber formats the value with two decimals at most, empty when it is nil
+func formatNumber(value *float64) string {
+	if value == nil {
+		return ""
+	}
+	return strconv.FormatFloat(math.Round(*value*100)/100, 'f', -1, 64)
+}
+
+// unitSymbol returns the symbol of the NWS unit code, e.g. km/h for wmoUnit:km_h-1
+func unitSymbol(unitCode string) string {
+	if unit, ok := quantityUnits[unitCode]; ok {
+		return strings.TrimSpace(unit)
+	}
+	return strings.TrimPrefix(unitCode, "wmoUnit:")
+}
+
+// label prefixes value with name, empty when value is
+func label(name, value string) string {
+	if value == "" {
+		return ""
+	}
+	return name + " " + value
+}
+
+// join joins the non empty values with sep
+func join(sep string, values ...string) string {
+	nonEmpty := make([]string, 0, len(values))
+	for _, value := range values {
+		if value != "" {
+			nonEmpty = append(nonEmpty, value)
+		}
+	}
+	return strings.Join(nonEmpty, sep)
+}
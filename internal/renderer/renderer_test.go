@@ -0,0 +1,75 @@
+package renderer
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/softstone1/fl/internal/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLocale(t *testing.T) {
+	r, err := New("")
+	require.NoError(t, err)
+
+	tests := []struct {
+		acceptLanguage string
+		expected       string
+	}{
+		{acceptLanguage: "", expected: "en"},
+		{acceptLanguage: "es", expected: "es"},
+		{acceptLanguage: "es-MX", expected: "es"},
+		{acceptLanguage: "fr, es;q=0.5", expected: "es"},
+		{acceptLanguage: "es;q=0.4, en;q=0.9", expected: "en"},
+		{acceptLanguage: "de, *;q=0.1", expected: "en"},
+		{acceptLanguage: "es;q=invalid", expected: "en"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.acceptLanguage, func(t *testing.T) {
+			assert.Equal(t, tc.expected, r.Locale(tc.acceptLanguage))
+		})
+	}
+}
+
+func TestOverrides(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "en.tmpl"),
+		[]byte(`{{define "summary"}}{{.Location.Name}}: {{.Period.ShortForecast}}{{end}}`), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "fr.tmpl"),
+		[]byte(`{{define "summary"}}Le temps à {{.Location.Name}} : {{.Period.DetailedForecast}}{{end}}`), 0o600))
+
+	r, err := New(dir)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"en", "es", "fr"}, r.Locales())
+
+	forecast := &model.CurrentForecast{
+		Location: model.Location{Name: "Paris"},
+		Period:   model.ForcastPeriod{ShortForecast: "Sunny", DetailedForecast: "Sunny all day"},
+		Alerts:   []model.Alert{{Event: "Heat Advisory", Severity: "Moderate", Urgency: "Expected"}},
+	}
+	render := func(locale, name string) string {
+		var b strings.Builder
+		require.NoError(t, r.Render(&b, locale, name, forecast))
+		return b.String()
+	}
+
+	assert.Equal(t, "Paris: Sunny", render("en", Summary))
+	// the overridden summary is used by the bundled templates including it
+	assert.Equal(t, "Paris: Sunny\nAlert: Heat Advisory (severity Moderate, urgency Expected)\n", render("en", Current))
+	// the added locale falls back to the default locale for the templates it does not define
+	assert.Equal(t, "Le temps à Paris : Sunny all day\nAlert: Heat Advisory (severity Moderate, urgency Expected)\n", render("fr", Current))
+	assert.Equal(t, "El tiempo en Paris es: Sunny all day", render("es", Summary))
+}
+
+func TestOverrides_InvalidTemplate(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "en.tmpl"), []byte(`{{define "summary"}}{{.Location.Name}`), 0o600))
+
+	_, err := New(dir)
+
+	assert.Error(t, err)
+}
@@ -3,16 +3,24 @@ package server
 import (
 	"github.com/go-chi/chi/v5"
 	"github.com/softstone1/fl/internal/handler"
+	"github.com/softstone1/fl/internal/renderer"
 	"github.com/softstone1/fl/internal/service"
 )
 
 // NewRouter constructs the main router for your app.
-func NewRouter(forcastService service.Forecast) *chi.Mux {
+func NewRouter(forcastService service.Forecast, renderer *renderer.Renderer) *chi.Mux {
 	r := chi.NewRouter()
 	r.Route("/", func(r chi.Router) {
 		// forcast handler
-		forcastHandler := handler.NewForecast(forcastService)
+		forcastHandler := handler.NewForecast(forcastService, renderer)
 		r.Get("/", forcastHandler.GetRandomForecast)
+		r.Get("/forecast", forcastHandler.GetForecast)
+		r.Post("/forecast/batch", forcastHandler.GetForecastBatch)
+		r.Get("/forecast/periods", forcastHandler.GetForecastPeriods)
+		r.Get("/forecast/hourly", forcastHandler.GetHourlyForecast)
+		r.Get("/forecast/gridpoints", forcastHandler.GetGridpoints)
+		r.Get("/alerts", forcastHandler.GetAlerts)
+		r.Get("/points/{coordinates}", forcastHandler.GetPoint)
 	})
 
 	return r
@@ -2,6 +2,7 @@ package server
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
 	"net/http"
@@ -15,7 +16,8 @@ import (
 
 // Server encapsulates the HTTP server and its dependencies
 type Server struct {
-	srv *http.Server
+	srv           *http.Server
+	shutdownHooks []func(ctx context.Context)
 }
 
 // NewServer initializes a new Server with the provided configuration
@@ -30,9 +32,16 @@ func NewServer(cfg *config.Config, router http.Handler) *Server {
 	}
 }
 
+// OnShutdown registers a hook that runs once the server stopped serving requests during graceful shutdown
+func (s *Server) OnShutdown(hook func(ctx context.Context)) {
+	s.shutdownHooks = append(s.shutdownHooks, hook)
+}
+
 // Run starts the HTTP server
 func (s *Server) Run() error {
+	done := make(chan struct{})
 	go func() {
+		defer close(done)
 		sigCh := make(chan os.Signal, 1)
 		signal.Notify(sigCh, syscall.SIGINT, syscall.SIGTERM)
 		<-sigCh
@@ -44,8 +53,17 @@ func (s *Server) Run() error {
 		if err := s.srv.Shutdown(ctx); err != nil {
 			log.Printf("Shutdown error: %v", err)
 		}
+
+		for _, hook := range s.shutdownHooks {
+			hook(ctx)
+		}
 	}()
 
 	// This blocks until an error occurs, or srv.Shutdown() is called
-	return s.srv.ListenAndServe()
+	err := s.srv.ListenAndServe()
+	if errors.Is(err, http.ErrServerClosed) {
+		// wait for the shutdown hooks to complete before returning
+		<-done
+	}
+	return err
 }
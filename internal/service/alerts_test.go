@@ -0,0 +1,88 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/softstone1/fl/internal/client"
+	"github.com/softstone1/fl/internal/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestGetAlerts_Cached(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockAlerts := client.NewMockAlerts(ctrl)
+	mockForecast := client.NewMockForecast(ctrl)
+	svc, _ := NewForecast(nil, mockForecast, time.Second, 10, WithAlerts(mockAlerts, time.Minute))
+
+	expires := time.Date(2025, 1, 2, 12, 0, 0, 0, time.UTC)
+	warning := model.Alert{Event: "Winter Storm Warning", Severity: "Severe", Urgency: "Expected", Expires: expires}
+	mockAlerts.EXPECT().GetActiveAlerts(gomock.Any(), 39.7456, -97.0892).Return([]model.Alert{warning}, nil)
+	mockForecast.EXPECT().GetPointMetadata(gomock.Any(), 39.7456, -97.0892).Return(
+		&model.PointMetadata{TimeZone: "America/Chicago"}, nil,
+	)
+
+	location := &model.Location{Latitude: 39.7456, Longitude: -97.0892}
+	for i := 0; i < 2; i++ {
+		resp, err := svc.GetAlerts(context.Background(), AlertsQuery{Location: location})
+		require.NoError(t, err)
+		require.Len(t, resp.Alerts, 1)
+		assert.Equal(t, "America/Chicago", resp.Location.TimeZone)
+		// the alert times are in the location time zone
+		assert.True(t, expires.Equal(resp.Alerts[0].Expires))
+		assert.Equal(t, "2025-01-02T06:00:00-06:00", resp.Alerts[0].Expires.Format(time.RFC3339))
+		assert.True(t, resp.Alerts[0].Onset.IsZero())
+	}
+}
+
+func TestGetAlerts_Disabled(t *testing.T) {
+	svc, _ := NewForecast(nil, nil, time.Second, 10)
+
+	_, err := svc.GetAlerts(context.Background(), AlertsQuery{Location: &model.Location{}})
+
+	assert.ErrorIs(t, err, ErrAlertsDisabled)
+}
+
+func TestGetRandomForecast_IncludesAlerts(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockLocation := client.NewMockLocation(ctrl)
+	mockForecast := client.NewMockForecast(ctrl)
+	mockAlerts := client.NewMockAlerts(ctrl)
+	svc, _ := NewForecast(mockLocation, mockForecast, time.Second, 10, WithAlerts(mockAlerts, time.Minute))
+
+	mockLocation.EXPECT().GetRandomLocation(gomock.Any()).Return(
+		&model.Location{Name: "Test Location", Latitude: 1.0, Longitude: 1.0}, nil,
+	).Times(2)
+	mockForecast.EXPECT().GetPointMetadata(gomock.Any(), gomock.Any(), gomock.Any()).Return(
+		&model.PointMetadata{Forecast: "http://test.url"}, nil,
+	)
+	mockForecast.EXPECT().GetForecastPeriods(gomock.Any(), gomock.Any()).Return(
+		&model.Forecast{
+			Periods: []model.ForcastPeriod{
+				{StartTime: time.Now().Add(-time.Hour), EndTime: time.Now().Add(time.Hour), DetailedForecast: "Snow"},
+			},
+		}, nil,
+	)
+	warning := model.Alert{Event: "Winter Storm Warning"}
+	gomock.InOrder(
+		mockAlerts.EXPECT().GetActiveAlerts(gomock.Any(), 1.0, 1.0).Return(nil, errors.New("alerts error")),
+		mockAlerts.EXPECT().GetActiveAlerts(gomock.Any(), 1.0, 1.0).Return([]model.Alert{warning}, nil),
+	)
+
+	// failing to get the alerts does not fail the forecast
+	resp, err := svc.GetRandomForecast(context.Background(), CurrentQuery{})
+	assert.NoError(t, err)
+	assert.Empty(t, resp.Alerts)
+
+	resp, err = svc.GetRandomForecast(context.Background(), CurrentQuery{})
+	assert.NoError(t, err)
+	assert.Equal(t, []model.Alert{warning}, resp.Alerts)
+}
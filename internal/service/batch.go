@@ -0,0 +1,96 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/softstone1/fl/internal/model"
+)
+
+// ErrEmptyBatch is returned by GetForecastBatch when the query has no items
+var ErrEmptyBatch = errors.New("empty batch")
+
+// ErrBatchTooLarge is returned by GetForecastBatch when the query has more items than the configured maximum
+var ErrBatchTooLarge = errors.New("batch too large")
+
+// BatchItem is a location of a batch, given by coordinates or by place name
+type BatchItem struct {
+	Location *model.Location
+	Place    string
+}
+
+// BatchQuery selects the locations and how GetForecastBatch returns their current forecasts
+type BatchQuery struct {
+	Items []BatchItem
+	// Units of the forecasts, the service default when empty
+	Units model.Units
+	// TimeZone of the returned times, the service default or else the location time zone when nil
+	TimeZone *time.Location
+}
+
+// BatchResult is the current forecast of the batch item at Index, or the error getting it
+type BatchResult struct {
+	Index    int
+	Forecast *model.CurrentForecast
+	Err      error
+}
+
+// WithBatchLimits sets the maximum number of items of a batch, how many of them are forecast concurrently
+// and the deadline of the whole batch
+func WithBatchLimits(maxSize, concurrency int, timeout time.Duration) Option {
+	return func(o *options) {
+		o.batchMaxSize = maxSize
+		o.batchConcurrency = concurrency
+		o.batchTimeout = timeout
+	}
+}
+
+// GetForecastBatch gets the current forecast of every query item, at most batchConcurrency at a time,
+// and calls emit with each result as it completes, one call at a time. The items not forecast before
+// the batch deadline are emitted with the deadline error.
+func (s *forecast) GetForecastBatch(ctx context.Context, query BatchQuery, emit func(BatchResult)) error {
+	if len(query.Items) == 0 {
+		return ErrEmptyBatch
+	}
+	if len(query.Items) > s.batchMaxSize {
+		return fmt.Errorf("%w: %d items, at most %d", ErrBatchTooLarge, len(query.Items), s.batchMaxSize)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, s.batchTimeout)
+	defer cancel()
+
+	var emitMu sync.Mutex
+	result := func(r BatchResult) {
+		emitMu.Lock()
+		defer emitMu.Unlock()
+		emit(r)
+	}
+
+	var wg sync.WaitGroup
+	slots := make(chan struct{}, s.batchConcurrency)
+	for i, item := range query.Items {
+		select {
+		case slots <- struct{}{}:
+		case <-ctx.Done():
+			result(BatchResult{Index: i, Err: ctx.Err()})
+			continue
+		}
+		wg.Add(1)
+		go func(i int, item BatchItem) {
+			defer wg.Done()
+			defer func() { <-slots }()
+			forecast, err := s.GetRandomForecast(ctx, CurrentQuery{
+				Location: item.Location,
+				Place:    item.Place,
+				Units:    query.Units,
+				TimeZone: query.TimeZone,
+			})
+			result(BatchResult{Index: i, Forecast: forecast, Err: err})
+		}(i, item)
+	}
+	wg.Wait()
+	return nil
+}
@@ -0,0 +1,133 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/softstone1/fl/internal/client"
+	"github.com/softstone1/fl/internal/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestGetForecastBatch_ResultsAndErrors(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockForecast := client.NewMockForecast(ctrl)
+	mockGeocoder := client.NewMockGeocoder(ctrl)
+	svc, _ := NewForecast(nil, mockForecast, time.Second, 10, WithGeocoder(mockGeocoder), WithBatchLimits(10, 2, time.Second))
+
+	// the two points share a grid cell, whose forecast is fetched once through the cache
+	mockForecast.EXPECT().GetPointMetadata(gomock.Any(), gomock.Any(), gomock.Any()).Return(
+		&model.PointMetadata{GridID: "TOP", GridX: 32, GridY: 81, Forecast: "http://test.url"}, nil,
+	).Times(2)
+	mockForecast.EXPECT().GetForecastPeriods(gomock.Any(), "http://test.url").Return(
+		&model.Forecast{Periods: []model.ForcastPeriod{
+			{StartTime: time.Now().Add(-time.Hour), EndTime: time.Now().Add(time.Hour), DetailedForecast: "Sunny"},
+		}}, nil,
+	)
+	mockGeocoder.EXPECT().Geocode(gomock.Any(), "Gotham").Return(nil, nil)
+
+	query := BatchQuery{Items: []BatchItem{
+		{Location: &model.Location{Name: "Linn", Latitude: 39.7456, Longitude: -97.0892}},
+		{Place: "Gotham"},
+		{Location: &model.Location{Name: "Linn North", Latitude: 39.7457, Longitude: -97.0892}},
+	}}
+	var results []BatchResult
+	err := svc.GetForecastBatch(context.Background(), query, func(result BatchResult) {
+		results = append(results, result)
+	})
+
+	require.NoError(t, err)
+	require.Len(t, results, 3)
+	sort.Slice(results, func(i, j int) bool { return results[i].Index < results[j].Index })
+	assert.Equal(t, "Sunny", results[0].Forecast.Period.DetailedForecast)
+	assert.Equal(t, "Linn", results[0].Forecast.Location.Name)
+	assert.ErrorIs(t, results[1].Err, ErrPlaceNotFound)
+	assert.Equal(t, "Linn North", results[2].Forecast.Location.Name)
+}
+
+func TestGetForecastBatch_BoundedConcurrency(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockForecast := client.NewMockForecast(ctrl)
+	svc, _ := NewForecast(nil, mockForecast, time.Second, 10, WithBatchLimits(10, 2, time.Second))
+
+	var running, maxRunning int32
+	mockForecast.EXPECT().GetPointMetadata(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ context.Context, _, _ float64) (*model.PointMetadata, error) {
+			current := atomic.AddInt32(&running, 1)
+			defer atomic.AddInt32(&running, -1)
+			for {
+				seen := atomic.LoadInt32(&maxRunning)
+				if current <= seen || atomic.CompareAndSwapInt32(&maxRunning, seen, current) {
+					break
+				}
+			}
+			time.Sleep(5 * time.Millisecond)
+			return nil, errors.New("points error")
+		},
+	).Times(6)
+
+	items := make([]BatchItem, 6)
+	for i := range items {
+		items[i] = BatchItem{Location: &model.Location{Latitude: float64(i), Longitude: -97}}
+	}
+	emitted := 0
+	err := svc.GetForecastBatch(context.Background(), BatchQuery{Items: items}, func(result BatchResult) {
+		emitted++
+		assert.Error(t, result.Err)
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, 6, emitted)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&maxRunning))
+}
+
+func TestGetForecastBatch_Deadline(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockForecast := client.NewMockForecast(ctrl)
+	svc, _ := NewForecast(nil, mockForecast, time.Second, 10, WithBatchLimits(10, 1, 20*time.Millisecond))
+
+	// the first item outlives the batch deadline, the second one is never started
+	mockForecast.EXPECT().GetPointMetadata(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(
+		func(ctx context.Context, _, _ float64) (*model.PointMetadata, error) {
+			<-ctx.Done()
+			return nil, ctx.Err()
+		},
+	)
+
+	items := []BatchItem{
+		{Location: &model.Location{Latitude: 1, Longitude: 1}},
+		{Location: &model.Location{Latitude: 2, Longitude: 2}},
+	}
+	results := map[int]error{}
+	err := svc.GetForecastBatch(context.Background(), BatchQuery{Items: items}, func(result BatchResult) {
+		results[result.Index] = result.Err
+	})
+
+	require.NoError(t, err)
+	require.Len(t, results, 2)
+	assert.ErrorIs(t, results[0], context.DeadlineExceeded)
+	assert.ErrorIs(t, results[1], context.DeadlineExceeded)
+}
+
+func TestGetForecastBatch_Size(t *testing.T) {
+	svc, _ := NewForecast(nil, nil, time.Second, 10, WithBatchLimits(1, 1, time.Second))
+	emit := func(BatchResult) { t.Error("unexpected result") }
+
+	err := svc.GetForecastBatch(context.Background(), BatchQuery{}, emit)
+	assert.ErrorIs(t, err, ErrEmptyBatch)
+
+	err = svc.GetForecastBatch(context.Background(), BatchQuery{Items: make([]BatchItem, 2)}, emit)
+	assert.ErrorIs(t, err, ErrBatchTooLarge)
+}
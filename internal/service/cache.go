@@ -0,0 +1,146 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	lru "github.com/hashicorp/golang-lru/v2"
+)
+
+// Cache stores values by key. A zero ttl means the entry never expires.
+type Cache[V any] interface {
+	Get(ctx context.Context, key string) (V, bool, error)
+	Set(ctx context.Context, key string, value V, ttl time.Duration) error
+}
+
+// CacheEntry is a single cached value with its expiry, zero when it never expires
+type CacheEntry[V any] struct {
+	Key       string    `json:"key"`
+	Value     V         `json:"value"`
+	ExpiresAt time.Time `json:"expiresAt"`
+}
+
+// EnumerableCache is implemented by caches whose unexpired entries can be listed
+type EnumerableCache[V any] interface {
+	Entries() []CacheEntry[V]
+}
+
+// lruItem is the value stored in the in-process LRU
+type lruItem[V any] struct {
+	value     V
+	expiresAt time.Time
+}
+
+// lruCache is the in-process Cache backed by hashicorp/golang-lru
+type lruCache[V any] struct {
+	cache *lru.Cache[string, lruItem[V]]
+	now   func() time.Time
+}
+
+// make sure lruCache implements the Cache and EnumerableCache interfaces
+var _ Cache[string] = (*lruCache[string])(nil)
+var _ EnumerableCache[string] = (*lruCache[string])(nil)
+
+// NewLRUCache creates an in-process LRU cache holding up to size entries, expiring them by the now clock
+func NewLRUCache[V any](size int, now func() time.Time) (*lruCache[V], error) {
+	cache, err := lru.New[string, lruItem[V]](size)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create lru cache: %w", err)
+	}
+	return &lruCache[V]{cache: cache, now: now}, nil
+}
+
+// Get returns the cached value unless it is missing or expired
+func (c *lruCache[V]) Get(_ context.Context, key string) (V, bool, error) {
+	var zero V
+	item, found := c.cache.Get(key)
+	if !found {
+		return zero, false, nil
+	}
+	if !item.expiresAt.IsZero() && !c.now().Before(item.expiresAt) {
+		c.cache.Remove(key)
+		return zero, false, nil
+	}
+	return item.value, true, nil
+}
+
+// Set stores the value, evicting the least recently used entry when full
+func (c *lruCache[V]) Set(_ context.Context, key string, value V, ttl time.Duration) error {
+	item := lruItem[V]{value: value}
+	if ttl > 0 {
+		item.expiresAt = c.now().Add(ttl)
+	}
+	c.cache.Add(key, item)
+	return nil
+}
+
+// Entries lists the unexpired entries from least to most recently used
+func (c *lruCache[V]) Entries() []CacheEntry[V] {
+	current := c.now()
+	var entries []CacheEntry[V]
+	for _, key := range c.cache.Keys() {
+		item, found := c.cache.Peek(key)
+		if !found || (!item.expiresAt.IsZero() && !current.Before(item.expiresAt)) {
+			continue
+		}
+		entries = append(entries, CacheEntry[V]{Key: key, Value: item.value, ExpiresAt: item.expiresAt})
+	}
+	return entries
+}
+
+// tieredCache keeps a short-lived local copy in front of a shared cache
+type tieredCache[V any] struct {
+	local    Cache[V]
+	shared   Cache[V]
+	localTTL time.Duration
+}
+
+// make sure tieredCache implements the Cache and EnumerableCache interfaces
+var _ Cache[string] = (*tieredCache[string])(nil)
+var _ EnumerableCache[string] = (*tieredCache[string])(nil)
+
+// NewTieredCache puts local in front of shared. Local entries live at most localTTL
+// so that updates written by other replicas are picked up.
+func NewTieredCache[V any](local, shared Cache[V], localTTL time.Duration) *tieredCache[V] {
+	return &tieredCache[V]{
+		local:    local,
+		shared:   shared,
+		localTTL: localTTL,
+	}
+}
+
+// Get reads the local tier first and fills it from the shared tier on a miss
+func (c *tieredCache[V]) Get(ctx context.Context, key string) (V, bool, error) {
+	if value, found, err := c.local.Get(ctx, key); err == nil && found {
+		return value, true, nil
+	}
+	value, found, err := c.shared.Get(ctx, key)
+	if err != nil || !found {
+		return value, found, err
+	}
+	if err := c.local.Set(ctx, key, value, c.localTTL); err != nil {
+		return value, true, err
+	}
+	return value, true, nil
+}
+
+// Set writes to both tiers
+func (c *tieredCache[V]) Set(ctx context.Context, key string, value V, ttl time.Duration) error {
+	localTTL := c.localTTL
+	if ttl > 0 && ttl < localTTL {
+		localTTL = ttl
+	}
+	if err := c.local.Set(ctx, key, value, localTTL); err != nil {
+		return err
+	}
+	return c.shared.Set(ctx, key, value, ttl)
+}
+
+// Entries lists the entries of the local tier
+func (c *tieredCache[V]) Entries() []CacheEntry[V] {
+	if local, ok := c.local.(EnumerableCache[V]); ok {
+		return local.Entries()
+	}
+	return nil
+}
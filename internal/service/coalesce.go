@@ -0,0 +1,70 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// call is an in-flight or completed callGroup call
+type call[V any] struct {
+	done  chan struct{}
+	value V
+	err   error
+}
+
+// callGroup coalesces concurrent calls with the same key into a single execution.
+// The execution is detached from the callers, so that one of them going away does not fail the others,
+// and bounded by timeout when it is positive.
+type callGroup[V any] struct {
+	mu      sync.Mutex
+	calls   map[string]*call[V]
+	timeout time.Duration
+}
+
+// Do executes fn unless a call for key is already in flight, in which case it joins that call,
+// and returns its result. fn runs on a context keeping the values of ctx but not its cancellation,
+// while every caller stops waiting when its own ctx is done. A panic in fn is returned as an error
+// to every caller.
+func (g *callGroup[V]) Do(ctx context.Context, key string, fn func(ctx context.Context) (V, error)) (V, error) {
+	g.mu.Lock()
+	if g.calls == nil {
+		g.calls = make(map[string]*call[V])
+	}
+	c, ok := g.calls[key]
+	if !ok {
+		c = &call[V]{done: make(chan struct{})}
+		g.calls[key] = c
+		go g.run(context.WithoutCancel(ctx), key, c, fn)
+	}
+	g.mu.Unlock()
+
+	select {
+	case <-c.done:
+		return c.value, c.err
+	case <-ctx.Done():
+		var zero V
+		return zero, ctx.Err()
+	}
+}
+
+// run executes the call of key and releases its callers
+func (g *callGroup[V]) run(ctx context.Context, key string, c *call[V], fn func(ctx context.Context) (V, error)) {
+	defer func() {
+		if r := recover(); r != nil {
+			c.err = fmt.Errorf("call %s panicked: %v", key, r)
+		}
+		g.mu.Lock()
+		delete(g.calls, key)
+		g.mu.Unlock()
+		close(c.done)
+	}()
+
+	if g.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, g.timeout)
+		defer cancel()
+	}
+	c.value, c.err = fn(ctx)
+}
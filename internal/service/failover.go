@@ -0,0 +1,362 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/softstone1/fl/internal/client"
+	"github.com/softstone1/fl/internal/model"
+)
+
+// ErrConsensusUnavailable is returned for a consensus query when a single forecast provider is configured
+var ErrConsensusUnavailable = errors.New("consensus needs more than one forecast provider")
+
+// FailoverRule lists the providers forecasting the locations of an area, in the order they are tried
+type FailoverRule struct {
+	// NWSCoverage restricts the rule to the locations NWS covers
+	NWSCoverage bool
+	// Box restricts the rule to the locations inside it
+	Box *client.BoundingBox
+	// Providers are tried in order until one answers
+	Providers []ProviderSelection
+}
+
+// FailoverConfiguration holds the settings of the provider failover
+type FailoverConfiguration struct {
+	// Rules are matched in order, the first rule whose area has the location choosing its providers.
+	// The rules of the provider selection are used when empty.
+	Rules []FailoverRule
+	// AttemptTimeout bounds the time a provider has to answer before the next one is tried
+	AttemptTimeout time.Duration
+	// FailureThreshold is the number of consecutive failures after which a provider is skipped for Cooldown
+	FailureThreshold int
+	Cooldown         time.Duration
+}
+
+// WithFailover tries the next provider of the location when one fails or does not answer in time,
+// and skips the providers failing repeatedly
+func WithFailover(cfg FailoverConfiguration) Option {
+	return func(o *options) {
+		o.failover = cfg
+	}
+}
+
+// ParseFailoverRules parses rules separated by semicolons. A rule is an area, "us" for the NWS coverage,
+// "*" for every location or a south,west,north,east bounding box, then "=" and its providers separated
+// by commas, e.g. "us=nws,open-meteo;*=open-meteo".
+func ParseFailoverRules(spec string) ([]FailoverRule, error) {
+	var rules []FailoverRule
+	for _, ruleSpec := range strings.Split(spec, ";") {
+		if strings.TrimSpace(ruleSpec) == "" {
+			continue
+		}
+		area, providers, found := strings.Cut(ruleSpec, "=")
+		if !found {
+			return nil, fmt.Errorf("invalid failover rule %q: expected area=providers", strings.TrimSpace(ruleSpec))
+		}
+
+		rule := FailoverRule{}
+		switch area = strings.TrimSpace(area); area {
+		case "*":
+		case "us":
+			rule.NWSCoverage = true
+		default:
+			box, err := client.ParseBoundingBox(area)
+			if err != nil {
+				return nil, fmt.Errorf("invalid failover rule area: %w", err)
+			}
+			rule.Box = &box
+		}
+		for _, name := range strings.Split(providers, ",") {
+			provider, err := ParseProviderSelection(strings.TrimSpace(name))
+			if err != nil || provider == ProviderAuto {
+				return nil, fmt.Errorf("unknown forecast provider: %q", strings.TrimSpace(name))
+			}
+			rule.Providers = append(rule.Providers, provider)
+		}
+		rules = append(rules, rule)
+	}
+	return rules, nil
+}
+
+// selectionRules returns the failover rules of a provider selection. The auto selection falls back
+// to Open-Meteo when NWS fails.
+func selectionRules(selection ProviderSelection) []FailoverRule {
+	switch selection {
+	case ProviderOpenMeteo:
+		return []FailoverRule{{Providers: []ProviderSelection{ProviderOpenMeteo}}}
+	case ProviderAuto:
+		return []FailoverRule{
+			{NWSCoverage: true, Providers: []ProviderSelection{ProviderNWS, ProviderOpenMeteo}},
+			{Providers: []ProviderSelection{ProviderOpenMeteo}},
+		}
+	default:
+		return []FailoverRule{{Providers: []ProviderSelection{ProviderNWS}}}
+	}
+}
+
+// UsesProvider reports whether the rules, or the ones of the selection when there are none, try the provider
+func UsesProvider(selection ProviderSelection, rules []FailoverRule, provider ProviderSelection) bool {
+	if len(rules) == 0 {
+		rules = selectionRules(selection)
+	}
+	for _, rule := range rules {
+		for _, name := range rule.Providers {
+			if name == provider {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// matches reports whether the location is in the rule area
+func (r FailoverRule) matches(location model.Location) bool {
+	if r.NWSCoverage && !nwsCovers(location) {
+		return false
+	}
+	return r.Box == nil || r.Box.Contains(location.Latitude, location.Longitude)
+}
+
+// ruleProviders returns the providers of the first rule matching the location, NWS when none does
+func (s *forecast) ruleProviders(location model.Location) []ProviderSelection {
+	for _, rule := range s.failoverRules {
+		if rule.matches(location) {
+			return rule.Providers
+		}
+	}
+	return []ProviderSelection{ProviderNWS}
+}
+
+// nwsOnly reports whether NWS is the only provider of the location, which then needs to be in its coverage
+func (s *forecast) nwsOnly(location model.Location) bool {
+	for _, name := range s.ruleProviders(location) {
+		if name != ProviderNWS {
+			return false
+		}
+	}
+	return true
+}
+
+// providersFor returns the providers of the first rule matching the location, NWS when none does.
+// The providers skipped for failing come last, in case all the others fail too.
+func (s *forecast) providersFor(location model.Location) []forecastProvider {
+	var healthy, skipped []forecastProvider
+	for _, name := range s.ruleProviders(location) {
+		if s.health.healthy(name) {
+			healthy = append(healthy, s.providers[name])
+		} else {
+			skipped = append(skipped, s.providers[name])
+		}
+	}
+	return append(healthy, skipped...)
+}
+
+// failoverForecast returns the forecast of kind of the first provider of the location answering,
+// trying the next one when a provider fails, does not answer within the attempt timeout, or answers
+// a standard forecast without a current period
+func (s *forecast) failoverForecast(ctx context.Context, location model.Location, kind forecastKind, units model.Units) (providerForecast, error) {
+	return s.failover(ctx, location, kind, func(ctx context.Context, provider forecastProvider) (providerForecast, error) {
+		return provider.forecast(ctx, location, kind, units)
+	})
+}
+
+// failover returns the forecast of kind attempt gets from the first provider of the location answering,
+// in the order and within the attempt timeout of failoverForecast
+func (s *forecast) failover(ctx context.Context, location model.Location, kind forecastKind, attempt func(ctx context.Context, provider forecastProvider) (providerForecast, error)) (providerForecast, error) {
+	providers := s.providersFor(location)
+	var errs []error
+	for i, provider := range providers {
+		last := i == len(providers)-1
+		attemptCtx, cancel := ctx, context.CancelFunc(func() {})
+		if !last && s.attemptTimeout > 0 {
+			attemptCtx, cancel = context.WithTimeout(ctx, s.attemptTimeout)
+		}
+		result, err := attempt(attemptCtx, provider)
+		cancel()
+		if err == nil && kind == standardForecast {
+			if _, ok := findCurrentPeriod(result.Forecast.Periods, s.now(), s.periodTolerance); !ok {
+				err = fmt.Errorf("Stage 3 - GetForecastResponse error: %w", errNoCurrentForecast)
+			}
+		}
+		if ctx.Err() == nil {
+			s.health.record(provider.name(), err)
+		}
+		if err == nil {
+			return result, nil
+		}
+		errs = append(errs, err)
+		if ctx.Err() != nil {
+			break
+		}
+		if !last && !errors.Is(err, errOutsideNWSCoverage) {
+			log.Printf("Forecast provider %s failed, trying %s: %v", provider.name(), providers[i+1].name(), err)
+		}
+	}
+	if len(errs) == 1 {
+		return providerForecast{}, errs[0]
+	}
+	return providerForecast{}, errors.Join(errs...)
+}
+
+// consensusProviders returns the providers of the location, in the failover order, followed by the other
+// configured providers
+func (s *forecast) consensusProviders(location model.Location) []forecastProvider {
+	providers := s.providersFor(location)
+	listed := make(map[ProviderSelection]bool, len(providers))
+	for _, provider := range providers {
+		listed[provider.name()] = true
+	}
+	for _, name := range []ProviderSelection{ProviderNWS, ProviderOpenMeteo} {
+		if provider, ok := s.providers[name]; ok && !listed[name] {
+			providers = append(providers, provider)
+		}
+	}
+	return providers
+}
+
+// consensusForecast forecasts the current period with every configured provider in parallel, each within
+// the attempt timeout. It returns the forecast of the first provider with a current period, in the failover
+// order of the location, and the consensus of all of them, their periods in units.
+func (s *forecast) consensusForecast(ctx context.Context, location model.Location, units model.Units) (providerForecast, *model.Consensus, error) {
+	providers := s.consensusProviders(location)
+	results := make([]providerForecast, len(providers))
+	errs := make([]error, len(providers))
+	var wg sync.WaitGroup
+	for i, provider := range providers {
+		wg.Add(1)
+		go func(i int, provider forecastProvider) {
+			defer wg.Done()
+			attemptCtx, cancel := ctx, context.CancelFunc(func() {})
+			if s.attemptTimeout > 0 {
+				attemptCtx, cancel = context.WithTimeout(ctx, s.attemptTimeout)
+			}
+			defer cancel()
+			results[i], errs[i] = provider.forecast(attemptCtx, location, standardForecast, units)
+			if ctx.Err() == nil {
+				s.health.record(provider.name(), errs[i])
+			}
+		}(i, provider)
+	}
+	wg.Wait()
+
+	consensus := &model.Consensus{}
+	primary := -1
+	for i, provider := range providers {
+		forecast := model.SourcePeriod{Source: string(provider.name())}
+		if errs[i] == nil {
+			if period, ok := findCurrentPeriod(results[i].Forecast.Periods, s.now(), s.periodTolerance); ok {
+				period = convertPeriod(period, units)
+				forecast.Period = &period
+			} else {
+				errs[i] = fmt.Errorf("Stage 3 - GetForecastResponse error: %w", errNoCurrentForecast)
+			}
+		}
+		if errs[i] != nil {
+			forecast.Error = errs[i].Error()
+		} else if primary < 0 {
+			primary = i
+		}
+		consensus.Forecasts = append(consensus.Forecasts, forecast)
+	}
+	if primary < 0 {
+		if len(errs) == 1 {
+			return providerForecast{}, nil, errs[0]
+		}
+		return providerForecast{}, nil, errors.Join(errs...)
+	}
+
+	consensus.TemperatureSpread, consensus.PrecipitationSpread = consensusSpreads(consensus.Forecasts)
+	return results[primary], consensus, nil
+}
+
+// consensusSpreads returns how far apart the temperatures and chances of precipitation of the periods are
+func consensusSpreads(forecasts []model.SourcePeriod) (float64, *float64) {
+	minTemperature, maxTemperature := math.Inf(1), math.Inf(-1)
+	minPrecipitation, maxPrecipitation := math.Inf(1), math.Inf(-1)
+	precipitations := 0
+	for _, forecast := range forecasts {
+		if forecast.Period == nil {
+			continue
+		}
+		minTemperature = math.Min(minTemperature, forecast.Period.Temperature)
+		maxTemperature = math.Max(maxTemperature, forecast.Period.Temperature)
+		if p := forecast.Period.ProbabilityOfPrecipitation.Value; p != nil {
+			minPrecipitation = math.Min(minPrecipitation, *p)
+			maxPrecipitation = math.Max(maxPrecipitation, *p)
+			precipitations++
+		}
+	}
+	temperatureSpread := round(maxTemperature-minTemperature, 1)
+	if precipitations < 2 {
+		return temperatureSpread, nil
+	}
+	precipitationSpread := maxPrecipitation - minPrecipitation
+	return temperatureSpread, &precipitationSpread
+}
+
+// providerHealth counts the consecutive failures of the providers, to skip the ones failing repeatedly
+// for a cooldown. A skipped provider is tried again after its cooldown, and skipped again while it fails.
+type providerHealth struct {
+	mu        sync.Mutex
+	threshold int
+	cooldown  time.Duration
+	now       func() time.Time
+	failures  map[ProviderSelection]int
+	skipUntil map[ProviderSelection]time.Time
+}
+
+func newProviderHealth(threshold int, cooldown time.Duration, now func() time.Time) *providerHealth {
+	return &providerHealth{
+		threshold: threshold,
+		cooldown:  cooldown,
+		now:       now,
+		failures:  make(map[ProviderSelection]int),
+		skipUntil: make(map[ProviderSelection]time.Time),
+	}
+}
+
+// healthy reports whether the provider is not skipped
+func (h *providerHealth) healthy(name ProviderSelection) bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return !h.now().Before(h.skipUntil[name])
+}
+
+// record counts a failure of the provider when err is an outage, and resets its failures otherwise
+func (h *providerHealth) record(name ProviderSelection, err error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if err == nil || !isOutage(err) {
+		delete(h.failures, name)
+		return
+	}
+	h.failures[name]++
+	if h.threshold > 0 && h.failures[name] >= h.threshold {
+		h.skipUntil[name] = h.now().Add(h.cooldown)
+		log.Printf("Forecast provider %s failed %d times in a row, skipping it for %s", name, h.failures[name], h.cooldown)
+	}
+}
+
+// isOutage reports whether a provider error means the provider is failing: it answered a server error
+// or 429, or did not answer at all. The other errors come from a provider that is up, e.g. NWS answering
+// 404 outside of its coverage, a forecast that cannot be decoded or has no current period.
+func isOutage(err error) bool {
+	var apiErr *client.APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.StatusCode >= http.StatusInternalServerError || apiErr.StatusCode == http.StatusTooManyRequests
+	}
+	// timeouts, refused connections and the other transport errors
+	var urlErr *url.Error
+	var netErr net.Error
+	return errors.Is(err, context.DeadlineExceeded) || errors.As(err, &urlErr) || errors.As(err, &netErr)
+}
@@ -0,0 +1,349 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/softstone1/fl/internal/client"
+	"github.com/softstone1/fl/internal/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+var denver = model.Location{Name: "Denver", Latitude: 39.7392, Longitude: -104.9903}
+
+func TestParseFailoverRules(t *testing.T) {
+	rules, err := ParseFailoverRules("us=nws,open-meteo; 35,-10,60,30 = open-meteo ;*=nws")
+
+	require.NoError(t, err)
+	require.Len(t, rules, 3)
+	assert.True(t, rules[0].NWSCoverage)
+	assert.Equal(t, []ProviderSelection{ProviderNWS, ProviderOpenMeteo}, rules[0].Providers)
+	assert.Equal(t, &client.BoundingBox{South: 35, West: -10, North: 60, East: 30}, rules[1].Box)
+	assert.Equal(t, []ProviderSelection{ProviderOpenMeteo}, rules[1].Providers)
+	assert.Equal(t, FailoverRule{Providers: []ProviderSelection{ProviderNWS}}, rules[2])
+
+	_, err = ParseFailoverRules("us")
+	assert.EqualError(t, err, `invalid failover rule "us": expected area=providers`)
+	_, err = ParseFailoverRules("*=auto")
+	assert.EqualError(t, err, `unknown forecast provider: "auto"`)
+	_, err = ParseFailoverRules("north=nws")
+	assert.ErrorContains(t, err, "invalid failover rule area")
+}
+
+func TestUsesProvider(t *testing.T) {
+	nwsOnly := []FailoverRule{{Providers: []ProviderSelection{ProviderNWS}}}
+
+	assert.True(t, UsesProvider(ProviderAuto, nil, ProviderOpenMeteo))
+	assert.False(t, UsesProvider(ProviderNWS, nil, ProviderOpenMeteo))
+	// the rules replace the ones of the selection
+	assert.False(t, UsesProvider(ProviderAuto, nwsOnly, ProviderOpenMeteo))
+	assert.True(t, UsesProvider(ProviderOpenMeteo, nwsOnly, ProviderNWS))
+}
+
+func TestProvidersFor(t *testing.T) {
+	var calls atomic.Int32
+	srv := newOpenMeteoStandIn(t, &calls)
+	defer srv.Close()
+
+	honolulu := model.Location{Latitude: 21.3069, Longitude: -157.8583}
+	europe := []FailoverRule{{Box: &client.BoundingBox{South: 35, West: -10, North: 60, East: 30}, Providers: []ProviderSelection{ProviderOpenMeteo, ProviderNWS}}}
+	tests := []struct {
+		name      string
+		selection ProviderSelection
+		rules     []FailoverRule
+		location  model.Location
+		expected  []ProviderSelection
+	}{
+		{name: "Auto in the US", selection: ProviderAuto, location: denver, expected: []ProviderSelection{ProviderNWS, ProviderOpenMeteo}},
+		{name: "Auto in Hawaii", selection: ProviderAuto, location: honolulu, expected: []ProviderSelection{ProviderNWS, ProviderOpenMeteo}},
+		{name: "Auto abroad", selection: ProviderAuto, location: paris, expected: []ProviderSelection{ProviderOpenMeteo}},
+		{name: "NWS abroad", selection: ProviderNWS, location: paris, expected: []ProviderSelection{ProviderNWS}},
+		{name: "Open-Meteo in the US", selection: ProviderOpenMeteo, location: denver, expected: []ProviderSelection{ProviderOpenMeteo}},
+		{name: "Rule box", rules: europe, location: paris, expected: []ProviderSelection{ProviderOpenMeteo, ProviderNWS}},
+		{name: "No rule matching", rules: europe, location: denver, expected: []ProviderSelection{ProviderNWS}},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			svc := newOpenMeteoService(t, srv, nil, tc.selection, WithFailover(FailoverConfiguration{Rules: tc.rules}))
+			var names []ProviderSelection
+			for _, provider := range svc.providersFor(tc.location) {
+				names = append(names, provider.name())
+			}
+			assert.Equal(t, tc.expected, names)
+		})
+	}
+}
+
+func TestGetForecastPeriods_OutsideNWSCoverage(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	var calls atomic.Int32
+	srv := newOpenMeteoStandIn(t, &calls)
+	defer srv.Close()
+	mockForecast := client.NewMockForecast(ctrl)
+	svc := newOpenMeteoService(t, srv, mockForecast, ProviderAuto)
+
+	// Toronto is in the bounding box of the contiguous US, NWS is only asked once for its cell
+	toronto := model.Location{Name: "Toronto", Latitude: 43.6532, Longitude: -79.3832}
+	nearby := model.Location{Latitude: 43.6611, Longitude: -79.3957}
+	mockForecast.EXPECT().GetPointMetadata(gomock.Any(), gomock.Any(), gomock.Any()).Return(
+		nil, fmt.Errorf("failed to fetch forecast: %w", &client.APIError{StatusCode: http.StatusNotFound}),
+	).Times(1)
+
+	for _, location := range []model.Location{toronto, nearby} {
+		resp, err := svc.GetForecastPeriods(context.Background(), PeriodsQuery{Location: &location})
+		require.NoError(t, err)
+		assert.Equal(t, "open-meteo", resp.Source)
+		assert.Nil(t, resp.Alerts)
+	}
+	assert.True(t, svc.health.healthy(ProviderNWS))
+}
+
+func TestGetForecastPeriods_Failover(t *testing.T) {
+	tests := []struct {
+		name      string
+		pointsErr func(ctx context.Context, _, _ float64) (*model.PointMetadata, error)
+	}{
+		{
+			name: "NWS fails",
+			pointsErr: func(context.Context, float64, float64) (*model.PointMetadata, error) {
+				return nil, &client.APIError{StatusCode: http.StatusServiceUnavailable}
+			},
+		},
+		{
+			name: "NWS times out",
+			pointsErr: func(ctx context.Context, _, _ float64) (*model.PointMetadata, error) {
+				<-ctx.Done()
+				return nil, ctx.Err()
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			var calls atomic.Int32
+			srv := newOpenMeteoStandIn(t, &calls)
+			defer srv.Close()
+			mockForecast := client.NewMockForecast(ctrl)
+			svc := newOpenMeteoService(t, srv, mockForecast, ProviderAuto, WithFailover(FailoverConfiguration{AttemptTimeout: 20 * time.Millisecond}))
+
+			mockForecast.EXPECT().GetPointMetadata(gomock.Any(), denver.Latitude, denver.Longitude).DoAndReturn(tc.pointsErr)
+
+			resp, err := svc.GetForecastPeriods(context.Background(), PeriodsQuery{Location: &denver})
+
+			require.NoError(t, err)
+			assert.Equal(t, "open-meteo", resp.Source)
+			assert.Equal(t, "Today", resp.Periods[0].Name)
+		})
+	}
+}
+
+func TestGetRandomForecast_FailoverOnStaleForecast(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	var calls atomic.Int32
+	srv := newOpenMeteoStandIn(t, &calls)
+	defer srv.Close()
+	mockForecast := client.NewMockForecast(ctrl)
+	svc := newOpenMeteoService(t, srv, mockForecast, ProviderAuto)
+
+	// the NWS periods all ended, Open-Meteo has the current one
+	now := time.Date(2025, 7, 1, 8, 30, 0, 0, time.UTC)
+	mockForecast.EXPECT().GetPointMetadata(gomock.Any(), gomock.Any(), gomock.Any()).Return(
+		&model.PointMetadata{Forecast: "http://test.url", TimeZone: "America/Denver"}, nil,
+	)
+	mockForecast.EXPECT().GetForecastPeriods(gomock.Any(), "http://test.url").Return(
+		&model.Forecast{Periods: []model.ForcastPeriod{{StartTime: now.Add(-2 * time.Hour), EndTime: now.Add(-time.Hour)}}}, nil,
+	)
+
+	resp, err := svc.GetRandomForecast(context.Background(), CurrentQuery{Location: &denver})
+
+	require.NoError(t, err)
+	assert.Equal(t, "open-meteo", resp.Source)
+	assert.True(t, svc.health.healthy(ProviderNWS))
+}
+
+func TestFailover_HealthAwareSkipping(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	var calls atomic.Int32
+	srv := newOpenMeteoStandIn(t, &calls)
+	defer srv.Close()
+	now := time.Date(2025, 7, 1, 8, 30, 0, 0, time.UTC)
+	mockForecast := client.NewMockForecast(ctrl)
+	svc := newOpenMeteoService(t, srv, mockForecast, ProviderAuto,
+		WithClock(func() time.Time { return now }),
+		WithFailover(FailoverConfiguration{FailureThreshold: 2, Cooldown: time.Minute}),
+	)
+	outage := &client.APIError{StatusCode: http.StatusBadGateway}
+
+	// NWS is skipped after failing twice in a row, and tried again once its cooldown is over
+	mockForecast.EXPECT().GetPointMetadata(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, outage).Times(3)
+	for i := 0; i < 3; i++ {
+		resp, err := svc.GetForecastPeriods(context.Background(), PeriodsQuery{Location: &denver})
+		require.NoError(t, err)
+		assert.Equal(t, "open-meteo", resp.Source)
+	}
+	assert.False(t, svc.health.healthy(ProviderNWS))
+
+	now = now.Add(time.Minute)
+	_, err := svc.GetForecastPeriods(context.Background(), PeriodsQuery{Location: &denver})
+	require.NoError(t, err)
+	assert.False(t, svc.health.healthy(ProviderNWS))
+}
+
+func TestProviderHealth(t *testing.T) {
+	now := time.Now()
+	health := newProviderHealth(1, time.Minute, func() time.Time { return now })
+
+	// NWS answering it has no forecast for a location is no outage
+	health.record(ProviderNWS, &client.APIError{StatusCode: http.StatusNotFound})
+	assert.True(t, health.healthy(ProviderNWS))
+
+	health.record(ProviderNWS, context.DeadlineExceeded)
+	assert.False(t, health.healthy(ProviderNWS))
+	assert.True(t, health.healthy(ProviderOpenMeteo))
+
+	now = now.Add(time.Minute)
+	assert.True(t, health.healthy(ProviderNWS))
+	// a forecast that cannot be decoded comes from a provider that is up
+	health.record(ProviderNWS, fmt.Errorf("failed to fetch forecast: %w", &json.SyntaxError{}))
+	assert.True(t, health.healthy(ProviderNWS))
+	health.record(ProviderNWS, &url.Error{Op: "Get", URL: "http://test.url", Err: errors.New("connection refused")})
+	assert.False(t, health.healthy(ProviderNWS))
+
+	now = now.Add(time.Minute)
+	health.record(ProviderNWS, nil)
+	health.record(ProviderNWS, &client.APIError{StatusCode: http.StatusTooManyRequests})
+	assert.False(t, health.healthy(ProviderNWS))
+}
+
+func TestGetRandomForecast_Consensus(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	var calls atomic.Int32
+	srv := newOpenMeteoStandIn(t, &calls)
+	defer srv.Close()
+	mockForecast := client.NewMockForecast(ctrl)
+	svc := newOpenMeteoService(t, srv, mockForecast, ProviderAuto)
+
+	now := time.Date(2025, 7, 1, 8, 30, 0, 0, time.UTC)
+	probability := 20.0
+	mockForecast.EXPECT().GetPointMetadata(gomock.Any(), gomock.Any(), gomock.Any()).Return(
+		&model.PointMetadata{Forecast: "http://test.url", TimeZone: "America/Denver"}, nil,
+	)
+	mockForecast.EXPECT().GetForecastPeriods(gomock.Any(), "http://test.url").Return(
+		&model.Forecast{Periods: []model.ForcastPeriod{{
+			StartTime:                  now.Add(-time.Hour),
+			EndTime:                    now.Add(time.Hour),
+			Temperature:                70,
+			TemperatureUnit:            "F",
+			ProbabilityOfPrecipitation: model.QuantitativeValue{Value: &probability, UnitCode: "wmoUnit:percent"},
+			ShortForecast:              "Sunny",
+		}}}, nil,
+	)
+
+	resp, err := svc.GetRandomForecast(context.Background(), CurrentQuery{Location: &denver, Consensus: true})
+
+	require.NoError(t, err)
+	assert.Equal(t, "nws", resp.Source)
+	assert.Equal(t, "Sunny", resp.Period.ShortForecast)
+	require.NotNil(t, resp.Consensus)
+	require.Len(t, resp.Consensus.Forecasts, 2)
+	assert.Equal(t, "nws", resp.Consensus.Forecasts[0].Source)
+	assert.Equal(t, "open-meteo", resp.Consensus.Forecasts[1].Source)
+	assert.Equal(t, 63.0, resp.Consensus.Forecasts[1].Period.Temperature)
+	assert.Equal(t, "America/Denver", resp.Consensus.Forecasts[1].Period.StartTime.Location().String())
+	assert.Equal(t, 7.0, resp.Consensus.TemperatureSpread)
+	assert.Equal(t, 10.0, *resp.Consensus.PrecipitationSpread)
+}
+
+func TestGetRandomForecast_ConsensusWithFailure(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	var calls atomic.Int32
+	srv := newOpenMeteoStandIn(t, &calls)
+	defer srv.Close()
+	mockForecast := client.NewMockForecast(ctrl)
+	svc := newOpenMeteoService(t, srv, mockForecast, ProviderAuto)
+
+	mockForecast.EXPECT().GetPointMetadata(gomock.Any(), gomock.Any(), gomock.Any()).Return(
+		nil, &client.APIError{StatusCode: http.StatusInternalServerError},
+	)
+
+	resp, err := svc.GetRandomForecast(context.Background(), CurrentQuery{Location: &denver, Consensus: true})
+
+	require.NoError(t, err)
+	assert.Equal(t, "open-meteo", resp.Source)
+	require.Len(t, resp.Consensus.Forecasts, 2)
+	assert.Nil(t, resp.Consensus.Forecasts[0].Period)
+	assert.Contains(t, resp.Consensus.Forecasts[0].Error, "Stage 2 - GetPointMetadata error")
+	assert.Equal(t, 0.0, resp.Consensus.TemperatureSpread)
+	assert.Nil(t, resp.Consensus.PrecipitationSpread)
+}
+
+func TestGetRandomForecast_ConsensusWithHungProvider(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	var calls atomic.Int32
+	srv := newOpenMeteoStandIn(t, &calls)
+	defer srv.Close()
+	mockForecast := client.NewMockForecast(ctrl)
+	svc := newOpenMeteoService(t, srv, mockForecast, ProviderAuto, WithFailover(FailoverConfiguration{AttemptTimeout: 20 * time.Millisecond}))
+
+	// NWS does not answer before the request deadline, the consensus does not wait for it
+	mockForecast.EXPECT().GetPointMetadata(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(
+		func(ctx context.Context, _, _ float64) (*model.PointMetadata, error) {
+			<-ctx.Done()
+			return nil, ctx.Err()
+		},
+	)
+
+	resp, err := svc.GetRandomForecast(context.Background(), CurrentQuery{Location: &denver, Consensus: true})
+
+	require.NoError(t, err)
+	assert.Equal(t, "open-meteo", resp.Source)
+	assert.Contains(t, resp.Consensus.Forecasts[0].Error, context.DeadlineExceeded.Error())
+}
+
+func TestGetRandomForecast_ConsensusSingleProvider(t *testing.T) {
+	svc, err := NewForecast(nil, nil, time.Second, 10)
+	require.NoError(t, err)
+
+	_, err = svc.GetRandomForecast(context.Background(), CurrentQuery{Location: &denver, Consensus: true})
+
+	assert.ErrorIs(t, err, ErrConsensusUnavailable)
+}
+
+func TestConsensusProviders(t *testing.T) {
+	var calls atomic.Int32
+	srv := newOpenMeteoStandIn(t, &calls)
+	defer srv.Close()
+	svc := newOpenMeteoService(t, srv, nil, ProviderAuto)
+
+	// NWS is compared outside of its coverage too, after the providers of the location
+	var names []ProviderSelection
+	for _, provider := range svc.consensusProviders(paris) {
+		names = append(names, provider.name())
+	}
+	assert.Equal(t, []ProviderSelection{ProviderOpenMeteo, ProviderNWS}, names)
+}
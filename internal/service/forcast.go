@@ -4,125 +4,942 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"log"
 	"time"
 
-	lru "github.com/hashicorp/golang-lru/v2"
 	"github.com/softstone1/fl/internal/client"
 	"github.com/softstone1/fl/internal/model"
 )
 
 type Forecast interface {
-	GetRandomForecast(ctx context.Context) (string, error)
+	GetRandomForecast(ctx context.Context, query CurrentQuery) (*model.CurrentForecast, error)
+	GetForecastPeriods(ctx context.Context, query PeriodsQuery) (*model.ForecastPeriods, error)
+	GetHourlyForecast(ctx context.Context, query HourlyQuery) (*model.ForecastPeriods, error)
+	GetAlerts(ctx context.Context, query AlertsQuery) (*model.LocationAlerts, error)
+	GetGridpoints(ctx context.Context, query GridpointsQuery) (*model.GridSeriesData, error)
+	GetPoint(ctx context.Context, query PointQuery) (*model.Point, error)
+	GetForecastBatch(ctx context.Context, query BatchQuery, emit func(BatchResult)) error
+}
+
+// CurrentQuery selects how GetRandomForecast returns the current forecast
+type CurrentQuery struct {
+	// Location to forecast, a random one when nil
+	Location *model.Location
+	// Seed replays the random location picked with it when Location is nil, a new one is drawn when nil
+	Seed *int64
+	// Region restricts the random location when Location is nil, the configured filter region when nil
+	Region *Region
+	// Place is the name of the place to forecast, e.g. "Seattle, WA", instead of Location
+	Place string
+	// Units of the forecast and observation, the service default when empty
+	Units model.Units
+	// TimeZone of the returned times, the service default or else the location time zone when nil
+	TimeZone *time.Location
+	// Consensus forecasts the current period with every configured provider, in parallel, and compares
+	// their forecasts. ErrConsensusUnavailable is returned when a single provider is configured.
+	Consensus bool
+}
+
+// PeriodsQuery selects the upcoming forecast periods returned by GetForecastPeriods
+type PeriodsQuery struct {
+	// Location to forecast, a random one when nil
+	Location *model.Location
+	// Seed replays the random location picked with it when Location is nil, a new one is drawn when nil
+	Seed *int64
+	// Region restricts the random location when Location is nil, the configured filter region when nil
+	Region *Region
+	// From and To keep the periods overlapping the range, when set
+	From time.Time
+	To   time.Time
+	// Limit is the max number of periods, all when zero
+	Limit int
+	// DaytimeOnly drops the night periods
+	DaytimeOnly bool
+	// Units of the periods, the service default when empty
+	Units model.Units
+	// TimeZone of the returned times, the service default or else the location time zone when nil
+	TimeZone *time.Location
+}
+
+// HourlyQuery selects the hourly forecast periods returned by GetHourlyForecast
+type HourlyQuery struct {
+	// Location to forecast, a random one when nil
+	Location *model.Location
+	// Seed replays the random location picked with it when Location is nil, a new one is drawn when nil
+	Seed *int64
+	// Region restricts the random location when Location is nil, the configured filter region when nil
+	Region *Region
+	// Horizon is how far ahead periods are returned, the service default when zero
+	Horizon time.Duration
+	// Units of the periods, the service default when empty
+	Units model.Units
+	// TimeZone of the returned times, the service default or else the location time zone when nil
+	TimeZone *time.Location
+}
+
+// AlertsQuery selects the active alerts returned by GetAlerts
+type AlertsQuery struct {
+	// Location of the alerts, a random one when nil
+	Location *model.Location
+	// Seed replays the random location picked with it when Location is nil, a new one is drawn when nil
+	Seed *int64
+	// Region restricts the random location when Location is nil, the configured filter region when nil
+	Region *Region
+	// TimeZone of the returned times, the service default or else the location time zone when nil
+	TimeZone *time.Location
+}
+
+// PointQuery selects the location whose metadata GetPoint returns
+type PointQuery struct {
+	// Location to look up, a random one when nil
+	Location *model.Location
 }
 
 type forecast struct {
 	LocationClient       client.Location
 	ForcastClient        client.Forecast
+	AlertsClient         client.Alerts
+	ObservationsClient   client.Observations
+	Geocoder             client.Geocoder
 	Timeout              time.Duration
-	forecastURLCache     *lru.Cache[string, string]
-	forecastPeriodsCache *lru.Cache[string, []model.ForcastPeriod]
+	pointsCache          Cache[model.PointMetadata]
+	forecastPeriodsCache Cache[model.Forecast]
+	forecastFetches      callGroup[model.Forecast]
+	gridDataCache        Cache[model.GridData]
+	gridDataTTL          time.Duration
+	alertsCache          Cache[[]model.Alert]
+	alertsTTL            time.Duration
+	stationsCache        Cache[[]model.Station]
+	observationsCache    Cache[model.Observation]
+	observationsTTL      time.Duration
+	maxStations          int
+	seedsCache           Cache[model.Location]
+	nwsUncoveredCache    Cache[bool]
+	seedTTL              time.Duration
+	locationPool         *locationPool
+	locationFilter       LocationFilter
+	batchMaxSize         int
+	batchConcurrency     int
+	batchTimeout         time.Duration
+	providers            map[ProviderSelection]forecastProvider
+	failoverRules        []FailoverRule
+	attemptTimeout       time.Duration
+	health               *providerHealth
+	refreshes            *refreshSchedule
+	periodTolerance      time.Duration
+	hourlyHorizon        time.Duration
+	units                model.Units
+	zone                 *time.Location
+	now                  func() time.Time
 }
 
 // make sure forcast implements the Forcast interface
 var _ Forecast = (*forecast)(nil)
 
-func NewForecast(locationClient client.Location, forcastClient client.Forecast, timeout time.Duration, cacheSize int) (*forecast, error) {
+var errNoCurrentForecast = errors.New("no current detailed forecast found")
+
+// ErrAlertsDisabled is returned by GetAlerts when the service has no alerts client
+var ErrAlertsDisabled = errors.New("alerts are not enabled")
+
+// forecastKind distinguishes the standard forecast, in 12 hour periods, from the hourly one
+type forecastKind int
+
+const (
+	standardForecast forecastKind = iota
+	hourlyForecast
+)
+
+// forecastRequest identifies a standard or hourly forecast by its cache key and API URL
+type forecastRequest struct {
+	kind forecastKind
+	key  string
+	url  string
+}
+
+// newForecastRequest returns the request of the forecast of kind in units at the point. Its cache key is
+// the grid cell of the point, so all the points in a cell share one entry.
+func newForecastRequest(point model.PointMetadata, kind forecastKind, units model.Units) forecastRequest {
+	forecastURL, path := point.Forecast, "forecast"
+	if kind == hourlyForecast {
+		forecastURL, path = point.ForecastHourly, "forecast/hourly"
+	}
+	forecastURL = unitsForecastURL(forecastURL, units)
+
+	key := forecastURL
+	if point.GridID != "" {
+		key = fmt.Sprintf("%s/%d,%d/%s", point.GridID, point.GridX, point.GridY, path)
+		if units == model.UnitsSI {
+			key += "?units=si"
+		}
+	}
+	return forecastRequest{kind: kind, key: key, url: forecastURL}
+}
+
+// options holds the optional settings of the forecast service
+type options struct {
+	redis     *RedisClient
+	keyPrefix string
+	tiered    bool
+	localTTL  time.Duration
+
+	updateInterval time.Duration
+	idleTimeout    time.Duration
+
+	periodTolerance time.Duration
+	hourlyHorizon   time.Duration
+	gridDataTTL     time.Duration
+	alertsClient    client.Alerts
+	alertsTTL       time.Duration
+
+	observationsClient client.Observations
+	observationsTTL    time.Duration
+	maxStations        int
+
+	geocoder client.Geocoder
+
+	seedLocations  []model.Location
+	seedTTL        time.Duration
+	locationFilter LocationFilter
+
+	batchMaxSize     int
+	batchConcurrency int
+	batchTimeout     time.Duration
+
+	openMeteoClient   client.OpenMeteo
+	providerSelection ProviderSelection
+	openMeteoTTL      time.Duration
+	failover          FailoverConfiguration
+
+	units model.Units
+	zone  *time.Location
+	now   func() time.Time
+}
+
+// Option customizes the forecast service
+type Option func(*options)
+
+// WithRedisCache stores the forecast caches in Redis only, sharing them between replicas
+func WithRedisCache(client *RedisClient, keyPrefix string) Option {
+	return func(o *options) {
+		o.redis = client
+		o.keyPrefix = keyPrefix
+		o.tiered = false
+	}
+}
+
+// WithTieredCache keeps an in-process LRU, whose entries live at most localTTL, in front of Redis
+func WithTieredCache(client *RedisClient, keyPrefix string, localTTL time.Duration) Option {
+	return func(o *options) {
+		o.redis = client
+		o.keyPrefix = keyPrefix
+		o.tiered = true
+		o.localTTL = localTTL
+	}
+}
+
+// WithBackgroundRefresh tracks the served forecasts so that RefreshDue refreshes them when NWS is
+// expected to update them, every updateInterval, and when their current period ends.
+// Forecasts not served within idleTimeout are no longer refreshed.
+func WithBackgroundRefresh(updateInterval, idleTimeout time.Duration) Option {
+	return func(o *options) {
+		o.updateInterval = updateInterval
+		o.idleTimeout = idleTimeout
+	}
+}
+
+// WithPeriodTolerance selects the nearest upcoming period starting within tolerance
+// when no period covers the current time, e.g. when NWS returns periods starting slightly in the future
+func WithPeriodTolerance(tolerance time.Duration) Option {
+	return func(o *options) {
+		o.periodTolerance = tolerance
+	}
+}
+
+// WithHourlyHorizon sets how far ahead GetHourlyForecast returns periods by default
+func WithHourlyHorizon(horizon time.Duration) Option {
+	return func(o *options) {
+		o.hourlyHorizon = horizon
+	}
+}
+
+// WithGridDataTTL sets how long the raw gridpoint data is cached, 15 minutes by default
+func WithGridDataTTL(ttl time.Duration) Option {
+	return func(o *options) {
+		o.gridDataTTL = ttl
+	}
+}
+
+// WithAlerts includes the active alerts of the location in the forecasts. Alerts are cached for ttl.
+func WithAlerts(alertsClient client.Alerts, ttl time.Duration) Option {
+	return func(o *options) {
+		o.alertsClient = alertsClient
+		o.alertsTTL = ttl
+	}
+}
+
+// WithObservations includes the latest observation of the nearest station in the current forecast.
+// Up to maxStations stations are tried, nearest first, until one has a usable observation.
+// Observations are cached for ttl.
+func WithObservations(observationsClient client.Observations, ttl time.Duration, maxStations int) Option {
+	return func(o *options) {
+		o.observationsClient = observationsClient
+		o.observationsTTL = ttl
+		o.maxStations = maxStations
+	}
+}
+
+// WithUnits sets the unit system of the responses whose query does not select one, US by default
+func WithUnits(units model.Units) Option {
+	return func(o *options) {
+		o.units = units
+	}
+}
+
+// WithTimeZone renders the times of every response in zone, instead of the location time zone,
+// unless the query selects another one
+func WithTimeZone(zone *time.Location) Option {
+	return func(o *options) {
+		o.zone = zone
+	}
+}
+
+// WithClock replaces time.Now as the source of the current time
+func WithClock(now func() time.Time) Option {
+	return func(o *options) {
+		o.now = now
+	}
+}
+
+func NewForecast(locationClient client.Location, forcastClient client.Forecast, timeout time.Duration, cacheSize int, opts ...Option) (*forecast, error) {
+	o := &options{
+		hourlyHorizon:     24 * time.Hour,
+		gridDataTTL:       15 * time.Minute,
+		units:             model.UnitsUS,
+		batchMaxSize:      250,
+		batchConcurrency:  8,
+		batchTimeout:      10 * time.Second,
+		seedTTL:           24 * time.Hour,
+		providerSelection: ProviderNWS,
+		openMeteoTTL:      30 * time.Minute,
+		now:               time.Now,
+		failover: FailoverConfiguration{
+			AttemptTimeout:   5 * time.Second,
+			FailureThreshold: 3,
+			Cooldown:         30 * time.Second,
+		},
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
 
-	forecastURLCache, err := lru.New[string, string](cacheSize)
+	pointsCache, err := newCache[model.PointMetadata](o, "points", cacheSize)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create forecastURLCache: %w", err)
+		return nil, fmt.Errorf("failed to create pointsCache: %w", err)
 	}
-	forecastPeriodsCache, err := lru.New[string, []model.ForcastPeriod](cacheSize)
+	forecastPeriodsCache, err := newCache[model.Forecast](o, "forecast", cacheSize)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create forecastPeriodsCache: %w", err)
 	}
 
-	return &forecast{
+	gridDataCache, err := newCache[model.GridData](o, "gridData", cacheSize)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create gridDataCache: %w", err)
+	}
+
+	alertsCache, err := newCache[[]model.Alert](o, "alerts", cacheSize)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create alertsCache: %w", err)
+	}
+	stationsCache, err := newCache[[]model.Station](o, "stations", cacheSize)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create stationsCache: %w", err)
+	}
+	observationsCache, err := newCache[model.Observation](o, "observations", cacheSize)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create observationsCache: %w", err)
+	}
+	seedsCache, err := newCache[model.Location](o, "seeds", cacheSize)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create seedsCache: %w", err)
+	}
+
+	nwsUncoveredCache, err := newCache[bool](o, "nwsUncovered", cacheSize)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create nwsUncoveredCache: %w", err)
+	}
+
+	s := &forecast{
 		LocationClient:       locationClient,
 		ForcastClient:        forcastClient,
+		AlertsClient:         o.alertsClient,
+		ObservationsClient:   o.observationsClient,
+		Geocoder:             o.geocoder,
 		Timeout:              timeout,
-		forecastURLCache:     forecastURLCache,
+		pointsCache:          pointsCache,
 		forecastPeriodsCache: forecastPeriodsCache,
+		forecastFetches:      callGroup[model.Forecast]{timeout: timeout},
+		gridDataCache:        gridDataCache,
+		gridDataTTL:          o.gridDataTTL,
+		alertsCache:          alertsCache,
+		alertsTTL:            o.alertsTTL,
+		stationsCache:        stationsCache,
+		observationsCache:    observationsCache,
+		observationsTTL:      o.observationsTTL,
+		maxStations:          o.maxStations,
+		seedsCache:           seedsCache,
+		nwsUncoveredCache:    nwsUncoveredCache,
+		seedTTL:              o.seedTTL,
+		locationPool:         newLocationPool(cacheSize, o.seedLocations),
+		locationFilter:       o.locationFilter,
+		batchMaxSize:         o.batchMaxSize,
+		batchConcurrency:     o.batchConcurrency,
+		batchTimeout:         o.batchTimeout,
+		failoverRules:        o.failover.Rules,
+		attemptTimeout:       o.failover.AttemptTimeout,
+		health:               newProviderHealth(o.failover.FailureThreshold, o.failover.Cooldown, o.now),
+		periodTolerance:      o.periodTolerance,
+		hourlyHorizon:        o.hourlyHorizon,
+		units:                o.units,
+		zone:                 o.zone,
+		now:                  o.now,
+	}
+	s.providers = map[ProviderSelection]forecastProvider{ProviderNWS: nwsProvider{s}}
+	if o.openMeteoClient != nil {
+		openMeteoCache, err := newCache[client.OpenMeteoForecast](o, "openMeteo", cacheSize)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create openMeteoCache: %w", err)
+		}
+		s.providers[ProviderOpenMeteo] = &openMeteoProvider{
+			client:  o.openMeteoClient,
+			cache:   openMeteoCache,
+			fetches: callGroup[client.OpenMeteoForecast]{timeout: timeout},
+			ttl:     o.openMeteoTTL,
+			now:     o.now,
+		}
+	}
+	if len(s.failoverRules) == 0 {
+		s.failoverRules = selectionRules(o.providerSelection)
+	}
+	for _, rule := range s.failoverRules {
+		for _, name := range rule.Providers {
+			if _, ok := s.providers[name]; !ok {
+				return nil, fmt.Errorf("forecast provider %q is not configured", name)
+			}
+		}
+	}
+	if o.updateInterval > 0 {
+		s.refreshes = newRefreshSchedule(o.updateInterval, o.idleTimeout)
+	}
+	return s, nil
+}
+
+// newCache creates the cache called name for the configured backend
+func newCache[V any](o *options, name string, size int) (Cache[V], error) {
+	if o.redis == nil {
+		return NewLRUCache[V](size, o.now)
+	}
+	shared := NewRedisCache[V](o.redis, fmt.Sprintf("%sv%d:%s:", o.keyPrefix, cacheLayoutVersion, name))
+	if !o.tiered {
+		return shared, nil
+	}
+	local, err := NewLRUCache[V](size, o.now)
+	if err != nil {
+		return nil, err
+	}
+	return NewTieredCache[V](local, shared, o.localTTL), nil
+}
+
+// GetRandomForecast orchestrates fetching the query location or a random one, point metadata, and current forcast period with timeout and caching
+func (s *forecast) GetRandomForecast(ctx context.Context, query CurrentQuery) (*model.CurrentForecast, error) {
+	if query.Consensus && len(s.providers) < 2 {
+		return nil, ErrConsensusUnavailable
+	}
+	ctx, cancel := context.WithTimeout(ctx, s.Timeout)
+	defer cancel()
+
+	location := query.Location
+	if query.Place != "" {
+		place, err := s.findPlace(ctx, query.Place)
+		if err != nil {
+			return nil, err
+		}
+		location = &place
+	}
+	location, err := s.resolveLocation(ctx, location, query.Seed, query.Region)
+	if err != nil {
+		return nil, err
+	}
+
+	units := s.resolveUnits(query.Units)
+	var result providerForecast
+	var consensus *model.Consensus
+	if query.Consensus {
+		result, consensus, err = s.consensusForecast(ctx, *location, units)
+	} else {
+		result, err = s.failoverForecast(ctx, *location, standardForecast, units)
+	}
+	if err != nil {
+		return nil, err
+	}
+	period, ok := findCurrentPeriod(result.Forecast.Periods, s.now(), s.periodTolerance)
+	if !ok {
+		return nil, fmt.Errorf("Stage 3 - GetForecastResponse error: %w", errNoCurrentForecast)
+	}
+
+	point := result.Point
+	zone := s.resolveZone(query.TimeZone, point.TimeZone)
+	observation := s.getObservationOrNone(ctx, point.ObservationStations)
+	if observation != nil {
+		*observation = convertObservation(*observation, units)
+		observation.Timestamp = timeIn(observation.Timestamp, zone)
+	}
+	periods := []model.ForcastPeriod{convertPeriod(period, units)}
+	periodsIn(periods, zone)
+	if consensus != nil {
+		for _, forecast := range consensus.Forecasts {
+			if forecast.Period != nil {
+				localized := []model.ForcastPeriod{*forecast.Period}
+				periodsIn(localized, zone)
+				*forecast.Period = localized[0]
+			}
+		}
+	}
+	described, alerts := s.supplement(ctx, *location, result, units)
+
+	return &model.CurrentForecast{
+		Location:    described,
+		Period:      periods[0],
+		Alerts:      alertsIn(alerts, zone),
+		Observation: observation,
+		Source:      string(result.Source),
+		Consensus:   consensus,
+	}, nil
+}
+
+// GetForecastPeriods returns the upcoming forecast periods of the query location matching the query filters
+func (s *forecast) GetForecastPeriods(ctx context.Context, query PeriodsQuery) (*model.ForecastPeriods, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.Timeout)
+	defer cancel()
+
+	location, err := s.resolveLocation(ctx, query.Location, query.Seed, query.Region)
+	if err != nil {
+		return nil, err
+	}
+
+	units := s.resolveUnits(query.Units)
+	result, err := s.failoverForecast(ctx, *location, standardForecast, units)
+	if err != nil {
+		return nil, err
+	}
+
+	zone := s.resolveZone(query.TimeZone, result.Point.TimeZone)
+	periods := convertPeriods(filterPeriods(result.Forecast.Periods, query, s.now()), units)
+	periodsIn(periods, zone)
+	described, alerts := s.supplement(ctx, *location, result, units)
+
+	return &model.ForecastPeriods{
+		Location: described,
+		Periods:  periods,
+		Alerts:   alertsIn(alerts, zone),
+		Source:   string(result.Source),
+	}, nil
+}
+
+// GetHourlyForecast returns the hourly forecast periods of the query location up to the query horizon
+func (s *forecast) GetHourlyForecast(ctx context.Context, query HourlyQuery) (*model.ForecastPeriods, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.Timeout)
+	defer cancel()
+
+	location, err := s.resolveLocation(ctx, query.Location, query.Seed, query.Region)
+	if err != nil {
+		return nil, err
+	}
+
+	units := s.resolveUnits(query.Units)
+	result, err := s.failoverForecast(ctx, *location, hourlyForecast, units)
+	if err != nil {
+		return nil, err
+	}
+
+	horizon := query.Horizon
+	if horizon <= 0 {
+		horizon = s.hourlyHorizon
+	}
+	current := s.now()
+	zone := s.resolveZone(query.TimeZone, result.Point.TimeZone)
+	periods := convertPeriods(filterPeriods(result.Forecast.Periods, PeriodsQuery{To: current.Add(horizon)}, current), units)
+	periodsIn(periods, zone)
+	described, alerts := s.supplement(ctx, *location, result, units)
+
+	return &model.ForecastPeriods{
+		Location: described,
+		Periods:  periods,
+		Alerts:   alertsIn(alerts, zone),
+		Source:   string(result.Source),
+	}, nil
+}
+
+// GetAlerts returns the active alerts at the query location, or a random one when it is nil
+func (s *forecast) GetAlerts(ctx context.Context, query AlertsQuery) (*model.LocationAlerts, error) {
+	if s.AlertsClient == nil {
+		return nil, ErrAlertsDisabled
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, s.Timeout)
+	defer cancel()
+
+	location, err := s.resolveLocation(ctx, query.Location, query.Seed, query.Region)
+	if err != nil {
+		return nil, err
+	}
+
+	activeAlerts, err := s.getAlerts(ctx, location.Latitude, location.Longitude)
+	if err != nil {
+		return nil, fmt.Errorf("Stage 2 - GetActiveAlerts error: %w", err)
+	}
+
+	// the location time zone and name only come with the point metadata, which is looked up when needed
+	var point model.PointMetadata
+	if query.TimeZone == nil && s.zone == nil || location.Name == "" {
+		point, err = s.getPointMetadata(ctx, location.Latitude, location.Longitude)
+		if err != nil {
+			log.Printf("Failed to get the point metadata of the alerts: %v", err)
+		}
+	}
+	return &model.LocationAlerts{
+		Location: s.describeLocation(ctx, *location, point, s.units),
+		Alerts:   alertsIn(activeAlerts, s.resolveZone(query.TimeZone, point.TimeZone)),
 	}, nil
 }
 
-// GetRandomForecast orchestrates fetching random location, forecast URL, and current detailed forcast with timeout and caching
-func (s *forecast) GetRandomForecast(ctx context.Context) (string, error) {
+// GetPoint returns the NWS point metadata of the query location, or a random one when it is nil
+func (s *forecast) GetPoint(ctx context.Context, query PointQuery) (*model.Point, error) {
 	ctx, cancel := context.WithTimeout(ctx, s.Timeout)
 	defer cancel()
 
-	location, err := s.LocationClient.GetRandomLocation(ctx)
+	location, err := s.resolveLocation(ctx, query.Location, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	point, err := s.getPointMetadata(ctx, location.Latitude, location.Longitude)
+	if err != nil {
+		return nil, fmt.Errorf("Stage 2 - GetPointMetadata error: %w", err)
+	}
+	return &model.Point{
+		Location: s.describeLocation(ctx, *location, point, s.units),
+		Metadata: point,
+	}, nil
+}
+
+// getAlertsOrNone returns the active alerts to include in a forecast. Alerts are supplementary,
+// so failing to fetch them is logged instead of failing the forecast.
+func (s *forecast) getAlertsOrNone(ctx context.Context, location *model.Location) []model.Alert {
+	if s.AlertsClient == nil {
+		return nil
+	}
+	activeAlerts, err := s.getAlerts(ctx, location.Latitude, location.Longitude)
+	if err != nil {
+		log.Printf("Failed to get active alerts: %v", err)
+		return nil
+	}
+	return activeAlerts
+}
+
+// getAlerts retrieves the active alerts, utilizing the cache if available
+func (s *forecast) getAlerts(ctx context.Context, lat, lng float64) ([]model.Alert, error) {
+	cacheKey := fmt.Sprintf("%.4f,%.4f", lat, lng)
+
+	cachedAlerts, found, err := s.alertsCache.Get(ctx, cacheKey)
+	if err != nil {
+		log.Printf("Cache error: %v", err)
+	}
+	if found {
+		return cachedAlerts, nil
+	}
+
+	activeAlerts, err := s.AlertsClient.GetActiveAlerts(ctx, lat, lng)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.alertsCache.Set(ctx, cacheKey, activeAlerts, s.alertsTTL); err != nil {
+		log.Printf("Cache error: %v", err)
+	}
+	return activeAlerts, nil
+}
+
+// getObservationOrNone returns the latest usable observation of the stations nearest to a location,
+// trying them nearest first. Like alerts, observations are supplementary, so failures are logged
+// and nil is returned.
+func (s *forecast) getObservationOrNone(ctx context.Context, observationStationsURL string) *model.Observation {
+	if s.ObservationsClient == nil || observationStationsURL == "" {
+		return nil
+	}
+	stations, err := s.getStations(ctx, observationStationsURL)
+	if err != nil {
+		log.Printf("Failed to get observation stations: %v", err)
+		return nil
+	}
+
+	for i, station := range stations {
+		if i == s.maxStations {
+			break
+		}
+		observation, err := s.getLatestObservation(ctx, station.ID)
+		if err != nil {
+			log.Printf("Failed to get latest observation of %s: %v", station.ID, err)
+			continue
+		}
+		if observation.Temperature.Value == nil {
+			// the station did not report its measurements, try the next-nearest one
+			continue
+		}
+		observation.Station = station
+		return &observation
+	}
+	return nil
+}
+
+// getStations retrieves the observation stations nearest first, utilizing the cache if available
+func (s *forecast) getStations(ctx context.Context, observationStationsURL string) ([]model.Station, error) {
+	cachedStations, found, err := s.stationsCache.Get(ctx, observationStationsURL)
+	if err != nil {
+		log.Printf("Cache error: %v", err)
+	}
+	if found {
+		return cachedStations, nil
+	}
+
+	stations, err := s.ObservationsClient.GetStations(ctx, observationStationsURL)
 	if err != nil {
-		return "", fmt.Errorf("Stage 1 - FetchLocation error: %w", err)
+		return nil, err
+	}
+
+	if err := s.stationsCache.Set(ctx, observationStationsURL, stations, 0); err != nil {
+		log.Printf("Cache error: %v", err)
 	}
+	return stations, nil
+}
 
-	forecastURL, err := s.getForecastURL(ctx, location.Latitude, location.Longitude)
+// getLatestObservation retrieves the latest observation of a station, utilizing the cache if available.
+// Observations with missing values are cached too so that the station is not asked again until they expire.
+func (s *forecast) getLatestObservation(ctx context.Context, stationID string) (model.Observation, error) {
+	cachedObservation, found, err := s.observationsCache.Get(ctx, stationID)
 	if err != nil {
-		return "", fmt.Errorf("Stage 2 - GetForecastURL error: %w", err)
+		log.Printf("Cache error: %v", err)
+	}
+	if found {
+		return cachedObservation, nil
 	}
 
-	detailedForcast, err := s.getCurrentDetailedForcast(ctx, forecastURL)
+	observation, err := s.ObservationsClient.GetLatestObservation(ctx, stationID)
 	if err != nil {
-		return "", fmt.Errorf("Stage 3 - GetForecastResponse error: %w", err)
+		return model.Observation{}, err
+	}
+
+	if err := s.observationsCache.Set(ctx, stationID, *observation, s.observationsTTL); err != nil {
+		log.Printf("Cache error: %v", err)
+	}
+	return *observation, nil
+}
+
+// resolveUnits returns units, or the service default when it is empty
+func (s *forecast) resolveUnits(units model.Units) model.Units {
+	if units == "" {
+		return s.units
+	}
+	return units
+}
+
+// resolveLocation returns location, or else the random one in region picked with seed
+func (s *forecast) resolveLocation(ctx context.Context, location *model.Location, seed *int64, region *Region) (*model.Location, error) {
+	if location != nil {
+		return location, nil
 	}
+	return s.seededLocation(ctx, seed, region)
+}
 
-	return fmt.Sprintf("The weather in %s is: %s", location.Name, detailedForcast), nil
+// filterPeriods keeps the periods that have not ended yet and match the query filters
+func filterPeriods(periods []model.ForcastPeriod, query PeriodsQuery, now time.Time) []model.ForcastPeriod {
+	filtered := []model.ForcastPeriod{}
+	for _, period := range periods {
+		if query.Limit > 0 && len(filtered) == query.Limit {
+			break
+		}
+		if !period.EndTime.After(now) {
+			continue
+		}
+		if !query.From.IsZero() && !period.EndTime.After(query.From) {
+			continue
+		}
+		if !query.To.IsZero() && !period.StartTime.Before(query.To) {
+			continue
+		}
+		if query.DaytimeOnly && !period.IsDaytime {
+			continue
+		}
+		filtered = append(filtered, period)
+	}
+	return filtered
 }
 
-// getForecastURL retrieves the forecast URL, utilizing the cache if available
-func (s *forecast) getForecastURL(ctx context.Context, lat, lng float64) (string, error) {
+// getPointMetadata retrieves the point metadata, utilizing the cache if available
+func (s *forecast) getPointMetadata(ctx context.Context, lat, lng float64) (model.PointMetadata, error) {
+	// Normalize the coordinates to the NWS precision, nearby coordinates resolving to the same point
+	lat, lng = model.RoundCoordinate(lat), model.RoundCoordinate(lng)
+
 	// Define cache key
-	cacheKey := fmt.Sprintf("%f,%f", lat, lng)
+	cacheKey := fmt.Sprintf("%.4f,%.4f", lat, lng)
 
-	// Check if forecast URL is cached
-	if cachedURL, found := s.forecastURLCache.Get(cacheKey); found {
-		return cachedURL, nil
+	// Check if point metadata is cached
+	cachedPoint, found, err := s.pointsCache.Get(ctx, cacheKey)
+	if err != nil {
+		log.Printf("Cache error: %v", err)
+	}
+	if found {
+		return cachedPoint, nil
 	}
 
-	// Fetch forecast URL from external API
-	forecastURL, err := s.ForcastClient.GetForecastURL(ctx, lat, lng)
+	// Fetch point metadata from external API
+	point, err := s.ForcastClient.GetPointMetadata(ctx, lat, lng)
 	if err != nil {
-		return "", err
+		return model.PointMetadata{}, err
+	}
+
+	// Store the fetched point metadata in cache
+	if err := s.pointsCache.Set(ctx, cacheKey, *point, 0); err != nil {
+		log.Printf("Cache error: %v", err)
 	}
 
-	// Store the fetched forecast URL in cache
-	s.forecastURLCache.Add(cacheKey, forecastURL)
+	return *point, nil
+}
 
-	return forecastURL, nil
+// CheckCoverage returns an error when NWS is the only provider of the location and has no point metadata
+// for it, which is then resolved through the points cache. The other locations are forecast by a provider
+// covering them, or fail over to one.
+func (s *forecast) CheckCoverage(ctx context.Context, location model.Location) error {
+	if !s.nwsOnly(location) {
+		return nil
+	}
+	_, err := s.getPointMetadata(ctx, location.Latitude, location.Longitude)
+	return err
 }
 
-// getCurrentDetailedForcast retrieves the current detailed forecast, utilizing the cache if available
-func (s *forecast) getCurrentDetailedForcast(ctx context.Context, forecastURL string) (string, error) {
+// getForecast retrieves the standard or hourly forecast, utilizing the cache while it still has a current period
+func (s *forecast) getForecast(ctx context.Context, request forecastRequest) (model.Forecast, error) {
 
 	// Define cache key
-	cacheKey := forecastURL
+	cacheKey := request.key
 
 	// Check if forecast response is cached
-	if cachedResp, found := s.forecastPeriodsCache.Get(cacheKey); found {
-		current := time.Now()
-		for _, period := range cachedResp {
-			if period.StartTime.Before(current) && period.EndTime.After(current) {
-				return period.DetailedForecast, nil
+	cachedResp, found, err := s.forecastPeriodsCache.Get(ctx, cacheKey)
+	if err != nil {
+		log.Printf("Cache error: %v", err)
+	}
+	if found {
+		current := s.now()
+		if _, ok := findCurrentPeriod(cachedResp.Periods, current, s.periodTolerance); ok {
+			if s.refreshes != nil {
+				s.refreshes.touch(request, cachedResp, current)
 			}
+			return cachedResp, nil
 		}
 	}
 
 	// Fetch forecast response from external API
-	forecastResponse, err := s.ForcastClient.GetForecastPeriods(ctx, forecastURL)
-	if err != nil {
-		return "", err
+	return s.fetchForecast(ctx, request)
+}
+
+// fetchForecast fetches the standard or hourly forecast from the API and caches it until its last period ends.
+// Concurrent fetches of the same forecast share a single API call.
+func (s *forecast) fetchForecast(ctx context.Context, request forecastRequest) (model.Forecast, error) {
+	fetch := s.ForcastClient.GetForecastPeriods
+	if request.kind == hourlyForecast {
+		fetch = s.ForcastClient.GetHourlyForecastPeriods
 	}
 
-	// Find the current detailed forecast
-	current := time.Now()
-	for _, period := range forecastResponse {
-		if period.StartTime.Before(current) && period.EndTime.After(current) {
-			// Store the fetched forecast response in cache
-			s.forecastPeriodsCache.Add(cacheKey, forecastResponse)
-			return period.DetailedForecast, nil
+	return s.forecastFetches.Do(ctx, request.key, func(ctx context.Context) (model.Forecast, error) {
+		forecastResponse, err := fetch(ctx, request.url)
+		if err != nil {
+			return model.Forecast{}, err
+		}
+
+		current := s.now()
+		if ttl := periodsTTL(forecastResponse.Periods, current); ttl > 0 {
+			if err := s.forecastPeriodsCache.Set(ctx, request.key, *forecastResponse, ttl); err != nil {
+				log.Printf("Cache error: %v", err)
+			}
+			if s.refreshes != nil {
+				s.refreshes.schedule(request, *forecastResponse, current)
+			}
+		}
+		return *forecastResponse, nil
+	})
+}
+
+// RefreshDue refreshes the served forecasts whose refresh is due and returns when the next one is due,
+// zero when background refresh is disabled or nothing is scheduled
+func (s *forecast) RefreshDue(ctx context.Context) time.Time {
+	if s.refreshes == nil {
+		return time.Time{}
+	}
+	for _, due := range s.refreshes.due(s.now()) {
+		fetchCtx, cancel := context.WithTimeout(ctx, s.Timeout)
+		if _, err := s.fetchForecast(fetchCtx, due); err != nil && ctx.Err() == nil {
+			log.Printf("Forecast refresh of %s failed: %v", due.key, err)
 		}
+		cancel()
 	}
+	return s.refreshes.next()
+}
+
+// Warm refreshes the cached forecast of the location from the API of its provider, failing over
+// like the forecasts served for it. It returns the end of the current period, when the cached answer changes.
+func (s *forecast) Warm(ctx context.Context, lat, lng float64) (time.Time, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.Timeout)
+	defer cancel()
+
+	location := model.Location{Latitude: lat, Longitude: lng}
+	result, err := s.failover(ctx, location, standardForecast, func(ctx context.Context, provider forecastProvider) (providerForecast, error) {
+		return provider.refresh(ctx, location, s.units)
+	})
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to warm forecast: %w", err)
+	}
+
+	period, ok := findCurrentPeriod(result.Forecast.Periods, s.now(), s.periodTolerance)
+	if !ok {
+		return time.Time{}, errNoCurrentForecast
+	}
+	return period.EndTime, nil
+}
 
-	return "", errors.New("no current detailed forecast found")
+// findCurrentPeriod returns the period covering now, including its start but not its end.
+// When none does, it returns the nearest upcoming period starting within tolerance.
+func findCurrentPeriod(periods []model.ForcastPeriod, now time.Time, tolerance time.Duration) (model.ForcastPeriod, bool) {
+	var upcoming model.ForcastPeriod
+	found := false
+	for _, period := range periods {
+		if !now.Before(period.StartTime) && now.Before(period.EndTime) {
+			return period, true
+		}
+		untilStart := period.StartTime.Sub(now)
+		if untilStart > 0 && untilStart <= tolerance && (!found || period.StartTime.Before(upcoming.StartTime)) {
+			upcoming = period
+			found = true
+		}
+	}
+	return upcoming, found
+}
 
+// periodsTTL returns how long the periods stay useful, until the last one ends
+func periodsTTL(periods []model.ForcastPeriod, now time.Time) time.Duration {
+	var ttl time.Duration
+	for _, period := range periods {
+		if remaining := period.EndTime.Sub(now); remaining > ttl {
+			ttl = remaining
+		}
+	}
+	return ttl
 }
@@ -1,4 +1,3 @@
-
 package service
 
 import (
@@ -7,10 +6,10 @@ import (
 	"testing"
 	"time"
 
-	"go.uber.org/mock/gomock"
-	"github.com/stretchr/testify/assert"
 	"github.com/softstone1/fl/internal/client"
 	"github.com/softstone1/fl/internal/model"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
 )
 
 func TestGetRandomForecast_Success(t *testing.T) {
@@ -30,25 +29,28 @@ func TestGetRandomForecast_Success(t *testing.T) {
 			Longitude: 1.0,
 		}, nil,
 	)
-	mockForecast.EXPECT().GetForecastURL(gomock.Any(), gomock.Any(), gomock.Any()).Return(
-		"http://test.url", nil,
+	mockForecast.EXPECT().GetPointMetadata(gomock.Any(), gomock.Any(), gomock.Any()).Return(
+		&model.PointMetadata{Forecast: "http://test.url"}, nil,
 	)
 	mockForecast.EXPECT().GetForecastPeriods(gomock.Any(), gomock.Any()).Return(
-		[]model.ForcastPeriod{
-			{
-				StartTime:       time.Now().Add(-time.Hour),
-				EndTime:         time.Now().Add(time.Hour),
-				DetailedForecast: "Sunny",
+		&model.Forecast{
+			Periods: []model.ForcastPeriod{
+				{
+					StartTime:        time.Now().Add(-time.Hour),
+					EndTime:          time.Now().Add(time.Hour),
+					DetailedForecast: "Sunny",
+				},
 			},
 		}, nil,
 	)
 
 	// Execute
-	resp, err := svc.GetRandomForecast(context.Background())
+	resp, err := svc.GetRandomForecast(context.Background(), CurrentQuery{})
 
 	// Verify
 	assert.NoError(t, err)
-	assert.Contains(t, resp, "The weather in Test Location is: Sunny")
+	assert.Equal(t, "Sunny", resp.Period.DetailedForecast)
+	assert.Equal(t, "Test Location", resp.Location.Name)
 }
 
 func TestGetRandomForecast_LocationError(t *testing.T) {
@@ -67,11 +69,11 @@ func TestGetRandomForecast_LocationError(t *testing.T) {
 	)
 
 	// Execute
-	resp, err := svc.GetRandomForecast(context.Background())
+	resp, err := svc.GetRandomForecast(context.Background(), CurrentQuery{})
 
 	// Verify
 	assert.Error(t, err)
-	assert.Empty(t, resp)
+	assert.Nil(t, resp)
 }
 
 func TestGetRandomForecast_NoCurrentDetailedForecast(t *testing.T) {
@@ -90,16 +92,172 @@ func TestGetRandomForecast_NoCurrentDetailedForecast(t *testing.T) {
 			Longitude: 1.0,
 		}, nil,
 	)
-	mockForecast.EXPECT().GetForecastURL(gomock.Any(), gomock.Any(), gomock.Any()).Return(
-		"http://test.url", nil,
+	mockForecast.EXPECT().GetPointMetadata(gomock.Any(), gomock.Any(), gomock.Any()).Return(
+		&model.PointMetadata{Forecast: "http://test.url"}, nil,
 	)
 	mockForecast.EXPECT().GetForecastPeriods(gomock.Any(), gomock.Any()).Return(
-		[]model.ForcastPeriod{}, nil,
+		&model.Forecast{}, nil,
 	)
 
-	resp, err := svc.GetRandomForecast(context.Background())
+	resp, err := svc.GetRandomForecast(context.Background(), CurrentQuery{})
 
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "no current detailed forecast found")
-	assert.Empty(t, resp)
-}
\ No newline at end of file
+	assert.Nil(t, resp)
+}
+
+func TestGetRandomForecast_PeriodSelection(t *testing.T) {
+	now := time.Date(2025, 1, 1, 18, 0, 0, 0, time.UTC)
+	periods := []model.ForcastPeriod{
+		{StartTime: now.Add(-12 * time.Hour), EndTime: now, DetailedForecast: "Sunny"},
+		{StartTime: now, EndTime: now.Add(12 * time.Hour), DetailedForecast: "Clear"},
+		{StartTime: now.Add(12 * time.Hour), EndTime: now.Add(24 * time.Hour), DetailedForecast: "Cloudy"},
+	}
+
+	tests := []struct {
+		name      string
+		periods   []model.ForcastPeriod
+		tolerance time.Duration
+		expected  string
+		expectErr bool
+	}{
+		{
+			name:     "Exact start selects the starting period",
+			periods:  periods,
+			expected: "Clear",
+		},
+		{
+			name:      "Upcoming period within tolerance",
+			periods:   periods[2:],
+			tolerance: 12 * time.Hour,
+			expected:  "Cloudy",
+		},
+		{
+			name:      "Nearest upcoming period wins",
+			periods:   []model.ForcastPeriod{periods[2], {StartTime: now.Add(time.Minute), EndTime: now.Add(time.Hour), DetailedForecast: "Windy"}},
+			tolerance: 12 * time.Hour,
+			expected:  "Windy",
+		},
+		{
+			name:      "Upcoming period beyond tolerance",
+			periods:   periods[2:],
+			tolerance: time.Hour,
+			expectErr: true,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockLocation := client.NewMockLocation(ctrl)
+			mockForecast := client.NewMockForecast(ctrl)
+
+			svc, _ := NewForecast(mockLocation, mockForecast, time.Second, 10,
+				WithClock(func() time.Time { return now }),
+				WithPeriodTolerance(tc.tolerance),
+			)
+
+			mockLocation.EXPECT().GetRandomLocation(gomock.Any()).Return(
+				&model.Location{Name: "Test Location", Latitude: 1.0, Longitude: 1.0}, nil,
+			)
+			mockForecast.EXPECT().GetPointMetadata(gomock.Any(), gomock.Any(), gomock.Any()).Return(
+				&model.PointMetadata{Forecast: "http://test.url"}, nil,
+			)
+			mockForecast.EXPECT().GetForecastPeriods(gomock.Any(), gomock.Any()).Return(
+				&model.Forecast{Periods: tc.periods}, nil,
+			)
+
+			resp, err := svc.GetRandomForecast(context.Background(), CurrentQuery{})
+
+			if tc.expectErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expected, resp.Period.DetailedForecast)
+		})
+	}
+}
+
+func TestFilterPeriods(t *testing.T) {
+	now := time.Date(2025, 1, 1, 20, 0, 0, 0, time.UTC)
+	var periods []model.ForcastPeriod
+	for i := 0; i < 6; i++ {
+		start := time.Date(2025, 1, 1, 6, 0, 0, 0, time.UTC).Add(time.Duration(i) * 12 * time.Hour)
+		periods = append(periods, model.ForcastPeriod{
+			Number:    i + 1,
+			StartTime: start,
+			EndTime:   start.Add(12 * time.Hour),
+			IsDaytime: i%2 == 0,
+		})
+	}
+
+	numbers := func(periods []model.ForcastPeriod) []int {
+		result := []int{}
+		for _, period := range periods {
+			result = append(result, period.Number)
+		}
+		return result
+	}
+
+	tests := []struct {
+		name     string
+		query    PeriodsQuery
+		expected []int
+	}{
+		{name: "Ended periods are dropped", expected: []int{2, 3, 4, 5, 6}},
+		{name: "Limit", query: PeriodsQuery{Limit: 2}, expected: []int{2, 3}},
+		{name: "Daytime only", query: PeriodsQuery{DaytimeOnly: true}, expected: []int{3, 5}},
+		{
+			name:     "From and to keep overlapping periods",
+			query:    PeriodsQuery{From: now.Add(12 * time.Hour), To: now.Add(24 * time.Hour)},
+			expected: []int{3, 4},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, numbers(filterPeriods(periods, tc.query, now)))
+		})
+	}
+}
+
+func TestGetHourlyForecast(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockLocation := client.NewMockLocation(ctrl)
+	mockForecast := client.NewMockForecast(ctrl)
+
+	now := time.Date(2025, 1, 1, 12, 30, 0, 0, time.UTC)
+	svc, _ := NewForecast(mockLocation, mockForecast, time.Second, 10,
+		WithClock(func() time.Time { return now }),
+		WithHourlyHorizon(3*time.Hour),
+	)
+
+	var hours []model.ForcastPeriod
+	for i := 0; i < 48; i++ {
+		start := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC).Add(time.Duration(i) * time.Hour)
+		hours = append(hours, model.ForcastPeriod{Number: i + 1, StartTime: start, EndTime: start.Add(time.Hour)})
+	}
+
+	location := &model.Location{Latitude: 1.0, Longitude: 1.0}
+	mockForecast.EXPECT().GetPointMetadata(gomock.Any(), 1.0, 1.0).Return(
+		&model.PointMetadata{Forecast: "http://test.url", ForecastHourly: "http://test.url/hourly"}, nil,
+	)
+	// the hourly forecast is fetched once and then served from the cache
+	mockForecast.EXPECT().GetHourlyForecastPeriods(gomock.Any(), "http://test.url/hourly").Return(
+		&model.Forecast{Periods: hours}, nil,
+	)
+
+	resp, err := svc.GetHourlyForecast(context.Background(), HourlyQuery{Location: location})
+	assert.NoError(t, err)
+	assert.Len(t, resp.Periods, 4)
+	assert.Equal(t, 1, resp.Periods[0].Number)
+
+	resp, err = svc.GetHourlyForecast(context.Background(), HourlyQuery{Location: location, Horizon: 12 * time.Hour})
+	assert.NoError(t, err)
+	assert.Len(t, resp.Periods, 13)
+}
@@ -0,0 +1,181 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/softstone1/fl/internal/model"
+)
+
+// DefaultGridLayers are the gridpoint layers returned when the query selects none
+var DefaultGridLayers = []string{
+	"temperature",
+	"dewpoint",
+	"relativeHumidity",
+	"skyCover",
+	"windSpeed",
+	"windGust",
+	"probabilityOfPrecipitation",
+	"quantitativePrecipitation",
+}
+
+// accumulatedGridLayers are the layers whose values are amounts over their interval rather than rates or levels.
+// Their resampled hourly values are the share of the amount falling in each hour.
+var accumulatedGridLayers = map[string]bool{
+	"quantitativePrecipitation": true,
+	"snowfallAmount":            true,
+	"iceAccumulation":           true,
+}
+
+// ErrUnknownGridLayer is returned by GetGridpoints when a query layer is not provided by NWS
+var ErrUnknownGridLayer = errors.New("unknown gridpoint layer")
+
+// GridpointsQuery selects the gridpoint layers returned by GetGridpoints
+type GridpointsQuery struct {
+	// Location of the gridpoint, a random one when nil
+	Location *model.Location
+	// Seed replays the random location picked with it when Location is nil, a new one is drawn when nil
+	Seed *int64
+	// Region restricts the random location when Location is nil, the configured filter region when nil
+	Region *Region
+	// Layers to return, DefaultGridLayers when empty
+	Layers []string
+	// Horizon is how far ahead the hourly series go, the hourly forecast default when zero
+	Horizon time.Duration
+	// Units of the values, the service default when empty
+	Units model.Units
+	// TimeZone of the returned times, the service default or else the location time zone when nil
+	TimeZone *time.Location
+}
+
+// GetGridpoints returns the query layers of the raw NWS gridpoint data at the query location, resampled hourly
+func (s *forecast) GetGridpoints(ctx context.Context, query GridpointsQuery) (*model.GridSeriesData, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.Timeout)
+	defer cancel()
+
+	location, err := s.resolveLocation(ctx, query.Location, query.Seed, query.Region)
+	if err != nil {
+		return nil, err
+	}
+
+	point, err := s.getPointMetadata(ctx, location.Latitude, location.Longitude)
+	if err != nil {
+		return nil, fmt.Errorf("Stage 2 - GetPointMetadata error: %w", err)
+	}
+
+	gridData, err := s.getGridData(ctx, point.ForecastGridData)
+	if err != nil {
+		return nil, fmt.Errorf("Stage 3 - GetGridData error: %w", err)
+	}
+
+	layers := query.Layers
+	if len(layers) == 0 {
+		layers = DefaultGridLayers
+	}
+	horizon := query.Horizon
+	if horizon <= 0 {
+		horizon = s.hourlyHorizon
+	}
+	units := s.resolveUnits(query.Units)
+	zone := s.resolveZone(query.TimeZone, point.TimeZone)
+
+	start := startOfHour(s.now(), zone)
+	times := make([]time.Time, 0, int(horizon/time.Hour))
+	for t := start; t.Before(start.Add(horizon)); t = t.Add(time.Hour) {
+		times = append(times, t)
+	}
+
+	series := make([]model.GridSeries, 0, len(layers))
+	for _, name := range layers {
+		layer, ok := gridData.Layers[name]
+		if !ok {
+			return nil, fmt.Errorf("%w: %q, available layers: %s", ErrUnknownGridLayer, name, strings.Join(gridLayerNames(gridData), ","))
+		}
+		series = append(series, convertGridSeries(resampleHourly(name, layer, times), units))
+	}
+
+	return &model.GridSeriesData{
+		Location:   s.describeLocation(ctx, *location, point, units),
+		UpdateTime: timeIn(gridData.UpdateTime, zone),
+		Times:      times,
+		Layers:     series,
+	}, nil
+}
+
+// gridLayerNames returns the sorted names of the layers in the grid data
+func gridLayerNames(gridData model.GridData) []string {
+	names := make([]string, 0, len(gridData.Layers))
+	for name := range gridData.Layers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// getGridData retrieves the raw gridpoint data, utilizing the cache if available
+func (s *forecast) getGridData(ctx context.Context, gridDataURL string) (model.GridData, error) {
+	if gridDataURL == "" {
+		return model.GridData{}, errors.New("no grid data for the location")
+	}
+
+	cachedGridData, found, err := s.gridDataCache.Get(ctx, gridDataURL)
+	if err != nil {
+		log.Printf("Cache error: %v", err)
+	}
+	if found {
+		return cachedGridData, nil
+	}
+
+	gridData, err := s.ForcastClient.GetGridData(ctx, gridDataURL)
+	if err != nil {
+		return model.GridData{}, err
+	}
+
+	if err := s.gridDataCache.Set(ctx, gridDataURL, *gridData, s.gridDataTTL); err != nil {
+		log.Printf("Cache error: %v", err)
+	}
+	return *gridData, nil
+}
+
+// resampleHourly returns the value of the layer at each hour of times. The value of an accumulated layer
+// is spread evenly over its interval.
+func resampleHourly(name string, layer model.GridLayer, times []time.Time) model.GridSeries {
+	series := model.GridSeries{
+		Name:     name,
+		UnitCode: layer.UnitCode,
+		Values:   make([]*float64, len(times)),
+	}
+	for i, t := range times {
+		for _, value := range layer.Values {
+			if value.Value == nil || t.Before(value.StartTime) || !t.Before(value.StartTime.Add(value.Duration)) {
+				continue
+			}
+			hourly := *value.Value
+			if accumulatedGridLayers[name] && value.Duration > time.Hour {
+				hourly = round(hourly*float64(time.Hour)/float64(value.Duration), 2)
+			}
+			series.Values[i] = &hourly
+			break
+		}
+	}
+	return series
+}
+
+// convertGridSeries converts the series values to units
+func convertGridSeries(series model.GridSeries, units model.Units) model.GridSeries {
+	unitCode := series.UnitCode
+	for i, value := range series.Values {
+		if value == nil {
+			continue
+		}
+		converted := convertQuantity(model.QuantitativeValue{Value: value, UnitCode: series.UnitCode}, units)
+		series.Values[i], unitCode = converted.Value, converted.UnitCode
+	}
+	series.UnitCode = unitCode
+	return series
+}
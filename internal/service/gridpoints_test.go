@@ -0,0 +1,100 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/softstone1/fl/internal/client"
+	"github.com/softstone1/fl/internal/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestGetGridpoints(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockForecast := client.NewMockForecast(ctrl)
+	now := time.Date(2025, 1, 1, 12, 30, 0, 0, time.UTC)
+	svc, _ := NewForecast(nil, mockForecast, time.Second, 10, WithClock(func() time.Time { return now }))
+
+	value := func(v float64) *float64 { return &v }
+	start := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	gridData := &model.GridData{
+		UpdateTime: start,
+		Layers: map[string]model.GridLayer{
+			"temperature": {
+				UnitCode: "wmoUnit:degC",
+				Values: []model.GridValue{
+					{StartTime: start, Duration: 2 * time.Hour, Value: value(0)},
+					{StartTime: start.Add(2 * time.Hour), Duration: time.Hour, Value: value(-5)},
+				},
+			},
+			"quantitativePrecipitation": {
+				UnitCode: "wmoUnit:mm",
+				Values: []model.GridValue{
+					{StartTime: start.Add(-2 * time.Hour), Duration: 6 * time.Hour, Value: value(30)},
+				},
+			},
+		},
+	}
+	mockForecast.EXPECT().GetPointMetadata(gomock.Any(), 1.0, 1.0).Return(
+		&model.PointMetadata{ForecastGridData: "http://grid.url"}, nil,
+	)
+	// the grid data is cached
+	mockForecast.EXPECT().GetGridData(gomock.Any(), "http://grid.url").Return(gridData, nil)
+
+	location := &model.Location{Latitude: 1.0, Longitude: 1.0}
+	resp, err := svc.GetGridpoints(context.Background(), GridpointsQuery{
+		Location: location,
+		Layers:   []string{"temperature", "quantitativePrecipitation"},
+		Horizon:  4 * time.Hour,
+		Units:    model.UnitsSI,
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, []time.Time{start, start.Add(time.Hour), start.Add(2 * time.Hour), start.Add(3 * time.Hour)}, resp.Times)
+	require.Len(t, resp.Layers, 2)
+	assert.Equal(t, model.GridSeries{
+		Name:     "temperature",
+		UnitCode: "wmoUnit:degC",
+		Values:   []*float64{value(0), value(0), value(-5), nil},
+	}, resp.Layers[0])
+	// accumulated amounts are spread over their interval
+	assert.Equal(t, model.GridSeries{
+		Name:     "quantitativePrecipitation",
+		UnitCode: "wmoUnit:mm",
+		Values:   []*float64{value(5), value(5), value(5), value(5)},
+	}, resp.Layers[1])
+
+	resp, err = svc.GetGridpoints(context.Background(), GridpointsQuery{
+		Location: location,
+		Layers:   []string{"temperature"},
+		Horizon:  time.Hour,
+		Units:    model.UnitsUS,
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, "wmoUnit:degF", resp.Layers[0].UnitCode)
+	assert.Equal(t, []*float64{value(32)}, resp.Layers[0].Values)
+
+	// the hours start at the whole hours of the time zone, half past in UTC for India
+	kolkata, err := time.LoadLocation("Asia/Kolkata")
+	require.NoError(t, err)
+	resp, err = svc.GetGridpoints(context.Background(), GridpointsQuery{
+		Location: location,
+		Layers:   []string{"temperature"},
+		Horizon:  2 * time.Hour,
+		TimeZone: kolkata,
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, "2025-01-01T18:00:00+05:30", resp.Times[0].Format(time.RFC3339))
+	assert.Equal(t, []*float64{value(32), value(32)}, resp.Layers[0].Values)
+
+	_, err = svc.GetGridpoints(context.Background(), GridpointsQuery{Location: location, Layers: []string{"pollen"}})
+
+	assert.ErrorIs(t, err, ErrUnknownGridLayer)
+}
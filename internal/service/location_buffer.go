@@ -0,0 +1,176 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/softstone1/fl/internal/client"
+	"github.com/softstone1/fl/internal/model"
+)
+
+// locationRetryDelay is how long the buffer waits before fetching again after the location source failed
+const locationRetryDelay = 5 * time.Second
+
+// LocationBufferConfiguration holds the settings for the LocationBuffer
+type LocationBufferConfiguration struct {
+	// Size is the number of random locations fetched ahead
+	Size int
+	// LowWater is the number of buffered locations below which the buffer is refilled
+	LowWater int
+}
+
+// CoverageChecker checks that a location is covered by its forecast providers
+type CoverageChecker interface {
+	// CheckCoverage returns an NWS 404 APIError when the location is outside of the coverage
+	CheckCoverage(ctx context.Context, location model.Location) error
+}
+
+// LocationBuffer serves random locations fetched ahead in the background, so that a request does not wait
+// for the location source. It calls the source directly while it is empty.
+type LocationBuffer struct {
+	source    client.Location
+	coverage  CoverageChecker
+	config    LocationBufferConfiguration
+	locations chan model.Location
+	refill    chan struct{}
+	stopOnce  sync.Once
+	stop      chan struct{}
+	done      chan struct{}
+}
+
+// make sure LocationBuffer implements the client Location interface
+var _ client.Location = (*LocationBuffer)(nil)
+
+// NewLocationBuffer returns a buffer of the random locations of source. When coverage is not nil,
+// only the locations it covers are buffered.
+func NewLocationBuffer(source client.Location, coverage CoverageChecker, config LocationBufferConfiguration) *LocationBuffer {
+	return &LocationBuffer{
+		source:    source,
+		coverage:  coverage,
+		config:    config,
+		locations: make(chan model.Location, config.Size),
+		refill:    make(chan struct{}, 1),
+		stop:      make(chan struct{}),
+		done:      make(chan struct{}),
+	}
+}
+
+// SetCoverage buffers only the locations coverage covers. It must be called before Start, e.g. with
+// the service the buffer is the location source of, which caches the point metadata it resolves.
+func (b *LocationBuffer) SetCoverage(coverage CoverageChecker) {
+	b.coverage = coverage
+}
+
+// Start fills the buffer in the background
+func (b *LocationBuffer) Start() {
+	go func() {
+		defer close(b.done)
+		b.run()
+	}()
+}
+
+// Stop ends the background filling
+func (b *LocationBuffer) Stop(ctx context.Context) {
+	b.stopOnce.Do(func() {
+		close(b.stop)
+	})
+	select {
+	case <-b.done:
+	case <-ctx.Done():
+		log.Printf("Location buffer did not stop: %v", ctx.Err())
+	}
+}
+
+// GetRandomLocation returns a buffered location, or fetches one from the source when the buffer is empty
+func (b *LocationBuffer) GetRandomLocation(ctx context.Context) (*model.Location, error) {
+	select {
+	case location := <-b.locations:
+		if len(b.locations) < b.config.LowWater {
+			b.requestRefill()
+		}
+		return &location, nil
+	default:
+		b.requestRefill()
+		return b.source.GetRandomLocation(ctx)
+	}
+}
+
+// requestRefill wakes the background filling up, unless it is already pending
+func (b *LocationBuffer) requestRefill() {
+	select {
+	case b.refill <- struct{}{}:
+	default:
+	}
+}
+
+func (b *LocationBuffer) run() {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		select {
+		case <-b.stop:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	for {
+		if err := b.fill(ctx); err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			log.Printf("Location buffer refill failed: %v", err)
+			timer := time.NewTimer(locationRetryDelay)
+			select {
+			case <-timer.C:
+			case <-ctx.Done():
+				timer.Stop()
+				return
+			}
+			continue
+		}
+
+		select {
+		case <-b.refill:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// fill fetches locations until the buffer is full. It gives up after as many locations outside
+// the coverage in a row as the buffer holds. Only the locations NWS answers 404 for are outside
+// of the coverage, any other error stops the filling until it is retried.
+func (b *LocationBuffer) fill(ctx context.Context) error {
+	rejected := 0
+	for len(b.locations) < b.config.Size {
+		location, err := b.source.GetRandomLocation(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to fetch location: %w", err)
+		}
+		if b.coverage != nil {
+			if err := b.coverage.CheckCoverage(ctx, *location); err != nil {
+				if ctx.Err() != nil {
+					return ctx.Err()
+				}
+				var apiErr *client.APIError
+				if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusNotFound {
+					return fmt.Errorf("failed to validate location: %w", err)
+				}
+				rejected++
+				if rejected >= b.config.Size {
+					return fmt.Errorf("no location of the last %d inside the coverage: %w", rejected, err)
+				}
+				continue
+			}
+		}
+		rejected = 0
+		b.locations <- *location
+	}
+	return nil
+}
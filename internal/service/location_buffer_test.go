@@ -0,0 +1,154 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/softstone1/fl/internal/client"
+	"github.com/softstone1/fl/internal/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+// countingLocations returns locations numbered by the count of calls so far
+type countingLocations struct {
+	mu    sync.Mutex
+	calls int
+}
+
+func (c *countingLocations) GetRandomLocation(_ context.Context) (*model.Location, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.calls++
+	return &model.Location{Latitude: float64(c.calls)}, nil
+}
+
+func (c *countingLocations) count() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.calls
+}
+
+func stopLocationBuffer(t *testing.T, buffer *LocationBuffer) {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	buffer.Stop(ctx)
+	assert.NoError(t, ctx.Err())
+}
+
+func TestLocationBuffer_FillsAndRefillsBelowLowWater(t *testing.T) {
+	source := &countingLocations{}
+	buffer := NewLocationBuffer(source, nil, LocationBufferConfiguration{Size: 4, LowWater: 2})
+	buffer.Start()
+	defer stopLocationBuffer(t, buffer)
+
+	assert.Eventually(t, func() bool { return len(buffer.locations) == 4 }, time.Second, time.Millisecond)
+
+	// the buffered locations are served in the order they were fetched, without calling the source
+	for i := 1; i <= 2; i++ {
+		location, err := buffer.GetRandomLocation(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, float64(i), location.Latitude)
+	}
+	assert.Equal(t, 4, source.count())
+
+	// serving the third one leaves less than the low-water mark, which refills the buffer
+	_, err := buffer.GetRandomLocation(context.Background())
+	require.NoError(t, err)
+	assert.Eventually(t, func() bool { return len(buffer.locations) == 4 }, time.Second, time.Millisecond)
+	assert.Equal(t, 7, source.count())
+}
+
+func TestLocationBuffer_EmptyCallsSource(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockLocation := client.NewMockLocation(ctrl)
+	buffer := NewLocationBuffer(mockLocation, nil, LocationBufferConfiguration{Size: 4, LowWater: 2})
+
+	mockLocation.EXPECT().GetRandomLocation(gomock.Any()).Return(nil, errors.New("unavailable"))
+
+	_, err := buffer.GetRandomLocation(context.Background())
+
+	assert.EqualError(t, err, "unavailable")
+}
+
+func TestLocationBuffer_SkipsLocationsOutsideCoverage(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	source := &countingLocations{}
+	mockForecast := client.NewMockForecast(ctrl)
+	// the even locations are outside the NWS coverage, the fifth location fills the buffer
+	mockForecast.EXPECT().GetPointMetadata(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ context.Context, lat, _ float64) (*model.PointMetadata, error) {
+			if int(lat)%2 == 0 {
+				return nil, fmt.Errorf("failed to fetch forecast: %w", &client.APIError{StatusCode: http.StatusNotFound})
+			}
+			return &model.PointMetadata{GridID: "TOP"}, nil
+		},
+	).Times(5)
+	svc, _ := NewForecast(nil, mockForecast, time.Second, 10)
+
+	// without a low-water mark, draining the buffer does not refill it and validate more locations
+	buffer := NewLocationBuffer(source, nil, LocationBufferConfiguration{Size: 3, LowWater: 0})
+	buffer.SetCoverage(svc)
+	buffer.Start()
+	defer stopLocationBuffer(t, buffer)
+
+	assert.Eventually(t, func() bool { return len(buffer.locations) == 3 }, time.Second, time.Millisecond)
+	for _, expected := range []float64{1, 3, 5} {
+		location, err := buffer.GetRandomLocation(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, expected, location.Latitude)
+
+		// the validation warmed the points cache
+		point, err := svc.getPointMetadata(context.Background(), location.Latitude, location.Longitude)
+		require.NoError(t, err)
+		assert.Equal(t, "TOP", point.GridID)
+	}
+}
+
+func TestLocationBuffer_BuffersLocationsOfOtherProviders(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	source := &countingLocations{}
+	// Open-Meteo forecasts the locations outside of the NWS coverage, NWS is not asked for them
+	svc, err := NewForecast(nil, client.NewMockForecast(ctrl), time.Second, 10,
+		WithOpenMeteo(client.NewMockOpenMeteo(ctrl), ProviderAuto, time.Minute))
+	require.NoError(t, err)
+	buffer := NewLocationBuffer(source, svc, LocationBufferConfiguration{Size: 3})
+
+	err = buffer.fill(context.Background())
+
+	assert.NoError(t, err)
+	assert.Len(t, buffer.locations, 3)
+	assert.Equal(t, 3, source.count())
+}
+
+func TestLocationBuffer_RetriesDuringOutage(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	source := &countingLocations{}
+	mockForecast := client.NewMockForecast(ctrl)
+	mockForecast.EXPECT().GetPointMetadata(gomock.Any(), gomock.Any(), gomock.Any()).Return(
+		nil, fmt.Errorf("failed to fetch forecast: %w", &client.APIError{StatusCode: http.StatusServiceUnavailable}),
+	)
+	svc, _ := NewForecast(nil, mockForecast, time.Second, 10)
+	buffer := NewLocationBuffer(source, svc, LocationBufferConfiguration{Size: 3, LowWater: 1})
+
+	// an NWS outage does not reject the location as outside of the coverage, the filling is retried later
+	err := buffer.fill(context.Background())
+
+	assert.ErrorContains(t, err, "failed to validate location")
+	assert.Equal(t, 1, source.count())
+}
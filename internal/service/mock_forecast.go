@@ -3,7 +3,7 @@
 //
 // Generated by this command:
 //
-//	mockgen -destination=mocks/mock_forecast.go -package=service github.com/softstone1/fl/internal/service Forecast
+//	mockgen -destination=mocks/mock_forecast.go -package=service -self_package=github.com/softstone1/fl/internal/service github.com/softstone1/fl/internal/service Forecast
 //
 
 // Package service is a generated GoMock package.
@@ -13,6 +13,7 @@ import (
 	context "context"
 	reflect "reflect"
 
+	model "github.com/softstone1/fl/internal/model"
 	gomock "go.uber.org/mock/gomock"
 )
 
@@ -40,17 +41,106 @@ func (m *MockForecast) EXPECT() *MockForecastMockRecorder {
 	return m.recorder
 }
 
+// GetAlerts mocks base method.
+func (m *MockForecast) GetAlerts(ctx context.Context, query AlertsQuery) (*model.LocationAlerts, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAlerts", ctx, query)
+	ret0, _ := ret[0].(*model.LocationAlerts)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAlerts indicates an expected call of GetAlerts.
+func (mr *MockForecastMockRecorder) GetAlerts(ctx, query any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAlerts", reflect.TypeOf((*MockForecast)(nil).GetAlerts), ctx, query)
+}
+
+// GetForecastBatch mocks base method.
+func (m *MockForecast) GetForecastBatch(ctx context.Context, query BatchQuery, emit func(BatchResult)) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetForecastBatch", ctx, query, emit)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// GetForecastBatch indicates an expected call of GetForecastBatch.
+func (mr *MockForecastMockRecorder) GetForecastBatch(ctx, query, emit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetForecastBatch", reflect.TypeOf((*MockForecast)(nil).GetForecastBatch), ctx, query, emit)
+}
+
+// GetForecastPeriods mocks base method.
+func (m *MockForecast) GetForecastPeriods(ctx context.Context, query PeriodsQuery) (*model.ForecastPeriods, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetForecastPeriods", ctx, query)
+	ret0, _ := ret[0].(*model.ForecastPeriods)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetForecastPeriods indicates an expected call of GetForecastPeriods.
+func (mr *MockForecastMockRecorder) GetForecastPeriods(ctx, query any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetForecastPeriods", reflect.TypeOf((*MockForecast)(nil).GetForecastPeriods), ctx, query)
+}
+
+// GetGridpoints mocks base method.
+func (m *MockForecast) GetGridpoints(ctx context.Context, query GridpointsQuery) (*model.GridSeriesData, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetGridpoints", ctx, query)
+	ret0, _ := ret[0].(*model.GridSeriesData)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetGridpoints indicates an expected call of GetGridpoints.
+func (mr *MockForecastMockRecorder) GetGridpoints(ctx, query any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetGridpoints", reflect.TypeOf((*MockForecast)(nil).GetGridpoints), ctx, query)
+}
+
+// GetHourlyForecast mocks base method.
+func (m *MockForecast) GetHourlyForecast(ctx context.Context, query HourlyQuery) (*model.ForecastPeriods, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetHourlyForecast", ctx, query)
+	ret0, _ := ret[0].(*model.ForecastPeriods)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetHourlyForecast indicates an expected call of GetHourlyForecast.
+func (mr *MockForecastMockRecorder) GetHourlyForecast(ctx, query any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetHourlyForecast", reflect.TypeOf((*MockForecast)(nil).GetHourlyForecast), ctx, query)
+}
+
+// GetPoint mocks base method.
+func (m *MockForecast) GetPoint(ctx context.Context, query PointQuery) (*model.Point, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPoint", ctx, query)
+	ret0, _ := ret[0].(*model.Point)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPoint indicates an expected call of GetPoint.
+func (mr *MockForecastMockRecorder) GetPoint(ctx, query any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPoint", reflect.TypeOf((*MockForecast)(nil).GetPoint), ctx, query)
+}
+
 // GetRandomForecast mocks base method.
-func (m *MockForecast) GetRandomForecast(ctx context.Context) (string, error) {
+func (m *MockForecast) GetRandomForecast(ctx context.Context, query CurrentQuery) (*model.CurrentForecast, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetRandomForecast", ctx)
-	ret0, _ := ret[0].(string)
+	ret := m.ctrl.Call(m, "GetRandomForecast", ctx, query)
+	ret0, _ := ret[0].(*model.CurrentForecast)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // GetRandomForecast indicates an expected call of GetRandomForecast.
-func (mr *MockForecastMockRecorder) GetRandomForecast(ctx any) *gomock.Call {
+func (mr *MockForecastMockRecorder) GetRandomForecast(ctx, query any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRandomForecast", reflect.TypeOf((*MockForecast)(nil).GetRandomForecast), ctx)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRandomForecast", reflect.TypeOf((*MockForecast)(nil).GetRandomForecast), ctx, query)
 }
@@ -0,0 +1,62 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/softstone1/fl/internal/client"
+	"github.com/softstone1/fl/internal/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestGetObservationOrNone_FallsBackToNextStation(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockObservations := client.NewMockObservations(ctrl)
+	svc, _ := NewForecast(nil, nil, time.Second, 10, WithObservations(mockObservations, time.Minute, 3))
+
+	stations := []model.Station{
+		{ID: "KAAA", Name: "Down"},
+		{ID: "KBBB", Name: "No Measurements"},
+		{ID: "KCCC", Name: "Reporting"},
+		{ID: "KDDD", Name: "Too Far"},
+	}
+	temperature := -3.3
+	mockObservations.EXPECT().GetStations(gomock.Any(), "http://stations.url").Return(stations, nil)
+	mockObservations.EXPECT().GetLatestObservation(gomock.Any(), "KAAA").Return(nil, errors.New("station error")).Times(2)
+	mockObservations.EXPECT().GetLatestObservation(gomock.Any(), "KBBB").Return(&model.Observation{TextDescription: "Clear"}, nil)
+	mockObservations.EXPECT().GetLatestObservation(gomock.Any(), "KCCC").Return(&model.Observation{
+		TextDescription: "Cloudy",
+		Temperature:     model.QuantitativeValue{Value: &temperature, UnitCode: "wmoUnit:degC"},
+	}, nil)
+
+	// the stations and observations are cached, except for the failed one
+	for i := 0; i < 2; i++ {
+		observation := svc.getObservationOrNone(context.Background(), "http://stations.url")
+		require.NotNil(t, observation)
+		assert.Equal(t, stations[2], observation.Station)
+		assert.Equal(t, "Cloudy", observation.TextDescription)
+	}
+}
+
+func TestGetObservationOrNone_NoUsableStation(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockObservations := client.NewMockObservations(ctrl)
+	svc, _ := NewForecast(nil, nil, time.Second, 10, WithObservations(mockObservations, time.Minute, 1))
+
+	mockObservations.EXPECT().GetStations(gomock.Any(), "http://stations.url").Return(
+		[]model.Station{{ID: "KAAA"}, {ID: "KBBB"}}, nil,
+	)
+	mockObservations.EXPECT().GetLatestObservation(gomock.Any(), "KAAA").Return(&model.Observation{}, nil)
+
+	assert.Nil(t, svc.getObservationOrNone(context.Background(), "http://stations.url"))
+	// locations without a stations link have no observation
+	assert.Nil(t, svc.getObservationOrNone(context.Background(), ""))
+}
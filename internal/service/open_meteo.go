@@ -0,0 +1,385 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/softstone1/fl/internal/client"
+	"github.com/softstone1/fl/internal/model"
+)
+
+// WithOpenMeteo forecasts with Open-Meteo the locations selection gives to it. Its forecasts are cached for ttl.
+func WithOpenMeteo(openMeteoClient client.OpenMeteo, selection ProviderSelection, ttl time.Duration) Option {
+	return func(o *options) {
+		o.openMeteoClient = openMeteoClient
+		o.providerSelection = selection
+		o.openMeteoTTL = ttl
+	}
+}
+
+// openMeteoPrecision is the number of decimals the Open-Meteo coordinates are cached with, about 1 km,
+// finer than its forecast grids
+const openMeteoPrecision = 2
+
+// openMeteoProvider forecasts with Open-Meteo, worldwide. The standard forecast is made of 12 hour
+// day and night periods aggregated from the hourly one.
+type openMeteoProvider struct {
+	client  client.OpenMeteo
+	cache   Cache[client.OpenMeteoForecast]
+	fetches callGroup[client.OpenMeteoForecast]
+	ttl     time.Duration
+	now     func() time.Time
+}
+
+func (p *openMeteoProvider) name() ProviderSelection {
+	return ProviderOpenMeteo
+}
+
+// forecast returns the periods of kind, from the current hour on, built from the hourly forecast of the location
+func (p *openMeteoProvider) forecast(ctx context.Context, location model.Location, kind forecastKind, units model.Units) (providerForecast, error) {
+	response, err := p.getForecast(ctx, location.Latitude, location.Longitude)
+	if err != nil {
+		return providerForecast{}, forecastError(kind, err)
+	}
+	return p.periods(response, kind, units), nil
+}
+
+// refresh fetches the hourly forecast of the location from the API, then builds its standard forecast
+func (p *openMeteoProvider) refresh(ctx context.Context, location model.Location, units model.Units) (providerForecast, error) {
+	response, err := p.fetchForecast(ctx, location.Latitude, location.Longitude)
+	if err != nil {
+		return providerForecast{}, forecastError(standardForecast, err)
+	}
+	return p.periods(response, standardForecast, units), nil
+}
+
+// periods returns the periods of kind, from the current hour on, built from the hourly forecast
+func (p *openMeteoProvider) periods(response client.OpenMeteoForecast, kind forecastKind, units model.Units) providerForecast {
+	hours := openMeteoHours(response, p.now(), units)
+	periods := hourlyPeriods(hours, units)
+	if kind == standardForecast {
+		periods = dayNightPeriods(hours, units)
+	}
+	return providerForecast{
+		Forecast: model.Forecast{Periods: periods},
+		Point:    model.PointMetadata{TimeZone: response.TimeZone},
+		Source:   ProviderOpenMeteo,
+	}
+}
+
+// getForecast retrieves the hourly forecast of the coordinates, utilizing the cache if available
+func (p *openMeteoProvider) getForecast(ctx context.Context, lat, lng float64) (client.OpenMeteoForecast, error) {
+	cachedForecast, found, err := p.cache.Get(ctx, openMeteoCacheKey(lat, lng))
+	if err != nil {
+		log.Printf("Cache error: %v", err)
+	}
+	if found {
+		return cachedForecast, nil
+	}
+	return p.fetchForecast(ctx, lat, lng)
+}
+
+// openMeteoCacheKey returns the cache key of the coordinates, rounded to the Open-Meteo precision
+func openMeteoCacheKey(lat, lng float64) string {
+	return fmt.Sprintf("%.*f,%.*f", openMeteoPrecision, lat, openMeteoPrecision, lng)
+}
+
+// fetchForecast fetches the hourly forecast of the coordinates from the API and caches it.
+// Concurrent fetches of the same forecast share a single API call.
+func (p *openMeteoProvider) fetchForecast(ctx context.Context, lat, lng float64) (client.OpenMeteoForecast, error) {
+	cacheKey := openMeteoCacheKey(lat, lng)
+	return p.fetches.Do(ctx, cacheKey, func(ctx context.Context) (client.OpenMeteoForecast, error) {
+		forecastResponse, err := p.client.GetForecast(ctx, lat, lng)
+		if err != nil {
+			return client.OpenMeteoForecast{}, err
+		}
+		if err := p.cache.Set(ctx, cacheKey, *forecastResponse, p.ttl); err != nil {
+			log.Printf("Cache error: %v", err)
+		}
+		return *forecastResponse, nil
+	})
+}
+
+// openMeteoHour is the forecast of an hour, in units
+type openMeteoHour struct {
+	start                    time.Time
+	temperature              float64
+	precipitationProbability *float64
+	weatherCode              *int
+	windSpeed                float64
+	windDirection            *float64
+	isDay                    bool
+}
+
+// openMeteoHours returns the hours of the forecast not over at now, in its time zone, converted from °C
+// and km/h to units. The hours without a temperature are dropped.
+func openMeteoHours(response client.OpenMeteoForecast, now time.Time, units model.Units) []openMeteoHour {
+	zone, err := time.LoadLocation(response.TimeZone)
+	if err != nil {
+		zone = time.FixedZone(response.TimeZone, response.UTCOffsetSeconds)
+	}
+
+	hourly := response.Hourly
+	hours := make([]openMeteoHour, 0, len(hourly.Time))
+	for i, seconds := range hourly.Time {
+		start := time.Unix(seconds, 0).In(zone)
+		temperature := valueAt(hourly.Temperature, i)
+		if temperature == nil || !start.Add(time.Hour).After(now) {
+			continue
+		}
+		hour := openMeteoHour{
+			start:                    start,
+			temperature:              *temperature,
+			precipitationProbability: valueAt(hourly.PrecipitationProbability, i),
+			weatherCode:              valueAt(hourly.WeatherCode, i),
+			windDirection:            valueAt(hourly.WindDirection, i),
+			isDay:                    start.Hour() >= dayStartHour && start.Hour() < nightStartHour,
+		}
+		if isDay := valueAt(hourly.IsDay, i); isDay != nil {
+			hour.isDay = *isDay == 1
+		}
+		if windSpeed := valueAt(hourly.WindSpeed, i); windSpeed != nil {
+			hour.windSpeed = *windSpeed
+		}
+		if units == model.UnitsUS {
+			hour.temperature = celsiusToFahrenheit(hour.temperature)
+			hour.windSpeed = hour.windSpeed / kmPerMile
+		}
+		hours = append(hours, hour)
+	}
+	return hours
+}
+
+// valueAt returns the i-th value, nil when there is none
+func valueAt[V any](values []*V, i int) *V {
+	if i >= len(values) {
+		return nil
+	}
+	return values[i]
+}
+
+// hourlyPeriods returns a forecast period per hour
+func hourlyPeriods(hours []openMeteoHour, units model.Units) []model.ForcastPeriod {
+	temperatureUnit, speedUnit := periodUnits(units)
+	periods := make([]model.ForcastPeriod, 0, len(hours))
+	for i, hour := range hours {
+		periods = append(periods, model.ForcastPeriod{
+			Number:                     i + 1,
+			StartTime:                  hour.start,
+			EndTime:                    hour.start.Add(time.Hour),
+			IsDaytime:                  hour.isDay,
+			Temperature:                math.Round(hour.temperature),
+			TemperatureUnit:            temperatureUnit,
+			ProbabilityOfPrecipitation: percent(hour.precipitationProbability),
+			WindSpeed:                  fmt.Sprintf("%.0f %s", hour.windSpeed, speedUnit),
+			WindDirection:              windDirection(hour.windDirection),
+			ShortForecast:              weatherDescription(hour.weatherCode, hour.isDay),
+		})
+	}
+	return periods
+}
+
+// The day periods of the standard forecast run from 6 am to 6 pm local time, like the NWS ones,
+// and the night periods from 6 pm to 6 am
+const (
+	dayStartHour   = 6
+	nightStartHour = 18
+)
+
+// dayNightPeriods aggregates the hours into day and night periods. The first period starts with the first hour.
+func dayNightPeriods(hours []openMeteoHour, units model.Units) []model.ForcastPeriod {
+	var periods []model.ForcastPeriod
+	for start := 0; start < len(hours); {
+		blockStart, daytime := dayNightBlock(hours[start].start)
+		end := start + 1
+		for end < len(hours) {
+			if next, _ := dayNightBlock(hours[end].start); !next.Equal(blockStart) {
+				break
+			}
+			end++
+		}
+		period := dayNightPeriod(hours[start:end], daytime, units)
+		period.Number = len(periods) + 1
+		period.Name = dayNightName(blockStart, daytime, hours[0].start)
+		periods = append(periods, period)
+		start = end
+	}
+	return periods
+}
+
+// dayNightBlock returns the start of the day or night period the time is in, and whether it is a day period
+func dayNightBlock(t time.Time) (time.Time, bool) {
+	year, month, day := t.Date()
+	switch {
+	case t.Hour() < dayStartHour:
+		return time.Date(year, month, day-1, nightStartHour, 0, 0, 0, t.Location()), false
+	case t.Hour() < nightStartHour:
+		return time.Date(year, month, day, dayStartHour, 0, 0, 0, t.Location()), true
+	default:
+		return time.Date(year, month, day, nightStartHour, 0, 0, 0, t.Location()), false
+	}
+}
+
+// dayNightName names the period starting at blockStart like NWS does, relative to the first forecast hour,
+// e.g. "Tonight" or "Tuesday Night"
+func dayNightName(blockStart time.Time, daytime bool, first time.Time) string {
+	sameDay := blockStart.YearDay() == first.YearDay() && blockStart.Year() == first.Year()
+	switch {
+	case sameDay && daytime:
+		return "Today"
+	case sameDay:
+		return "Tonight"
+	case !daytime && blockStart.Before(first):
+		return "Overnight"
+	case daytime:
+		return blockStart.Weekday().String()
+	default:
+		return blockStart.Weekday().String() + " Night"
+	}
+}
+
+// dayNightPeriod aggregates hours into a period with the highest temperature of the day or the lowest one
+// of the night, the range of wind speeds, the highest chance of precipitation and the most severe weather
+func dayNightPeriod(hours []openMeteoHour, daytime bool, units model.Units) model.ForcastPeriod {
+	temperatureUnit, speedUnit := periodUnits(units)
+	temperature := hours[0].temperature
+	minWind, maxWind := hours[0], hours[0]
+	var precipitationProbability *float64
+	var weatherCode *int
+	for _, hour := range hours {
+		if daytime && hour.temperature > temperature || !daytime && hour.temperature < temperature {
+			temperature = hour.temperature
+		}
+		if hour.windSpeed < minWind.windSpeed {
+			minWind = hour
+		}
+		if hour.windSpeed > maxWind.windSpeed {
+			maxWind = hour
+		}
+		if p := hour.precipitationProbability; p != nil && (precipitationProbability == nil || *p > *precipitationProbability) {
+			precipitationProbability = p
+		}
+		// the WMO codes grow with the severity of the weather
+		if c := hour.weatherCode; c != nil && (weatherCode == nil || *c > *weatherCode) {
+			weatherCode = c
+		}
+	}
+
+	windSpeed := fmt.Sprintf("%.0f %s", math.Round(maxWind.windSpeed), speedUnit)
+	if math.Round(minWind.windSpeed) != math.Round(maxWind.windSpeed) {
+		windSpeed = fmt.Sprintf("%.0f to %.0f %s", math.Round(minWind.windSpeed), math.Round(maxWind.windSpeed), speedUnit)
+	}
+	period := model.ForcastPeriod{
+		StartTime:                  hours[0].start,
+		EndTime:                    hours[len(hours)-1].start.Add(time.Hour),
+		IsDaytime:                  daytime,
+		Temperature:                math.Round(temperature),
+		TemperatureUnit:            temperatureUnit,
+		ProbabilityOfPrecipitation: percent(precipitationProbability),
+		WindSpeed:                  windSpeed,
+		WindDirection:              windDirection(maxWind.windDirection),
+		ShortForecast:              weatherDescription(weatherCode, daytime),
+	}
+	period.DetailedForecast = detailedForecast(period)
+	return period
+}
+
+// detailedForecast describes the period in sentences like the NWS detailed forecasts,
+// e.g. "Partly Cloudy, with a high near 72. S wind 5 to 10 mph. Chance of precipitation is 20%."
+func detailedForecast(period model.ForcastPeriod) string {
+	var sentences []string
+	extreme := "a high near"
+	if !period.IsDaytime {
+		extreme = "a low around"
+	}
+	if period.ShortForecast != "" {
+		sentences = append(sentences, fmt.Sprintf("%s, with %s %.0f.", period.ShortForecast, extreme, period.Temperature))
+	} else {
+		sentences = append(sentences, fmt.Sprintf("%s %.0f.", strings.ToUpper(extreme[:1])+extreme[1:], period.Temperature))
+	}
+	wind := "Wind " + period.WindSpeed + "."
+	if period.WindDirection != "" {
+		wind = period.WindDirection + " wind " + period.WindSpeed + "."
+	}
+	sentences = append(sentences, wind)
+	if p := period.ProbabilityOfPrecipitation.Value; p != nil && *p > 0 {
+		sentences = append(sentences, fmt.Sprintf("Chance of precipitation is %.0f%%.", *p))
+	}
+	return strings.Join(sentences, " ")
+}
+
+// periodUnits returns the temperature and wind speed units of the periods in units
+func periodUnits(units model.Units) (string, string) {
+	if units == model.UnitsSI {
+		return celsius, "km/h"
+	}
+	return fahrenheit, "mph"
+}
+
+// percent returns the percentage as an NWS quantitative value
+func percent(value *float64) model.QuantitativeValue {
+	return model.QuantitativeValue{Value: value, UnitCode: "wmoUnit:percent"}
+}
+
+// windDirections are the 16 compass directions NWS gives wind directions in, clockwise from north
+var windDirections = []string{"N", "NNE", "NE", "ENE", "E", "ESE", "SE", "SSE", "S", "SSW", "SW", "WSW", "W", "WNW", "NW", "NNW"}
+
+// windDirection returns the compass direction the wind blows from, empty when unknown
+func windDirection(degrees *float64) string {
+	if degrees == nil {
+		return ""
+	}
+	sector := 360 / float64(len(windDirections))
+	return windDirections[int(math.Round(math.Mod(*degrees+360, 360)/sector))%len(windDirections)]
+}
+
+// weatherDescriptions describe the WMO weather interpretation codes, with their night variant when it differs
+var weatherDescriptions = map[int][2]string{
+	0:  {"Sunny", "Clear"},
+	1:  {"Mostly Sunny", "Mostly Clear"},
+	2:  {"Partly Cloudy", "Partly Cloudy"},
+	3:  {"Cloudy", "Cloudy"},
+	45: {"Fog", "Fog"},
+	48: {"Freezing Fog", "Freezing Fog"},
+	51: {"Light Drizzle", "Light Drizzle"},
+	53: {"Drizzle", "Drizzle"},
+	55: {"Heavy Drizzle", "Heavy Drizzle"},
+	56: {"Light Freezing Drizzle", "Light Freezing Drizzle"},
+	57: {"Freezing Drizzle", "Freezing Drizzle"},
+	61: {"Light Rain", "Light Rain"},
+	63: {"Rain", "Rain"},
+	65: {"Heavy Rain", "Heavy Rain"},
+	66: {"Light Freezing Rain", "Light Freezing Rain"},
+	67: {"Freezing Rain", "Freezing Rain"},
+	71: {"Light Snow", "Light Snow"},
+	73: {"Snow", "Snow"},
+	75: {"Heavy Snow", "Heavy Snow"},
+	77: {"Snow Grains", "Snow Grains"},
+	80: {"Light Rain Showers", "Light Rain Showers"},
+	81: {"Rain Showers", "Rain Showers"},
+	82: {"Heavy Rain Showers", "Heavy Rain Showers"},
+	85: {"Light Snow Showers", "Light Snow Showers"},
+	86: {"Snow Showers", "Snow Showers"},
+	95: {"Thunderstorms", "Thunderstorms"},
+	96: {"Thunderstorms With Hail", "Thunderstorms With Hail"},
+	99: {"Thunderstorms With Heavy Hail", "Thunderstorms With Heavy Hail"},
+}
+
+// weatherDescription describes the WMO weather code, empty when unknown
+func weatherDescription(code *int, daytime bool) string {
+	if code == nil {
+		return ""
+	}
+	descriptions, ok := weatherDescriptions[*code]
+	if !ok {
+		return ""
+	}
+	if daytime {
+		return descriptions[0]
+	}
+	return descriptions[1]
+}
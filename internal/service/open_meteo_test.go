@@ -0,0 +1,179 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/softstone1/fl/internal/client"
+	"github.com/softstone1/fl/internal/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+// paris is outside of the NWS coverage
+var paris = model.Location{Name: "Paris", Latitude: 48.8566, Longitude: 2.3522}
+
+// newOpenMeteoStandIn serves 48 hours of Open-Meteo forecast from July 1 2025 at midnight in Paris.
+// The temperature is the hour of the day in °C, the wind blows from SSW at 10 km/h, 20 km/h at 3 pm,
+// and it may rain at noon and rains at 2 pm on the first day.
+func newOpenMeteoStandIn(t *testing.T, calls *atomic.Int32) *httptest.Server {
+	zone, err := time.LoadLocation("Europe/Paris")
+	require.NoError(t, err)
+	start := time.Date(2025, 7, 1, 0, 0, 0, 0, zone)
+
+	var hourly struct {
+		Time                     []int64   `json:"time"`
+		Temperature              []float64 `json:"temperature_2m"`
+		PrecipitationProbability []float64 `json:"precipitation_probability"`
+		WeatherCode              []int     `json:"weather_code"`
+		WindSpeed                []float64 `json:"wind_speed_10m"`
+		WindDirection            []float64 `json:"wind_direction_10m"`
+		IsDay                    []int     `json:"is_day"`
+	}
+	for i := 0; i < 48; i++ {
+		hour := start.Add(time.Duration(i) * time.Hour)
+		probability, code, speed, isDay := 0.0, 2, 10.0, 0
+		switch i {
+		case 12:
+			probability = 10
+		case 14:
+			code = 61
+		case 15:
+			speed = 20
+		}
+		if hour.Hour() >= 6 && hour.Hour() < 20 {
+			isDay = 1
+		}
+		hourly.Time = append(hourly.Time, hour.Unix())
+		hourly.Temperature = append(hourly.Temperature, float64(hour.Hour()))
+		hourly.PrecipitationProbability = append(hourly.PrecipitationProbability, probability)
+		hourly.WeatherCode = append(hourly.WeatherCode, code)
+		hourly.WindSpeed = append(hourly.WindSpeed, speed)
+		hourly.WindDirection = append(hourly.WindDirection, 200)
+		hourly.IsDay = append(hourly.IsDay, isDay)
+	}
+
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		assert.Equal(t, "/v1/forecast", r.URL.Path)
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]any{
+			"timezone":           "Europe/Paris",
+			"utc_offset_seconds": 7200,
+			"hourly":             hourly,
+		})
+	}))
+}
+
+// newOpenMeteoService returns a service forecasting with the stand-in server as selected, at 10:30 am in Paris
+func newOpenMeteoService(t *testing.T, srv *httptest.Server, forecastClient client.Forecast, selection ProviderSelection, opts ...Option) *forecast {
+	now := time.Date(2025, 7, 1, 8, 30, 0, 0, time.UTC)
+	openMeteoClient := client.NewOpenMeteo(client.InitializeClient(client.Configuration{BaseURL: srv.URL, Timeout: time.Second}))
+	opts = append([]Option{WithClock(func() time.Time { return now }), WithOpenMeteo(openMeteoClient, selection, time.Minute)}, opts...)
+	svc, err := NewForecast(nil, forecastClient, time.Second, 10, opts...)
+	require.NoError(t, err)
+	return svc
+}
+
+func TestGetForecastPeriods_OpenMeteo(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	var calls atomic.Int32
+	srv := newOpenMeteoStandIn(t, &calls)
+	defer srv.Close()
+	// neither NWS forecasts nor alerts are asked for outside of the NWS coverage
+	mockAlerts := client.NewMockAlerts(ctrl)
+	svc := newOpenMeteoService(t, srv, client.NewMockForecast(ctrl), ProviderAuto, WithAlerts(mockAlerts, time.Minute))
+
+	resp, err := svc.GetForecastPeriods(context.Background(), PeriodsQuery{Location: &paris, Units: model.UnitsSI})
+
+	require.NoError(t, err)
+	assert.Equal(t, "Paris", resp.Location.Name)
+	assert.Equal(t, "Europe/Paris", resp.Location.TimeZone)
+	assert.Nil(t, resp.Alerts)
+	require.Len(t, resp.Periods, 4)
+
+	today := resp.Periods[0]
+	assert.Equal(t, "Today", today.Name)
+	assert.Equal(t, "2025-07-01T10:00:00+02:00", today.StartTime.Format(time.RFC3339))
+	assert.Equal(t, "2025-07-01T18:00:00+02:00", today.EndTime.Format(time.RFC3339))
+	assert.True(t, today.IsDaytime)
+	assert.Equal(t, 17.0, today.Temperature)
+	assert.Equal(t, "C", today.TemperatureUnit)
+	assert.Equal(t, "10 to 20 km/h", today.WindSpeed)
+	assert.Equal(t, "SSW", today.WindDirection)
+	assert.Equal(t, "Light Rain", today.ShortForecast)
+	assert.Equal(t, "Light Rain, with a high near 17. SSW wind 10 to 20 km/h. Chance of precipitation is 10%.", today.DetailedForecast)
+
+	tonight := resp.Periods[1]
+	assert.Equal(t, "Tonight", tonight.Name)
+	assert.False(t, tonight.IsDaytime)
+	assert.Equal(t, 0.0, tonight.Temperature)
+	assert.Equal(t, "Partly Cloudy, with a low around 0. SSW wind 10 km/h.", tonight.DetailedForecast)
+
+	assert.Equal(t, "Wednesday", resp.Periods[2].Name)
+	assert.Equal(t, "Wednesday Night", resp.Periods[3].Name)
+	assert.Equal(t, "2025-07-03T00:00:00+02:00", resp.Periods[3].EndTime.Format(time.RFC3339))
+
+	// the hourly forecast is cached and shared by the standard and hourly periods
+	_, err = svc.GetHourlyForecast(context.Background(), HourlyQuery{Location: &paris})
+	require.NoError(t, err)
+	assert.Equal(t, int32(1), calls.Load())
+}
+
+func TestGetHourlyForecast_OpenMeteo(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	var calls atomic.Int32
+	srv := newOpenMeteoStandIn(t, &calls)
+	defer srv.Close()
+	svc := newOpenMeteoService(t, srv, client.NewMockForecast(ctrl), ProviderAuto)
+
+	resp, err := svc.GetHourlyForecast(context.Background(), HourlyQuery{Location: &paris, Horizon: 3 * time.Hour})
+
+	require.NoError(t, err)
+	require.Len(t, resp.Periods, 4)
+	first := resp.Periods[0]
+	assert.Equal(t, "2025-07-01T10:00:00+02:00", first.StartTime.Format(time.RFC3339))
+	assert.Equal(t, 50.0, first.Temperature)
+	assert.Equal(t, "F", first.TemperatureUnit)
+	assert.Equal(t, "6 mph", first.WindSpeed)
+	assert.Equal(t, "Partly Cloudy", first.ShortForecast)
+	assert.Equal(t, 10.0, *resp.Periods[2].ProbabilityOfPrecipitation.Value)
+}
+
+func TestGetRandomForecast_OpenMeteo(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	var calls atomic.Int32
+	srv := newOpenMeteoStandIn(t, &calls)
+	defer srv.Close()
+	svc := newOpenMeteoService(t, srv, client.NewMockForecast(ctrl), ProviderOpenMeteo)
+
+	// Open-Meteo forecasts the US location too when it is selected for every location
+	location := &model.Location{Name: "Denver", Latitude: 39.7392, Longitude: -104.9903}
+	resp, err := svc.GetRandomForecast(context.Background(), CurrentQuery{Location: location})
+
+	require.NoError(t, err)
+	assert.Equal(t, "Today", resp.Period.Name)
+	assert.Equal(t, 63.0, resp.Period.Temperature)
+	assert.Equal(t, "6 to 12 mph", resp.Period.WindSpeed)
+	assert.Nil(t, resp.Observation)
+}
+
+func TestNewForecast_ProviderWithoutClient(t *testing.T) {
+	_, err := NewForecast(nil, nil, time.Second, 10, WithOpenMeteo(nil, ProviderAuto, time.Minute))
+	assert.EqualError(t, err, `forecast provider "open-meteo" is not configured`)
+
+	_, err = ParseProviderSelection("metoffice")
+	assert.EqualError(t, err, `unknown forecast provider: "metoffice"`)
+}
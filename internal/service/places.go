@@ -0,0 +1,115 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+
+	"github.com/softstone1/fl/internal/client"
+	"github.com/softstone1/fl/internal/model"
+)
+
+// ErrGeocodingDisabled is returned when a place is looked up by a service without a geocoder
+var ErrGeocodingDisabled = errors.New("place lookup is not enabled")
+
+// ErrPlaceNotFound is returned when no place matches a place name
+var ErrPlaceNotFound = errors.New("place not found")
+
+// AmbiguousPlaceError is returned when several places match a place name
+type AmbiguousPlaceError struct {
+	Query      string
+	Candidates []model.Place
+}
+
+func (e *AmbiguousPlaceError) Error() string {
+	return fmt.Sprintf("ambiguous place %q matches %d places", e.Query, len(e.Candidates))
+}
+
+// WithGeocoder looks up the places given by name with the geocoder
+func WithGeocoder(geocoder client.Geocoder) Option {
+	return func(o *options) {
+		o.geocoder = geocoder
+	}
+}
+
+// findPlace returns the location of the single place matching the query
+func (s *forecast) findPlace(ctx context.Context, query string) (model.Location, error) {
+	if s.Geocoder == nil {
+		return model.Location{}, ErrGeocodingDisabled
+	}
+	places, err := s.Geocoder.Geocode(ctx, query)
+	if err != nil {
+		return model.Location{}, fmt.Errorf("Stage 1 - Geocode error: %w", err)
+	}
+	switch len(places) {
+	case 0:
+		return model.Location{}, fmt.Errorf("%w: %q", ErrPlaceNotFound, query)
+	case 1:
+		return places[0].Location(), nil
+	default:
+		return model.Location{}, &AmbiguousPlaceError{Query: query, Candidates: places}
+	}
+}
+
+// describeLocation returns location with its time zone from the point metadata and, when it has no name,
+// named after its position relative to the nearest city
+func (s *forecast) describeLocation(ctx context.Context, location model.Location, point model.PointMetadata, units model.Units) model.Location {
+	location = withTimeZone(location, point.TimeZone)
+	if location.Name == "" {
+		location.Name = s.nameLocation(ctx, location, point.RelativeLocation, units)
+	}
+	return location
+}
+
+// nameLocation names the location after the city NWS relates its point to or else the nearest gazetteer place,
+// e.g. "5 mi NW of Boulder, CO". It returns an empty name when neither is known.
+func (s *forecast) nameLocation(ctx context.Context, location model.Location, relative model.RelativeLocation, units model.Units) string {
+	if relative.City == "" && s.Geocoder != nil {
+		nearest, err := s.Geocoder.ReverseGeocode(ctx, location.Latitude, location.Longitude)
+		if err != nil {
+			log.Printf("Failed to reverse geocode location: %v", err)
+		} else if nearest != nil {
+			relative = *nearest
+		}
+	}
+	if relative.City == "" {
+		return ""
+	}
+	return describeRelativeLocation(relative, units)
+}
+
+// describeRelativeLocation returns the distance, in whole miles or kilometers, and compass direction
+// of a position from a city, or just the city when the position is within a mile or kilometer of it
+func describeRelativeLocation(relative model.RelativeLocation, units model.Units) string {
+	city := relative.City
+	if relative.State != "" {
+		city += ", " + relative.State
+	}
+	if relative.Distance.Value == nil || relative.Distance.UnitCode != unitMeter || relative.Bearing.Value == nil {
+		return city
+	}
+
+	distance, unit := *relative.Distance.Value/(kmPerMile*1000), "mi"
+	if units == model.UnitsSI {
+		distance, unit = *relative.Distance.Value/1000, "km"
+	}
+	distance = math.Round(distance)
+	if distance < 1 {
+		return city
+	}
+	return fmt.Sprintf("%.0f %s %s of %s", distance, unit, compassPoint(*relative.Bearing.Value), city)
+}
+
+// compassPoints are the 8 principal compass directions, clockwise from north
+var compassPoints = []string{"N", "NE", "E", "SE", "S", "SW", "W", "NW"}
+
+// compassPoint returns the principal compass direction nearest to bearing, in degrees clockwise from north
+func compassPoint(bearing float64) string {
+	bearing = math.Mod(bearing, 360)
+	if bearing < 0 {
+		bearing += 360
+	}
+	return compassPoints[int(math.Round(bearing/45))%len(compassPoints)]
+}
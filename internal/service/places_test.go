@@ -0,0 +1,132 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/softstone1/fl/internal/client"
+	"github.com/softstone1/fl/internal/model"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func TestGetRandomForecast_Place(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockForecast := client.NewMockForecast(ctrl)
+	mockGeocoder := client.NewMockGeocoder(ctrl)
+	svc, _ := NewForecast(nil, mockForecast, time.Second, 10, WithGeocoder(mockGeocoder))
+
+	mockGeocoder.EXPECT().Geocode(gomock.Any(), "Seattle, WA").Return(
+		[]model.Place{{Name: "Seattle", State: "WA", Latitude: 47.6062, Longitude: -122.3321}}, nil,
+	)
+	mockForecast.EXPECT().GetPointMetadata(gomock.Any(), 47.6062, -122.3321).Return(
+		&model.PointMetadata{Forecast: "http://test.url"}, nil,
+	)
+	mockForecast.EXPECT().GetForecastPeriods(gomock.Any(), "http://test.url").Return(
+		&model.Forecast{Periods: []model.ForcastPeriod{
+			{StartTime: time.Now().Add(-time.Hour), EndTime: time.Now().Add(time.Hour), DetailedForecast: "Rainy"},
+		}}, nil,
+	)
+
+	resp, err := svc.GetRandomForecast(context.Background(), CurrentQuery{Place: "Seattle, WA"})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "Seattle, WA", resp.Location.Name)
+	assert.Equal(t, "Rainy", resp.Period.DetailedForecast)
+}
+
+func TestGetRandomForecast_PlaceErrors(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockGeocoder := client.NewMockGeocoder(ctrl)
+	svc, _ := NewForecast(nil, nil, time.Second, 10, WithGeocoder(mockGeocoder))
+
+	candidates := []model.Place{{Name: "Portland", State: "OR"}, {Name: "Portland", State: "ME"}}
+	mockGeocoder.EXPECT().Geocode(gomock.Any(), "Portland").Return(candidates, nil)
+	mockGeocoder.EXPECT().Geocode(gomock.Any(), "Gotham").Return(nil, nil)
+
+	_, err := svc.GetRandomForecast(context.Background(), CurrentQuery{Place: "Portland"})
+	var ambiguous *AmbiguousPlaceError
+	if assert.ErrorAs(t, err, &ambiguous) {
+		assert.Equal(t, candidates, ambiguous.Candidates)
+	}
+
+	_, err = svc.GetRandomForecast(context.Background(), CurrentQuery{Place: "Gotham"})
+	assert.ErrorIs(t, err, ErrPlaceNotFound)
+
+	disabled, _ := NewForecast(nil, nil, time.Second, 10)
+	_, err = disabled.GetRandomForecast(context.Background(), CurrentQuery{Place: "Seattle"})
+	assert.ErrorIs(t, err, ErrGeocodingDisabled)
+}
+
+func TestDescribeRelativeLocation(t *testing.T) {
+	value := func(v float64) *float64 { return &v }
+	boulder := func(meters, bearing float64) model.RelativeLocation {
+		return model.RelativeLocation{
+			City:     "Boulder",
+			State:    "CO",
+			Distance: model.QuantitativeValue{Value: value(meters), UnitCode: "wmoUnit:m"},
+			Bearing:  model.QuantitativeValue{Value: value(bearing), UnitCode: "wmoUnit:degree_(angle)"},
+		}
+	}
+
+	tests := []struct {
+		name     string
+		relative model.RelativeLocation
+		units    model.Units
+		expected string
+	}{
+		{name: "Miles", relative: boulder(8047, 315), units: model.UnitsUS, expected: "5 mi NW of Boulder, CO"},
+		{name: "Kilometers", relative: boulder(8047, 315), units: model.UnitsSI, expected: "8 km NW of Boulder, CO"},
+		{name: "Bearing wraps to north", relative: boulder(8047, 350), units: model.UnitsUS, expected: "5 mi N of Boulder, CO"},
+		{name: "Within a mile", relative: boulder(500, 90), units: model.UnitsUS, expected: "Boulder, CO"},
+		{name: "Unknown distance", relative: model.RelativeLocation{City: "Boulder", State: "CO"}, expected: "Boulder, CO"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, describeRelativeLocation(tc.relative, tc.units))
+		})
+	}
+}
+
+func TestGetPoint_NamesLocation(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockForecast := client.NewMockForecast(ctrl)
+	mockGeocoder := client.NewMockGeocoder(ctrl)
+	svc, _ := NewForecast(nil, mockForecast, time.Second, 10, WithGeocoder(mockGeocoder))
+
+	distance, bearing := 7366.98, 358.0
+	mockForecast.EXPECT().GetPointMetadata(gomock.Any(), 39.7456, -97.0892).Return(
+		&model.PointMetadata{RelativeLocation: model.RelativeLocation{
+			City:     "Linn",
+			State:    "KS",
+			Distance: model.QuantitativeValue{Value: &distance, UnitCode: "wmoUnit:m"},
+			Bearing:  model.QuantitativeValue{Value: &bearing, UnitCode: "wmoUnit:degree_(angle)"},
+		}}, nil,
+	)
+	// the gazetteer names the points NWS relates to no city
+	mockForecast.EXPECT().GetPointMetadata(gomock.Any(), 40.105, -105.2705).Return(&model.PointMetadata{}, nil)
+	mockGeocoder.EXPECT().ReverseGeocode(gomock.Any(), 40.105, -105.2705).Return(
+		&model.RelativeLocation{City: "Boulder", State: "CO"}, nil,
+	)
+
+	resp, err := svc.GetPoint(context.Background(), PointQuery{Location: &model.Location{Latitude: 39.7456, Longitude: -97.0892}})
+	assert.NoError(t, err)
+	assert.Equal(t, "5 mi N of Linn, KS", resp.Location.Name)
+
+	resp, err = svc.GetPoint(context.Background(), PointQuery{Location: &model.Location{Latitude: 40.105, Longitude: -105.2705}})
+	assert.NoError(t, err)
+	assert.Equal(t, "Boulder, CO", resp.Location.Name)
+
+	// named locations keep their name
+	resp, err = svc.GetPoint(context.Background(), PointQuery{Location: &model.Location{Name: "Farm", Latitude: 39.7456, Longitude: -97.0892}})
+	assert.NoError(t, err)
+	assert.Equal(t, "Farm", resp.Location.Name)
+}
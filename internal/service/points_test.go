@@ -0,0 +1,64 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/softstone1/fl/internal/client"
+	"github.com/softstone1/fl/internal/model"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func TestGetPoint_CachedPerRoundedCoordinate(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockForecast := client.NewMockForecast(ctrl)
+	svc, _ := NewForecast(nil, mockForecast, time.Second, 10)
+
+	// the points metadata is fetched once, nearby coordinates rounding to the same point reuse it
+	mockForecast.EXPECT().GetPointMetadata(gomock.Any(), 39.7456, -97.0892).Return(
+		&model.PointMetadata{GridID: "TOP", GridX: 32, GridY: 81, TimeZone: "America/Chicago"}, nil,
+	)
+
+	resp, err := svc.GetPoint(context.Background(), PointQuery{Location: &model.Location{Latitude: 39.7456, Longitude: -97.0892}})
+	assert.NoError(t, err)
+	assert.Equal(t, "TOP", resp.Metadata.GridID)
+	assert.Equal(t, "America/Chicago", resp.Location.TimeZone)
+
+	resp, err = svc.GetPoint(context.Background(), PointQuery{Location: &model.Location{Latitude: 39.74561, Longitude: -97.08919}})
+	assert.NoError(t, err)
+	assert.Equal(t, 81, resp.Metadata.GridY)
+}
+
+func TestGetForecastPeriods_SharedPerGridCell(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockForecast := client.NewMockForecast(ctrl)
+	svc, _ := NewForecast(nil, mockForecast, time.Second, 10)
+
+	// both points lie in the TOP 32,81 grid cell, whose forecast is fetched once
+	mockForecast.EXPECT().GetPointMetadata(gomock.Any(), 39.7456, -97.0892).Return(
+		&model.PointMetadata{GridID: "TOP", GridX: 32, GridY: 81, Forecast: "http://test.url/a"}, nil,
+	)
+	mockForecast.EXPECT().GetPointMetadata(gomock.Any(), 39.746, -97.09).Return(
+		&model.PointMetadata{GridID: "TOP", GridX: 32, GridY: 81, Forecast: "http://test.url/b"}, nil,
+	)
+	mockForecast.EXPECT().GetForecastPeriods(gomock.Any(), "http://test.url/a").Return(
+		&model.Forecast{Periods: []model.ForcastPeriod{
+			{StartTime: time.Now().Add(-time.Hour), EndTime: time.Now().Add(time.Hour), DetailedForecast: "Sunny"},
+		}}, nil,
+	)
+
+	for _, location := range []*model.Location{
+		{Latitude: 39.7456, Longitude: -97.0892},
+		{Latitude: 39.746, Longitude: -97.09},
+	} {
+		resp, err := svc.GetForecastPeriods(context.Background(), PeriodsQuery{Location: location})
+		assert.NoError(t, err)
+		assert.Equal(t, "Sunny", resp.Periods[0].DetailedForecast)
+	}
+}
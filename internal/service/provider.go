@@ -0,0 +1,159 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+
+	"github.com/softstone1/fl/internal/client"
+	"github.com/softstone1/fl/internal/model"
+)
+
+// ProviderSelection chooses the provider forecasting a location
+type ProviderSelection string
+
+const (
+	// ProviderNWS forecasts every location with NWS, which only covers the US
+	ProviderNWS ProviderSelection = "nws"
+	// ProviderOpenMeteo forecasts every location with Open-Meteo
+	ProviderOpenMeteo ProviderSelection = "open-meteo"
+	// ProviderAuto forecasts the locations NWS covers with NWS, falling back to Open-Meteo when NWS fails,
+	// and the others with Open-Meteo
+	ProviderAuto ProviderSelection = "auto"
+)
+
+// ParseProviderSelection returns the provider selection called name
+func ParseProviderSelection(name string) (ProviderSelection, error) {
+	switch selection := ProviderSelection(name); selection {
+	case ProviderNWS, ProviderOpenMeteo, ProviderAuto:
+		return selection, nil
+	default:
+		return "", fmt.Errorf("unknown forecast provider: %q", name)
+	}
+}
+
+// forecastProvider is a source of standard and hourly forecasts
+type forecastProvider interface {
+	// name identifies the provider
+	name() ProviderSelection
+	// forecast returns the forecast of kind at the location, in units when the provider supports them
+	forecast(ctx context.Context, location model.Location, kind forecastKind, units model.Units) (providerForecast, error)
+	// refresh fetches the standard forecast at the location again, bypassing the forecast cache, and caches it
+	refresh(ctx context.Context, location model.Location, units model.Units) (providerForecast, error)
+}
+
+// providerForecast is a forecast with what its provider knows of the forecast point, at least its time zone
+type providerForecast struct {
+	Forecast model.Forecast
+	Point    model.PointMetadata
+	Source   ProviderSelection
+}
+
+// errOutsideNWSCoverage is returned by the NWS provider for the locations of a cell NWS has no points for
+var errOutsideNWSCoverage = errors.New("location outside of the NWS coverage")
+
+// nwsCellPrecision is the number of decimals of the cells NWS is remembered not to cover, about 11 km
+const nwsCellPrecision = 1
+
+// nwsCoverage are the bounding boxes of the areas NWS forecasts: the contiguous US, Alaska, Hawaii,
+// Puerto Rico and the Virgin Islands, Guam and the Northern Mariana Islands, and American Samoa.
+// The boxes also hold parts of Canada, Mexico and the Caribbean, whose cells the NWS provider
+// remembers once NWS answered 404 for them.
+var nwsCoverage = []client.BoundingBox{
+	{South: 24.4, West: -125, North: 49.4, East: -66.9},
+	{South: 51.2, West: -180, North: 71.5, East: -129.9},
+	{South: 51.2, West: 172.4, North: 53.1, East: 180},
+	{South: 18.9, West: -160.3, North: 22.3, East: -154.8},
+	{South: 17.6, West: -67.3, North: 18.6, East: -64.5},
+	{South: 13.2, West: 144.6, North: 20.6, East: 146.1},
+	{South: -14.6, West: -171.1, North: -11.0, East: -168.1},
+}
+
+// nwsCovers reports whether the location is in the bounding boxes of the NWS coverage
+func nwsCovers(location model.Location) bool {
+	for _, box := range nwsCoverage {
+		if box.Contains(location.Latitude, location.Longitude) {
+			return true
+		}
+	}
+	return false
+}
+
+// supplement returns the location described, and its active alerts. Both come from NWS and the US gazetteer,
+// so they are only looked up when NWS made the forecast.
+func (s *forecast) supplement(ctx context.Context, location model.Location, result providerForecast, units model.Units) (model.Location, []model.Alert) {
+	if result.Source != ProviderNWS {
+		return withTimeZone(location, result.Point.TimeZone), nil
+	}
+	return s.describeLocation(ctx, location, result.Point, units), s.getAlertsOrNone(ctx, &location)
+}
+
+// nwsProvider forecasts with the NWS points and forecast APIs, through the service caches
+type nwsProvider struct {
+	s *forecast
+}
+
+func (p nwsProvider) name() ProviderSelection {
+	return ProviderNWS
+}
+
+// forecast resolves the point metadata of the location, then gets the forecast of its grid cell
+func (p nwsProvider) forecast(ctx context.Context, location model.Location, kind forecastKind, units model.Units) (providerForecast, error) {
+	point, err := p.point(ctx, location)
+	if err != nil {
+		return providerForecast{}, err
+	}
+	forecastResponse, err := p.s.getForecast(ctx, newForecastRequest(point, kind, units))
+	if err != nil {
+		return providerForecast{}, forecastError(kind, err)
+	}
+	return providerForecast{Forecast: forecastResponse, Point: point, Source: ProviderNWS}, nil
+}
+
+// refresh resolves the point metadata of the location, then fetches the forecast of its grid cell from the API
+func (p nwsProvider) refresh(ctx context.Context, location model.Location, units model.Units) (providerForecast, error) {
+	point, err := p.point(ctx, location)
+	if err != nil {
+		return providerForecast{}, err
+	}
+	forecastResponse, err := p.s.fetchForecast(ctx, newForecastRequest(point, standardForecast, units))
+	if err != nil {
+		return providerForecast{}, forecastError(standardForecast, err)
+	}
+	return providerForecast{Forecast: forecastResponse, Point: point, Source: ProviderNWS}, nil
+}
+
+// point resolves the point metadata of the location. The cells NWS answered 404 for are remembered,
+// so that the next locations in them are not asked for.
+func (p nwsProvider) point(ctx context.Context, location model.Location) (model.PointMetadata, error) {
+	cell := fmt.Sprintf("%.*f,%.*f", nwsCellPrecision, location.Latitude, nwsCellPrecision, location.Longitude)
+	uncovered, found, err := p.s.nwsUncoveredCache.Get(ctx, cell)
+	if err != nil {
+		log.Printf("Cache error: %v", err)
+	}
+	if found && uncovered {
+		return model.PointMetadata{}, fmt.Errorf("Stage 2 - GetPointMetadata error: %w", errOutsideNWSCoverage)
+	}
+
+	point, err := p.s.getPointMetadata(ctx, location.Latitude, location.Longitude)
+	if err != nil {
+		var apiErr *client.APIError
+		if errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusNotFound {
+			if err := p.s.nwsUncoveredCache.Set(ctx, cell, true, 0); err != nil {
+				log.Printf("Cache error: %v", err)
+			}
+		}
+		return model.PointMetadata{}, fmt.Errorf("Stage 2 - GetPointMetadata error: %w", err)
+	}
+	return point, nil
+}
+
+// forecastError wraps the error getting a forecast of kind
+func forecastError(kind forecastKind, err error) error {
+	if kind == hourlyForecast {
+		return fmt.Errorf("Stage 3 - GetHourlyForecastResponse error: %w", err)
+	}
+	return fmt.Errorf("Stage 3 - GetForecastResponse error: %w", err)
+}
@@ -0,0 +1,265 @@
+package service
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// errNil is the RESP null bulk string, returned by GET for missing keys
+var errNil = errors.New("resp: nil")
+
+// RedisConfiguration holds the settings for the Redis client
+type RedisConfiguration struct {
+	Addr        string
+	Password    string
+	DB          int
+	PoolSize    int
+	DialTimeout time.Duration
+}
+
+// RedisClient is a minimal RESP2 client with a fixed-size connection pool
+type RedisClient struct {
+	config RedisConfiguration
+	pool   chan *respConn
+}
+
+// respConn is a single connection to the Redis server
+type respConn struct {
+	conn   net.Conn
+	reader *bufio.Reader
+}
+
+// NewRedisClient initializes a Redis client. Connections are established lazily.
+func NewRedisClient(config RedisConfiguration) *RedisClient {
+	if config.PoolSize <= 0 {
+		config.PoolSize = 1
+	}
+	return &RedisClient{
+		config: config,
+		pool:   make(chan *respConn, config.PoolSize),
+	}
+}
+
+// Do sends a command and returns its reply
+func (c *RedisClient) Do(ctx context.Context, args ...string) (any, error) {
+	conn, err := c.get(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	reply, err := conn.do(ctx, args...)
+	var respErr respError
+	if err != nil && !errors.Is(err, errNil) && !errors.As(err, &respErr) {
+		// the connection state is unknown after an I/O error
+		conn.conn.Close()
+		return nil, err
+	}
+	c.put(conn)
+	return reply, err
+}
+
+// Ping checks the connection to the server
+func (c *RedisClient) Ping(ctx context.Context) error {
+	_, err := c.Do(ctx, "PING")
+	return err
+}
+
+// Close closes the idle connections
+func (c *RedisClient) Close() {
+	for {
+		select {
+		case conn := <-c.pool:
+			conn.conn.Close()
+		default:
+			return
+		}
+	}
+}
+
+// get takes an idle connection from the pool or dials a new one
+func (c *RedisClient) get(ctx context.Context) (*respConn, error) {
+	select {
+	case conn := <-c.pool:
+		return conn, nil
+	default:
+	}
+
+	dialer := net.Dialer{Timeout: c.config.DialTimeout}
+	netConn, err := dialer.DialContext(ctx, "tcp", c.config.Addr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to redis: %w", err)
+	}
+	conn := &respConn{conn: netConn, reader: bufio.NewReader(netConn)}
+
+	if c.config.Password != "" {
+		if _, err := conn.do(ctx, "AUTH", c.config.Password); err != nil {
+			netConn.Close()
+			return nil, fmt.Errorf("failed to authenticate to redis: %w", err)
+		}
+	}
+	if c.config.DB != 0 {
+		if _, err := conn.do(ctx, "SELECT", strconv.Itoa(c.config.DB)); err != nil {
+			netConn.Close()
+			return nil, fmt.Errorf("failed to select redis db: %w", err)
+		}
+	}
+	return conn, nil
+}
+
+// put returns a healthy connection to the pool, closing it when the pool is full
+func (c *RedisClient) put(conn *respConn) {
+	select {
+	case c.pool <- conn:
+	default:
+		conn.conn.Close()
+	}
+}
+
+// do writes a command and reads one reply
+func (c *respConn) do(ctx context.Context, args ...string) (any, error) {
+	// a zero deadline, when ctx has none, disables the timeout
+	deadline, _ := ctx.Deadline()
+	if err := c.conn.SetDeadline(deadline); err != nil {
+		return nil, err
+	}
+	if _, err := c.conn.Write(encodeRESPCommand(args)); err != nil {
+		return nil, fmt.Errorf("failed to send redis command: %w", err)
+	}
+	return readRESP(c.reader)
+}
+
+// respError is an error reply sent by the server
+type respError string
+
+func (e respError) Error() string {
+	return "redis: " + string(e)
+}
+
+// encodeRESPCommand encodes a command as a RESP array of bulk strings
+func encodeRESPCommand(args []string) []byte {
+	var b strings.Builder
+	fmt.Fprintf(&b, "*%d\r\n", len(args))
+	for _, arg := range args {
+		fmt.Fprintf(&b, "$%d\r\n%s\r\n", len(arg), arg)
+	}
+	return []byte(b.String())
+}
+
+// readRESP reads a single RESP2 value. Error replies are returned as respError,
+// null bulk strings and arrays as errNil.
+func readRESP(r *bufio.Reader) (any, error) {
+	line, err := r.ReadString('\n')
+	if err != nil {
+		return nil, fmt.Errorf("failed to read redis reply: %w", err)
+	}
+	line = strings.TrimSuffix(line, "\r\n")
+	if line == "" {
+		return nil, errors.New("empty redis reply")
+	}
+
+	switch line[0] {
+	case '+':
+		return line[1:], nil
+	case '-':
+		return nil, respError(line[1:])
+	case ':':
+		return strconv.ParseInt(line[1:], 10, 64)
+	case '$':
+		size, err := strconv.Atoi(line[1:])
+		if err != nil {
+			return nil, fmt.Errorf("invalid bulk string length %q: %w", line, err)
+		}
+		if size < 0 {
+			return nil, errNil
+		}
+		buf := make([]byte, size+2)
+		if _, err := io.ReadFull(r, buf); err != nil {
+			return nil, fmt.Errorf("failed to read redis reply: %w", err)
+		}
+		return string(buf[:size]), nil
+	case '*':
+		size, err := strconv.Atoi(line[1:])
+		if err != nil {
+			return nil, fmt.Errorf("invalid array length %q: %w", line, err)
+		}
+		if size < 0 {
+			return nil, errNil
+		}
+		values := make([]any, 0, size)
+		for i := 0; i < size; i++ {
+			value, err := readRESP(r)
+			if err != nil && !errors.Is(err, errNil) {
+				return nil, err
+			}
+			values = append(values, value)
+		}
+		return values, nil
+	default:
+		return nil, fmt.Errorf("unexpected redis reply %q", line)
+	}
+}
+
+// cacheLayoutVersion is part of the keys of the service caches in Redis. It must be bumped whenever the
+// layout of a cached value or of its keys changes, so that replicas never decode entries written with another one.
+const cacheLayoutVersion = 1
+
+// redisCache is a Cache shared between replicas, storing JSON encoded values in Redis
+type redisCache[V any] struct {
+	client *RedisClient
+	prefix string
+}
+
+// make sure redisCache implements the Cache interface
+var _ Cache[string] = (*redisCache[string])(nil)
+
+// NewRedisCache creates a cache storing its entries under keys starting with prefix
+func NewRedisCache[V any](client *RedisClient, prefix string) *redisCache[V] {
+	return &redisCache[V]{
+		client: client,
+		prefix: prefix,
+	}
+}
+
+// Get reads and decodes the value stored for key
+func (c *redisCache[V]) Get(ctx context.Context, key string) (V, bool, error) {
+	var value V
+	reply, err := c.client.Do(ctx, "GET", c.prefix+key)
+	if errors.Is(err, errNil) {
+		return value, false, nil
+	}
+	if err != nil {
+		return value, false, fmt.Errorf("failed to get %q from redis: %w", key, err)
+	}
+	data, ok := reply.(string)
+	if !ok {
+		return value, false, fmt.Errorf("unexpected redis reply type %T", reply)
+	}
+	if err := json.Unmarshal([]byte(data), &value); err != nil {
+		return value, false, fmt.Errorf("failed to decode %q from redis: %w", key, err)
+	}
+	return value, true, nil
+}
+
+// Set encodes and stores the value, letting Redis expire it after ttl
+func (c *redisCache[V]) Set(ctx context.Context, key string, value V, ttl time.Duration) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Errorf("failed to encode %q for redis: %w", key, err)
+	}
+	args := []string{"SET", c.prefix + key, string(data)}
+	if ttl > 0 {
+		args = append(args, "PX", strconv.FormatInt(max(ttl.Milliseconds(), 1), 10))
+	}
+	if _, err := c.client.Do(ctx, args...); err != nil {
+		return fmt.Errorf("failed to set %q in redis: %w", key, err)
+	}
+	return nil
+}
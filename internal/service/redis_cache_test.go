@@ -0,0 +1,229 @@
+package service
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/softstone1/fl/internal/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeRedis is an in-process RESP server supporting the commands used by redisCache
+type fakeRedis struct {
+	listener net.Listener
+	mu       sync.Mutex
+	data     map[string]string
+	expiry   map[string]time.Time
+}
+
+func newFakeRedis(t *testing.T) *fakeRedis {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	f := &fakeRedis{
+		listener: listener,
+		data:     map[string]string{},
+		expiry:   map[string]time.Time{},
+	}
+	go f.serve()
+	t.Cleanup(func() { listener.Close() })
+	return f
+}
+
+func (f *fakeRedis) addr() string {
+	return f.listener.Addr().String()
+}
+
+func (f *fakeRedis) serve() {
+	for {
+		conn, err := f.listener.Accept()
+		if err != nil {
+			return
+		}
+		go f.handle(conn)
+	}
+}
+
+func (f *fakeRedis) handle(conn net.Conn) {
+	defer conn.Close()
+	reader := bufio.NewReader(conn)
+	for {
+		value, err := readRESP(reader)
+		if err != nil {
+			return
+		}
+		items, _ := value.([]any)
+		args := make([]string, 0, len(items))
+		for _, item := range items {
+			arg, _ := item.(string)
+			args = append(args, arg)
+		}
+		if _, err := conn.Write([]byte(f.exec(args))); err != nil {
+			return
+		}
+	}
+}
+
+func (f *fakeRedis) exec(args []string) string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if len(args) == 0 {
+		return "-ERR empty command\r\n"
+	}
+	switch strings.ToUpper(args[0]) {
+	case "PING":
+		return "+PONG\r\n"
+	case "GET":
+		if expiresAt, ok := f.expiry[args[1]]; ok && !time.Now().Before(expiresAt) {
+			delete(f.data, args[1])
+			delete(f.expiry, args[1])
+		}
+		value, ok := f.data[args[1]]
+		if !ok {
+			return "$-1\r\n"
+		}
+		return fmt.Sprintf("$%d\r\n%s\r\n", len(value), value)
+	case "SET":
+		f.data[args[1]] = args[2]
+		delete(f.expiry, args[1])
+		if len(args) == 5 && strings.ToUpper(args[3]) == "PX" {
+			ms, err := strconv.Atoi(args[4])
+			if err != nil || ms <= 0 {
+				return "-ERR invalid expire time in 'set' command\r\n"
+			}
+			f.expiry[args[1]] = time.Now().Add(time.Duration(ms) * time.Millisecond)
+		}
+		return "+OK\r\n"
+	default:
+		return fmt.Sprintf("-ERR unknown command '%s'\r\n", args[0])
+	}
+}
+
+func TestRedisCache_SetGet(t *testing.T) {
+	server := newFakeRedis(t)
+	client := NewRedisClient(RedisConfiguration{Addr: server.addr(), PoolSize: 2, DialTimeout: time.Second})
+	defer client.Close()
+	ctx := context.Background()
+
+	cache := NewRedisCache[[]model.ForcastPeriod](client, "fl:forecastPeriods:")
+	periods := []model.ForcastPeriod{{Number: 1, Name: "Tonight", DetailedForecast: "Clear"}}
+
+	require.NoError(t, client.Ping(ctx))
+	require.NoError(t, cache.Set(ctx, "http://test.url", periods, time.Hour))
+
+	got, found, err := cache.Get(ctx, "http://test.url")
+	assert.NoError(t, err)
+	assert.True(t, found)
+	assert.Equal(t, periods, got)
+	assert.Contains(t, server.data, "fl:forecastPeriods:http://test.url")
+
+	_, found, err = cache.Get(ctx, "http://missing.url")
+	assert.NoError(t, err)
+	assert.False(t, found)
+}
+
+func TestNewForecast_RedisKeyPrefix(t *testing.T) {
+	server := newFakeRedis(t)
+	client := NewRedisClient(RedisConfiguration{Addr: server.addr(), DialTimeout: time.Second})
+	defer client.Close()
+
+	svc, err := NewForecast(nil, nil, time.Second, 10, WithRedisCache(client, "fl:"))
+	require.NoError(t, err)
+	require.NoError(t, svc.pointsCache.Set(context.Background(), "39.7456,-97.0892", model.PointMetadata{}, time.Hour))
+
+	// the keys carry the cache layout version, so entries of another layout are never decoded
+	assert.Contains(t, server.data, fmt.Sprintf("fl:v%d:points:39.7456,-97.0892", cacheLayoutVersion))
+}
+
+func TestRedisCache_Expiry(t *testing.T) {
+	server := newFakeRedis(t)
+	client := NewRedisClient(RedisConfiguration{Addr: server.addr(), DialTimeout: time.Second})
+	defer client.Close()
+	ctx := context.Background()
+
+	cache := NewRedisCache[string](client, "fl:")
+	require.NoError(t, cache.Set(ctx, "key", "value", time.Millisecond))
+	time.Sleep(5 * time.Millisecond)
+
+	_, found, err := cache.Get(ctx, "key")
+	assert.NoError(t, err)
+	assert.False(t, found)
+}
+
+func TestRedisCache_Unreachable(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	addr := listener.Addr().String()
+	listener.Close()
+
+	client := NewRedisClient(RedisConfiguration{Addr: addr, DialTimeout: time.Second})
+	cache := NewRedisCache[string](client, "fl:")
+
+	_, found, err := cache.Get(context.Background(), "key")
+	assert.Error(t, err)
+	assert.False(t, found)
+}
+
+func TestRedisClient_ErrorReply(t *testing.T) {
+	server := newFakeRedis(t)
+	client := NewRedisClient(RedisConfiguration{Addr: server.addr(), DialTimeout: time.Second})
+	defer client.Close()
+
+	_, err := client.Do(context.Background(), "FLUSHALL")
+
+	var respErr respError
+	assert.True(t, errors.As(err, &respErr))
+	// the connection is still usable after an error reply
+	assert.NoError(t, client.Ping(context.Background()))
+}
+
+func TestTieredCache(t *testing.T) {
+	server := newFakeRedis(t)
+	client := NewRedisClient(RedisConfiguration{Addr: server.addr(), DialTimeout: time.Second})
+	defer client.Close()
+	ctx := context.Background()
+
+	shared := NewRedisCache[string](client, "fl:")
+	replicaA, _ := NewLRUCache[string](10, time.Now)
+	replicaB, _ := NewLRUCache[string](10, time.Now)
+	cacheA := NewTieredCache[string](replicaA, shared, time.Minute)
+	cacheB := NewTieredCache[string](replicaB, shared, time.Minute)
+
+	require.NoError(t, cacheA.Set(ctx, "key", "value", 0))
+
+	// replica B reads through to the shared tier and fills its local tier
+	got, found, err := cacheB.Get(ctx, "key")
+	assert.NoError(t, err)
+	assert.True(t, found)
+	assert.Equal(t, "value", got)
+
+	got, found, _ = replicaB.Get(ctx, "key")
+	assert.True(t, found)
+	assert.Equal(t, "value", got)
+}
+
+func TestLRUCache_Expiry(t *testing.T) {
+	now := time.Now()
+	cache, _ := NewLRUCache[string](10, func() time.Time { return now })
+	ctx := context.Background()
+
+	require.NoError(t, cache.Set(ctx, "short", "value", time.Minute))
+	require.NoError(t, cache.Set(ctx, "forever", "value", 0))
+	now = now.Add(time.Minute)
+
+	_, found, _ := cache.Get(ctx, "short")
+	assert.False(t, found)
+	_, found, _ = cache.Get(ctx, "forever")
+	assert.True(t, found)
+	assert.Len(t, cache.Entries(), 1)
+}
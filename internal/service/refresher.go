@@ -0,0 +1,185 @@
+package service
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/softstone1/fl/internal/model"
+)
+
+// minRefreshDelay is the shortest time between two refreshes of the same forecast
+const minRefreshDelay = 5 * time.Minute
+
+// refreshEntry tracks when a cached forecast is refreshed next and when it was last served
+type refreshEntry struct {
+	request    forecastRequest
+	due        time.Time
+	lastAccess time.Time
+}
+
+// refreshSchedule tracks the cached forecasts that are refreshed in the background
+type refreshSchedule struct {
+	mu             sync.Mutex
+	entries        map[string]*refreshEntry
+	updateInterval time.Duration
+	idleTimeout    time.Duration
+}
+
+func newRefreshSchedule(updateInterval, idleTimeout time.Duration) *refreshSchedule {
+	return &refreshSchedule{
+		entries:        make(map[string]*refreshEntry),
+		updateInterval: updateInterval,
+		idleTimeout:    idleTimeout,
+	}
+}
+
+// touch records that the forecast cached for the request was served, scheduling it if it is not yet tracked
+func (r *refreshSchedule) touch(request forecastRequest, forecast model.Forecast, now time.Time) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if entry, ok := r.entries[request.key]; ok {
+		entry.lastAccess = now
+		return
+	}
+	r.entries[request.key] = &refreshEntry{
+		request:    request,
+		due:        nextRefresh(forecast, now, r.updateInterval),
+		lastAccess: now,
+	}
+}
+
+// schedule reschedules the forecast cached for the request after it was fetched
+func (r *refreshSchedule) schedule(request forecastRequest, forecast model.Forecast, now time.Time) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	entry, ok := r.entries[request.key]
+	if !ok {
+		entry = &refreshEntry{request: request, lastAccess: now}
+		r.entries[request.key] = entry
+	}
+	entry.due = nextRefresh(forecast, now, r.updateInterval)
+}
+
+// due returns the forecasts to refresh now and drops the ones not served within the idle timeout.
+// The returned forecasts are pushed back by minRefreshDelay so that a failed refresh is retried later.
+func (r *refreshSchedule) due(now time.Time) []forecastRequest {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var requests []forecastRequest
+	for key, entry := range r.entries {
+		if now.Sub(entry.lastAccess) > r.idleTimeout {
+			delete(r.entries, key)
+			continue
+		}
+		if !entry.due.After(now) {
+			requests = append(requests, entry.request)
+			entry.due = now.Add(minRefreshDelay)
+		}
+	}
+	return requests
+}
+
+// next returns the earliest scheduled refresh, zero when nothing is scheduled
+func (r *refreshSchedule) next() time.Time {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var next time.Time
+	for _, entry := range r.entries {
+		if next.IsZero() || entry.due.Before(next) {
+			next = entry.due
+		}
+	}
+	return next
+}
+
+// nextRefresh returns when a forecast should be refreshed: when NWS is expected to issue its next
+// update, or when the current period ends if that comes first
+func nextRefresh(forecast model.Forecast, now time.Time, updateInterval time.Duration) time.Time {
+	updated := forecast.UpdateTime
+	if updated.IsZero() {
+		updated = forecast.GeneratedAt
+	}
+	next := updated.Add(updateInterval)
+	if earliest := now.Add(minRefreshDelay); next.Before(earliest) {
+		// the update is overdue, check again later instead of on every tick
+		next = earliest
+	}
+
+	for _, period := range forecast.Periods {
+		if period.EndTime.After(now) {
+			if period.EndTime.Before(next) {
+				next = period.EndTime
+			}
+			break
+		}
+	}
+	return next
+}
+
+// ForecastRefresher is implemented by services that refresh their cached forecasts in the background
+type ForecastRefresher interface {
+	RefreshDue(ctx context.Context) time.Time
+}
+
+// Refresher periodically refreshes the cached forecasts that are due
+type Refresher struct {
+	refresher     ForecastRefresher
+	checkInterval time.Duration
+	stopOnce      sync.Once
+	stop          chan struct{}
+	done          chan struct{}
+}
+
+// NewRefresher creates a Refresher waking up at least every checkInterval to look for new entries
+func NewRefresher(refresher ForecastRefresher, checkInterval time.Duration) *Refresher {
+	return &Refresher{
+		refresher:     refresher,
+		checkInterval: checkInterval,
+		stop:          make(chan struct{}),
+		done:          make(chan struct{}),
+	}
+}
+
+// Start runs the refresh loop in the background
+func (r *Refresher) Start() {
+	go func() {
+		defer close(r.done)
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		go func() {
+			select {
+			case <-r.stop:
+				cancel()
+			case <-ctx.Done():
+			}
+		}()
+
+		for {
+			wait := r.checkInterval
+			if next := r.refresher.RefreshDue(ctx); !next.IsZero() && time.Until(next) < wait {
+				wait = time.Until(next)
+			}
+			timer := time.NewTimer(wait)
+			select {
+			case <-timer.C:
+			case <-ctx.Done():
+				timer.Stop()
+				return
+			}
+		}
+	}()
+}
+
+// Stop ends the refresh loop
+func (r *Refresher) Stop(ctx context.Context) {
+	r.stopOnce.Do(func() {
+		close(r.stop)
+	})
+	select {
+	case <-r.done:
+	case <-ctx.Done():
+		log.Printf("Forecast refresher did not stop: %v", ctx.Err())
+	}
+}
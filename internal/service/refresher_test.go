@@ -0,0 +1,191 @@
+package service
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/softstone1/fl/internal/client"
+	"github.com/softstone1/fl/internal/model"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func TestNextRefresh(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		forecast model.Forecast
+		expected time.Time
+	}{
+		{
+			name: "Next NWS update before the period ends",
+			forecast: model.Forecast{
+				UpdateTime: now.Add(-30 * time.Minute),
+				Periods: []model.ForcastPeriod{
+					{StartTime: now.Add(-time.Hour), EndTime: now.Add(6 * time.Hour)},
+				},
+			},
+			expected: now.Add(30 * time.Minute),
+		},
+		{
+			name: "Period ends before the next NWS update",
+			forecast: model.Forecast{
+				UpdateTime: now.Add(-30 * time.Minute),
+				Periods: []model.ForcastPeriod{
+					{StartTime: now.Add(-time.Hour), EndTime: now.Add(10 * time.Minute)},
+					{StartTime: now.Add(10 * time.Minute), EndTime: now.Add(12 * time.Hour)},
+				},
+			},
+			expected: now.Add(10 * time.Minute),
+		},
+		{
+			name: "Overdue NWS update is checked again later",
+			forecast: model.Forecast{
+				UpdateTime: now.Add(-3 * time.Hour),
+				Periods: []model.ForcastPeriod{
+					{StartTime: now.Add(-time.Hour), EndTime: now.Add(6 * time.Hour)},
+				},
+			},
+			expected: now.Add(minRefreshDelay),
+		},
+		{
+			name: "Missing update time falls back to generatedAt",
+			forecast: model.Forecast{
+				GeneratedAt: now.Add(-45 * time.Minute),
+				Periods: []model.ForcastPeriod{
+					{StartTime: now.Add(-time.Hour), EndTime: now.Add(6 * time.Hour)},
+				},
+			},
+			expected: now.Add(15 * time.Minute),
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, nextRefresh(tc.forecast, now, time.Hour))
+		})
+	}
+}
+
+func TestRefreshDue_RefreshesServedForecasts(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockForecast := client.NewMockForecast(ctrl)
+	svc, _ := NewForecast(nil, mockForecast, time.Second, 10, WithBackgroundRefresh(time.Hour, time.Hour))
+
+	// the current period ends right away, so the forecast is due as soon as it was served
+	stale := &model.Forecast{
+		UpdateTime: time.Now(),
+		Periods: []model.ForcastPeriod{
+			{StartTime: time.Now().Add(-time.Hour), EndTime: time.Now().Add(50 * time.Millisecond), DetailedForecast: "Sunny"},
+			{StartTime: time.Now().Add(50 * time.Millisecond), EndTime: time.Now().Add(time.Hour), DetailedForecast: "Cloudy"},
+		},
+	}
+	fresh := &model.Forecast{
+		UpdateTime: time.Now(),
+		Periods: []model.ForcastPeriod{
+			{StartTime: time.Now().Add(-time.Hour), EndTime: time.Now().Add(time.Hour), DetailedForecast: "Rainy"},
+		},
+	}
+	gomock.InOrder(
+		mockForecast.EXPECT().GetForecastPeriods(gomock.Any(), "http://test.url").Return(stale, nil),
+		mockForecast.EXPECT().GetForecastPeriods(gomock.Any(), "http://test.url").Return(fresh, nil),
+	)
+
+	// the forecast is cached per grid cell and refreshed from its URL
+	request := newForecastRequest(model.PointMetadata{GridID: "TOP", GridX: 32, GridY: 81, Forecast: "http://test.url"}, standardForecast, model.UnitsUS)
+	got, err := svc.getForecast(context.Background(), request)
+	assert.NoError(t, err)
+	assert.Equal(t, "Sunny", got.Periods[0].DetailedForecast)
+
+	// nothing is due before the period ends
+	next := svc.RefreshDue(context.Background())
+	assert.WithinDuration(t, stale.Periods[0].EndTime, next, time.Millisecond)
+
+	time.Sleep(time.Until(next))
+	svc.RefreshDue(context.Background())
+
+	got, err = svc.getForecast(context.Background(), request)
+	assert.NoError(t, err)
+	assert.Equal(t, "Rainy", got.Periods[0].DetailedForecast)
+}
+
+func TestCallGroup_CoalescesConcurrentCalls(t *testing.T) {
+	var group callGroup[int]
+	var calls atomic.Int32
+	release := make(chan struct{})
+
+	var wg sync.WaitGroup
+	results := make([]int, 5)
+	for i := range results {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i], _ = group.Do(context.Background(), "key", func(context.Context) (int, error) {
+				calls.Add(1)
+				<-release
+				return 42, nil
+			})
+		}()
+	}
+
+	// give the goroutines time to join the in-flight call
+	time.Sleep(20 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	assert.Equal(t, int32(1), calls.Load())
+	assert.Equal(t, []int{42, 42, 42, 42, 42}, results)
+}
+
+func TestCallGroup_CallerCanceled(t *testing.T) {
+	var group callGroup[int]
+	release := make(chan struct{})
+	fn := func(ctx context.Context) (int, error) {
+		<-release
+		// the call outlives the caller that started it
+		return 42, ctx.Err()
+	}
+
+	leaderCtx, cancel := context.WithCancel(context.Background())
+	leaderDone := make(chan error)
+	go func() {
+		_, err := group.Do(leaderCtx, "key", fn)
+		leaderDone <- err
+	}()
+	time.Sleep(20 * time.Millisecond)
+
+	waiterDone := make(chan int)
+	go func() {
+		value, _ := group.Do(context.Background(), "key", fn)
+		waiterDone <- value
+	}()
+	time.Sleep(20 * time.Millisecond)
+
+	// the leader stops waiting on its own context, the waiter still gets the result
+	cancel()
+	assert.ErrorIs(t, <-leaderDone, context.Canceled)
+	close(release)
+	assert.Equal(t, 42, <-waiterDone)
+}
+
+func TestCallGroup_Panic(t *testing.T) {
+	group := callGroup[int]{timeout: time.Second}
+
+	_, err := group.Do(context.Background(), "key", func(context.Context) (int, error) {
+		panic("boom")
+	})
+	assert.EqualError(t, err, "call key panicked: boom")
+
+	// the key is released for the next call
+	value, err := group.Do(context.Background(), "key", func(context.Context) (int, error) {
+		return 42, nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 42, value)
+}
@@ -0,0 +1,180 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/softstone1/fl/internal/client"
+	"github.com/softstone1/fl/internal/model"
+)
+
+// ErrNoLocationInRegion is returned when no random location inside the region was drawn before the deadline
+// or within maxRegionAttempts draws
+var ErrNoLocationInRegion = errors.New("no random location found in the region")
+
+// maxRegionAttempts is how many random locations are drawn for a region before giving up, so that a narrow
+// region neither spins on a local location source nor floods a remote one
+const maxRegionAttempts = 100
+
+// stateMargin is how far, in degrees, outside of the bounding box of a state a location may be related
+// to a city of the state by NWS, about 50 km
+const stateMargin = 0.5
+
+// Region is an area made of US states, bounding boxes and polygons. A location is in the region
+// when it is in any of them.
+type Region struct {
+	// States are two letter US state codes, matched against the state of the city NWS relates a location to
+	States   []string
+	Boxes    []client.BoundingBox
+	Polygons client.Polygons
+}
+
+// LocationFilter restricts the random locations to the Include region, when it is not empty,
+// outside of the Exclude region
+type LocationFilter struct {
+	Include Region
+	Exclude Region
+}
+
+// WithLocationFilter draws random locations again until one passes the filter
+func WithLocationFilter(filter LocationFilter) Option {
+	return func(o *options) {
+		o.locationFilter = filter
+	}
+}
+
+// ParseRegion parses a region from comma separated US state codes or names and bounding boxes
+// separated by semicolons, see client.ParseBoundingBox
+func ParseRegion(states, boxes string) (Region, error) {
+	region := Region{}
+	for _, state := range strings.Split(states, ",") {
+		if strings.TrimSpace(state) == "" {
+			continue
+		}
+		code, ok := client.ParseState(state)
+		if !ok {
+			return Region{}, fmt.Errorf("unknown US state %q", strings.TrimSpace(state))
+		}
+		region.States = append(region.States, code)
+	}
+	for _, box := range strings.Split(boxes, ";") {
+		if strings.TrimSpace(box) == "" {
+			continue
+		}
+		bbox, err := client.ParseBoundingBox(box)
+		if err != nil {
+			return Region{}, err
+		}
+		region.Boxes = append(region.Boxes, bbox)
+	}
+	return region, nil
+}
+
+// empty reports whether the region has no area
+func (r Region) empty() bool {
+	return len(r.States) == 0 && len(r.Boxes) == 0 && len(r.Polygons) == 0
+}
+
+// key identifies the states and boxes of the region
+func (r Region) key() string {
+	boxes := make([]string, len(r.Boxes))
+	for i, box := range r.Boxes {
+		boxes[i] = strings.Join([]string{
+			strconv.FormatFloat(box.South, 'f', -1, 64),
+			strconv.FormatFloat(box.West, 'f', -1, 64),
+			strconv.FormatFloat(box.North, 'f', -1, 64),
+			strconv.FormatFloat(box.East, 'f', -1, 64),
+		}, ",")
+	}
+	return strings.Join(r.States, ",") + "|" + strings.Join(boxes, ";")
+}
+
+// containsArea reports whether the location is in one of the region bounding boxes or polygons
+func (r Region) containsArea(location model.Location) bool {
+	for _, box := range r.Boxes {
+		if box.Contains(location.Latitude, location.Longitude) {
+			return true
+		}
+	}
+	return r.Polygons.Contains(location.Latitude, location.Longitude)
+}
+
+// hasState reports whether the state is one of the region states
+func (r Region) hasState(state string) bool {
+	for _, code := range r.States {
+		if code == state {
+			return true
+		}
+	}
+	return false
+}
+
+// nearStates reports whether the location is within stateMargin of the bounding box of one of the region
+// states, the only locations NWS may relate to a city of them
+func (r Region) nearStates(location model.Location) bool {
+	for _, code := range r.States {
+		for _, box := range client.StateBoundingBoxes(code) {
+			if location.Latitude >= box.South-stateMargin && location.Latitude <= box.North+stateMargin &&
+				location.Longitude >= box.West-stateMargin && location.Longitude <= box.East+stateMargin {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// filteredLocation draws random locations until one is inside include, or the configured filter region
+// when nil, and outside the excluded region, giving up with ErrNoLocationInRegion when ctx is done
+// or after maxRegionAttempts draws
+func (s *forecast) filteredLocation(ctx context.Context, include *Region) (*model.Location, error) {
+	filter := s.locationFilter
+	if include != nil {
+		filter.Include = *include
+	}
+	for attempt := 0; attempt < maxRegionAttempts; attempt++ {
+		location, err := s.LocationClient.GetRandomLocation(ctx)
+		if err != nil {
+			if ctx.Err() != nil && (!filter.Include.empty() || !filter.Exclude.empty()) {
+				return nil, fmt.Errorf("%w: %v", ErrNoLocationInRegion, ctx.Err())
+			}
+			return nil, err
+		}
+		if s.passesFilter(ctx, *location, filter) {
+			return location, nil
+		}
+		if ctx.Err() != nil {
+			return nil, fmt.Errorf("%w: %v", ErrNoLocationInRegion, ctx.Err())
+		}
+	}
+	return nil, fmt.Errorf("%w: none of %d locations drawn", ErrNoLocationInRegion, maxRegionAttempts)
+}
+
+// passesFilter reports whether the location is in the filter Include region and outside its Exclude one.
+// The areas are checked first, the location state is only looked up upstream when the location is near
+// a state left to check, so that random locations far from the states cost no NWS call.
+func (s *forecast) passesFilter(ctx context.Context, location model.Location, filter LocationFilter) bool {
+	if filter.Exclude.containsArea(location) {
+		return false
+	}
+	included := filter.Include.empty() || filter.Include.containsArea(location)
+	if included && !filter.Exclude.nearStates(location) {
+		return true
+	}
+	if !included && !filter.Include.nearStates(location) {
+		return false
+	}
+	state := s.locationState(ctx, location)
+	return (included || filter.Include.hasState(state)) && !filter.Exclude.hasState(state)
+}
+
+// locationState returns the state of the city NWS relates the location to, empty outside of the NWS coverage
+func (s *forecast) locationState(ctx context.Context, location model.Location) string {
+	point, err := s.getPointMetadata(ctx, location.Latitude, location.Longitude)
+	if err != nil {
+		return ""
+	}
+	return point.RelativeLocation.State
+}
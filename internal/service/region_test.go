@@ -0,0 +1,176 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/softstone1/fl/internal/client"
+	"github.com/softstone1/fl/internal/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+var (
+	boulder = model.Location{Name: "Boulder", Latitude: 40.015, Longitude: -105.2705}
+	linn    = model.Location{Name: "Linn", Latitude: 39.7456, Longitude: -97.0892}
+	seattle = model.Location{Name: "Seattle", Latitude: 47.6062, Longitude: -122.3321}
+)
+
+func TestParseRegion(t *testing.T) {
+	region, err := ParseRegion("co, Wyoming", "37,-109,41,-102;45,-125,49,-117")
+
+	require.NoError(t, err)
+	assert.Equal(t, []string{"CO", "WY"}, region.States)
+	assert.Equal(t, []client.BoundingBox{
+		{South: 37, West: -109, North: 41, East: -102},
+		{South: 45, West: -125, North: 49, East: -117},
+	}, region.Boxes)
+
+	_, err = ParseRegion("Atlantis", "")
+	assert.EqualError(t, err, `unknown US state "Atlantis"`)
+
+	_, err = ParseRegion("", "41,-109,37,-102")
+	assert.Error(t, err)
+}
+
+func TestFilteredLocation_ResamplesUntilInRegion(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockLocation := client.NewMockLocation(ctrl)
+	mockForecast := client.NewMockForecast(ctrl)
+	colorado := Region{Boxes: []client.BoundingBox{{South: 37, West: -109, North: 41, East: -102}}}
+	svc, _ := NewForecast(mockLocation, mockForecast, time.Second, 10, WithLocationFilter(LocationFilter{Include: colorado}))
+
+	gomock.InOrder(
+		mockLocation.EXPECT().GetRandomLocation(gomock.Any()).Return(&seattle, nil),
+		mockLocation.EXPECT().GetRandomLocation(gomock.Any()).Return(&linn, nil),
+		mockLocation.EXPECT().GetRandomLocation(gomock.Any()).Return(&boulder, nil),
+	)
+
+	location, err := svc.filteredLocation(context.Background(), nil)
+
+	require.NoError(t, err)
+	assert.Equal(t, boulder, *location)
+}
+
+func TestFilteredLocation_StatesAndExclusions(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockLocation := client.NewMockLocation(ctrl)
+	mockForecast := client.NewMockForecast(ctrl)
+	// Boulder is excluded, the request only wants Kansas
+	exclude := Region{Boxes: []client.BoundingBox{{South: 39.9, West: -105.4, North: 40.1, East: -105.1}}}
+	svc, _ := NewForecast(mockLocation, mockForecast, time.Second, 10, WithLocationFilter(LocationFilter{Exclude: exclude}))
+
+	gomock.InOrder(
+		mockLocation.EXPECT().GetRandomLocation(gomock.Any()).Return(&boulder, nil),
+		mockLocation.EXPECT().GetRandomLocation(gomock.Any()).Return(&seattle, nil),
+		mockLocation.EXPECT().GetRandomLocation(gomock.Any()).Return(&linn, nil),
+	)
+	// Seattle is far from Kansas, its state is not looked up
+	mockForecast.EXPECT().GetPointMetadata(gomock.Any(), linn.Latitude, linn.Longitude).Return(
+		&model.PointMetadata{RelativeLocation: model.RelativeLocation{City: "Linn", State: "KS"}}, nil,
+	)
+
+	location, err := svc.filteredLocation(context.Background(), &Region{States: []string{"KS"}})
+
+	require.NoError(t, err)
+	assert.Equal(t, linn, *location)
+}
+
+func TestPassesFilter_LooksUpStatesNearby(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockForecast := client.NewMockForecast(ctrl)
+	svc, _ := NewForecast(nil, mockForecast, time.Second, 10)
+	filter := LocationFilter{Exclude: Region{States: []string{"CO"}}}
+
+	// only Boulder is near Colorado, so only its state is looked up
+	mockForecast.EXPECT().GetPointMetadata(gomock.Any(), boulder.Latitude, boulder.Longitude).Return(
+		&model.PointMetadata{RelativeLocation: model.RelativeLocation{City: "Boulder", State: "CO"}}, nil,
+	)
+
+	assert.True(t, svc.passesFilter(context.Background(), seattle, filter))
+	assert.True(t, svc.passesFilter(context.Background(), linn, filter))
+	assert.False(t, svc.passesFilter(context.Background(), boulder, filter))
+}
+
+func TestFilteredLocation_GivesUpAtDeadline(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockLocation := client.NewMockLocation(ctrl)
+	svc, _ := NewForecast(mockLocation, nil, time.Second, 10)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	mockLocation.EXPECT().GetRandomLocation(gomock.Any()).DoAndReturn(func(ctx context.Context) (*model.Location, error) {
+		if ctx.Err() != nil {
+			return nil, errors.New("failed to fetch location: " + ctx.Err().Error())
+		}
+		return &seattle, nil
+	}).MinTimes(1)
+
+	_, err := svc.filteredLocation(ctx, &Region{Boxes: []client.BoundingBox{{South: 37, West: -109, North: 41, East: -102}}})
+
+	assert.ErrorIs(t, err, ErrNoLocationInRegion)
+}
+
+func TestFilteredLocation_GivesUpAfterMaxAttempts(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockLocation := client.NewMockLocation(ctrl)
+	svc, _ := NewForecast(mockLocation, nil, time.Second, 10)
+
+	mockLocation.EXPECT().GetRandomLocation(gomock.Any()).Return(&seattle, nil).Times(maxRegionAttempts)
+
+	_, err := svc.filteredLocation(context.Background(), &Region{Boxes: []client.BoundingBox{{South: 37, West: -109, North: 41, East: -102}}})
+
+	assert.ErrorIs(t, err, ErrNoLocationInRegion)
+}
+
+func TestResolveLocation_SeedPoolPassesFilter(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockLocation := client.NewMockLocation(ctrl)
+	colorado := Region{Boxes: []client.BoundingBox{{South: 37, West: -109, North: 41, East: -102}}}
+	svc, _ := NewForecast(mockLocation, nil, time.Second, 10, WithSeedLocations([]model.Location{seattle}), WithLocationFilter(LocationFilter{Include: colorado}))
+
+	// the pool location outside of the configured filter is not picked, a location is drawn instead
+	mockLocation.EXPECT().GetRandomLocation(gomock.Any()).Return(&boulder, nil)
+
+	seed := int64(3)
+	location, err := svc.resolveLocation(context.Background(), nil, &seed, nil)
+	require.NoError(t, err)
+	assert.Equal(t, "Boulder", location.Name)
+}
+
+func TestResolveLocation_SeedReplaysPerRegion(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockLocation := client.NewMockLocation(ctrl)
+	svc, _ := NewForecast(mockLocation, nil, time.Second, 10, WithSeedLocations([]model.Location{seattle}))
+
+	colorado := &Region{Boxes: []client.BoundingBox{{South: 37, West: -109, North: 41, East: -102}}}
+	// the pool is not picked from for a region, which draws from the location source once
+	mockLocation.EXPECT().GetRandomLocation(gomock.Any()).Return(&boulder, nil).Times(1)
+
+	seed := int64(3)
+	for i := 0; i < 2; i++ {
+		location, err := svc.resolveLocation(context.Background(), nil, &seed, colorado)
+		require.NoError(t, err)
+		assert.Equal(t, "Boulder", location.Name)
+	}
+	location, err := svc.resolveLocation(context.Background(), nil, &seed, nil)
+	require.NoError(t, err)
+	assert.Equal(t, "Seattle", location.Name)
+}
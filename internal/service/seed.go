@@ -0,0 +1,144 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math/rand"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/softstone1/fl/internal/model"
+)
+
+// WithSeedLocations adds locations to the pool the seeded random locations are picked from,
+// next to the random locations fetched so far
+func WithSeedLocations(locations []model.Location) Option {
+	return func(o *options) {
+		o.seedLocations = append(o.seedLocations, locations...)
+	}
+}
+
+// WithSeedTTL sets how long a seed replays the random location it picked, 24 hours by default
+func WithSeedTTL(ttl time.Duration) Option {
+	return func(o *options) {
+		o.seedTTL = ttl
+	}
+}
+
+// locationPool is the pool of known locations seeds pick from. Its locations are kept sorted so that
+// a seed picks the same location whatever order they were added in.
+type locationPool struct {
+	mu        sync.Mutex
+	locations []model.Location
+	size      int
+}
+
+func newLocationPool(size int, locations []model.Location) *locationPool {
+	p := &locationPool{size: size}
+	for _, location := range locations {
+		p.add(location)
+	}
+	return p
+}
+
+// add inserts the location into the pool unless it is already known or the pool is full
+func (p *locationPool) add(location model.Location) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if len(p.locations) >= p.size {
+		return
+	}
+	location.Seed = nil
+	i := sort.Search(len(p.locations), func(i int) bool {
+		return !lessLocation(p.locations[i], location)
+	})
+	if i < len(p.locations) && p.locations[i] == location {
+		return
+	}
+	p.locations = append(p.locations, model.Location{})
+	copy(p.locations[i+1:], p.locations[i:])
+	p.locations[i] = location
+}
+
+// pick returns the location seed picks, false when the pool is empty
+func (p *locationPool) pick(seed int64) (model.Location, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if len(p.locations) == 0 {
+		return model.Location{}, false
+	}
+	return p.locations[rand.New(rand.NewSource(seed)).Intn(len(p.locations))], true
+}
+
+// lessLocation orders locations by latitude, longitude and name
+func lessLocation(a, b model.Location) bool {
+	if a.Latitude != b.Latitude {
+		return a.Latitude < b.Latitude
+	}
+	if a.Longitude != b.Longitude {
+		return a.Longitude < b.Longitude
+	}
+	return a.Name < b.Name
+}
+
+// seededLocation returns the random location seed picked before, or else picks one from the pool.
+// Without a seed, or while the pool is empty, it fetches a new random location and records the seed,
+// drawn when nil, replaying it. Seeds replay the locations of a region separately, which are drawn
+// from the location source rather than the pool.
+func (s *forecast) seededLocation(ctx context.Context, seed *int64, region *Region) (*model.Location, error) {
+	if seed != nil {
+		cached, found, err := s.seedsCache.Get(ctx, seedKey(*seed, region))
+		if err != nil {
+			log.Printf("Cache error: %v", err)
+		}
+		if found {
+			cached.Seed = seed
+			return &cached, nil
+		}
+		if region == nil {
+			// the pool holds the seed locations too, which the configured filter may exclude
+			if location, ok := s.locationPool.pick(*seed); ok && s.passesFilter(ctx, location, s.locationFilter) {
+				s.recordSeed(ctx, seedKey(*seed, region), location)
+				location.Seed = seed
+				return &location, nil
+			}
+		}
+	}
+
+	location, err := s.filteredLocation(ctx, region)
+	if err != nil {
+		return nil, fmt.Errorf("Stage 1 - FetchLocation error: %w", err)
+	}
+	if seed == nil {
+		drawn := rand.Int63()
+		seed = &drawn
+	}
+	if region == nil {
+		s.locationPool.add(*location)
+	}
+	s.recordSeed(ctx, seedKey(*seed, region), *location)
+
+	picked := *location
+	picked.Seed = seed
+	return &picked, nil
+}
+
+// recordSeed caches the location seed picked for the seed ttl, so that the seed keeps replaying it as the pool grows.
+// The seeds are part of the cache snapshots, so that they also replay their locations after a restart.
+func (s *forecast) recordSeed(ctx context.Context, key string, location model.Location) {
+	location.Seed = nil
+	if err := s.seedsCache.Set(ctx, key, location, s.seedTTL); err != nil {
+		log.Printf("Cache error: %v", err)
+	}
+}
+
+// seedKey identifies the location seed picks in the region, or in the configured filter region when nil
+func seedKey(seed int64, region *Region) string {
+	if region == nil {
+		return strconv.FormatInt(seed, 10)
+	}
+	return strconv.FormatInt(seed, 10) + "@" + region.key()
+}
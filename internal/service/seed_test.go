@@ -0,0 +1,99 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/softstone1/fl/internal/client"
+	"github.com/softstone1/fl/internal/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestResolveLocation_SeedPicksFromPool(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	pool := []model.Location{
+		{Name: "Denver", Latitude: 39.7392, Longitude: -104.9903},
+		{Name: "Boulder", Latitude: 40.015, Longitude: -105.2705},
+		{Name: "Linn", Latitude: 39.7456, Longitude: -97.0892},
+	}
+	// the location client is not called while the pool has locations
+	mockLocation := client.NewMockLocation(ctrl)
+	svc, _ := NewForecast(mockLocation, nil, time.Second, 10, WithSeedLocations(pool))
+	reversed, _ := NewForecast(mockLocation, nil, time.Second, 10, WithSeedLocations([]model.Location{pool[2], pool[1], pool[0]}))
+
+	seed := int64(42)
+	first, err := svc.resolveLocation(context.Background(), nil, &seed, nil)
+	require.NoError(t, err)
+	again, err := svc.resolveLocation(context.Background(), nil, &seed, nil)
+	require.NoError(t, err)
+	other, err := reversed.resolveLocation(context.Background(), nil, &seed, nil)
+	require.NoError(t, err)
+
+	assert.Equal(t, first, again)
+	assert.Equal(t, first, other)
+	assert.Equal(t, seed, *first.Seed)
+	assert.Contains(t, pool, model.Location{Name: first.Name, Latitude: first.Latitude, Longitude: first.Longitude})
+}
+
+func TestResolveLocation_UnseededEchoesSeed(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockLocation := client.NewMockLocation(ctrl)
+	svc, _ := NewForecast(mockLocation, nil, time.Second, 10)
+
+	mockLocation.EXPECT().GetRandomLocation(gomock.Any()).Return(&model.Location{Name: "Linn", Latitude: 39.7456, Longitude: -97.0892}, nil)
+	mockLocation.EXPECT().GetRandomLocation(gomock.Any()).Return(&model.Location{Name: "Denver", Latitude: 39.7392, Longitude: -104.9903}, nil)
+
+	drawn, err := svc.resolveLocation(context.Background(), nil, nil, nil)
+	require.NoError(t, err)
+	require.NotNil(t, drawn.Seed)
+
+	// a second random location grows the pool, the echoed seed still replays the first one
+	_, err = svc.resolveLocation(context.Background(), nil, nil, nil)
+	require.NoError(t, err)
+
+	replayed, err := svc.resolveLocation(context.Background(), nil, drawn.Seed, nil)
+	require.NoError(t, err)
+	assert.Equal(t, drawn, replayed)
+	assert.Equal(t, "Linn", replayed.Name)
+}
+
+func TestResolveLocation_SeedWithEmptyPool(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockLocation := client.NewMockLocation(ctrl)
+	svc, _ := NewForecast(mockLocation, nil, time.Second, 10)
+
+	mockLocation.EXPECT().GetRandomLocation(gomock.Any()).Return(&model.Location{Name: "Linn", Latitude: 39.7456, Longitude: -97.0892}, nil).Times(1)
+
+	seed := int64(7)
+	first, err := svc.resolveLocation(context.Background(), nil, &seed, nil)
+	require.NoError(t, err)
+	again, err := svc.resolveLocation(context.Background(), nil, &seed, nil)
+	require.NoError(t, err)
+
+	assert.Equal(t, first, again)
+	assert.Equal(t, seed, *again.Seed)
+}
+
+func TestRecordSeed_Expires(t *testing.T) {
+	now := time.Now()
+	svc, _ := NewForecast(nil, nil, time.Second, 10, WithClock(func() time.Time { return now }), WithSeedTTL(time.Hour))
+	ctx := context.Background()
+
+	svc.recordSeed(ctx, "42", model.Location{Name: "Linn", Latitude: 39.7456, Longitude: -97.0892})
+	_, found, _ := svc.seedsCache.Get(ctx, "42")
+	assert.True(t, found)
+
+	// seeds replay their location for the seed ttl only, so that they do not pile up
+	now = now.Add(time.Hour)
+	_, found, _ = svc.seedsCache.Get(ctx, "42")
+	assert.False(t, found)
+}
@@ -0,0 +1,218 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/softstone1/fl/internal/model"
+)
+
+// snapshotVersion must be bumped whenever the snapshot layout changes
+const snapshotVersion = 11
+
+// ErrIncompatibleSnapshot is returned when a snapshot was written with a different layout version
+var ErrIncompatibleSnapshot = errors.New("incompatible cache snapshot version")
+
+// cacheSnapshot is the on-disk representation of the forecast caches.
+// Entries are stored from least to most recently used so restoring them keeps the LRU order.
+type cacheSnapshot struct {
+	Version   int                               `json:"version"`
+	CreatedAt time.Time                         `json:"createdAt"`
+	Points    []CacheEntry[model.PointMetadata] `json:"points"`
+	Forecasts []CacheEntry[model.Forecast]      `json:"forecasts"`
+	Seeds     []CacheEntry[model.Location]      `json:"seeds"`
+}
+
+// SaveSnapshot writes the content of the in-process forecast caches to path.
+// Caches that cannot be enumerated, such as Redis, are left out.
+func (s *forecast) SaveSnapshot(path string) error {
+	snapshot := cacheSnapshot{
+		Version:   snapshotVersion,
+		CreatedAt: s.now(),
+	}
+	if cache, ok := s.pointsCache.(EnumerableCache[model.PointMetadata]); ok {
+		snapshot.Points = cache.Entries()
+	}
+	if cache, ok := s.forecastPeriodsCache.(EnumerableCache[model.Forecast]); ok {
+		snapshot.Forecasts = cache.Entries()
+	}
+	if cache, ok := s.seedsCache.(EnumerableCache[model.Location]); ok {
+		snapshot.Seeds = cache.Entries()
+	}
+
+	data, err := json.Marshal(snapshot)
+	if err != nil {
+		return fmt.Errorf("failed to encode cache snapshot: %w", err)
+	}
+
+	// write to a temporary file first so a crash never leaves a truncated snapshot behind
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to create cache snapshot: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write cache snapshot: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write cache snapshot: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("failed to replace cache snapshot: %w", err)
+	}
+	return nil
+}
+
+// LoadSnapshot restores the unexpired entries of the snapshot at path and returns how many were restored.
+// Point metadata is only restored from snapshots younger than maxAge, forecast periods only while
+// at least one period has not ended yet, seeds until their ttl. A missing snapshot file is not an error.
+func (s *forecast) LoadSnapshot(path string, maxAge time.Duration) (int, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to read cache snapshot: %w", err)
+	}
+
+	// check the version before decoding the rest so layout changes never get misread
+	var header struct {
+		Version int `json:"version"`
+	}
+	if err := json.Unmarshal(data, &header); err != nil {
+		return 0, fmt.Errorf("failed to decode cache snapshot: %w", err)
+	}
+	if header.Version != snapshotVersion {
+		return 0, fmt.Errorf("%w: got %d, want %d", ErrIncompatibleSnapshot, header.Version, snapshotVersion)
+	}
+
+	snapshot := cacheSnapshot{}
+	if err := json.Unmarshal(data, &snapshot); err != nil {
+		return 0, fmt.Errorf("failed to decode cache snapshot: %w", err)
+	}
+
+	ctx := context.Background()
+	restored := 0
+	current := s.now()
+	if current.Sub(snapshot.CreatedAt) <= maxAge {
+		for _, entry := range snapshot.Points {
+			ttl, ok := remainingTTL(entry.ExpiresAt, current)
+			if !ok {
+				continue
+			}
+			if err := s.pointsCache.Set(ctx, entry.Key, entry.Value, ttl); err != nil {
+				return restored, fmt.Errorf("failed to restore cache snapshot: %w", err)
+			}
+			restored++
+		}
+	}
+	for _, entry := range snapshot.Forecasts {
+		ttl, ok := remainingTTL(entry.ExpiresAt, current)
+		if !ok || !hasUnexpiredPeriod(entry.Value.Periods, current) {
+			continue
+		}
+		if err := s.forecastPeriodsCache.Set(ctx, entry.Key, entry.Value, ttl); err != nil {
+			return restored, fmt.Errorf("failed to restore cache snapshot: %w", err)
+		}
+		restored++
+	}
+	for _, entry := range snapshot.Seeds {
+		ttl, ok := remainingTTL(entry.ExpiresAt, current)
+		if !ok {
+			continue
+		}
+		if err := s.seedsCache.Set(ctx, entry.Key, entry.Value, ttl); err != nil {
+			return restored, fmt.Errorf("failed to restore cache snapshot: %w", err)
+		}
+		restored++
+	}
+	return restored, nil
+}
+
+// remainingTTL returns the ttl left until expiresAt and false once it passed.
+// A zero expiresAt never expires.
+func remainingTTL(expiresAt, now time.Time) (time.Duration, bool) {
+	if expiresAt.IsZero() {
+		return 0, true
+	}
+	ttl := expiresAt.Sub(now)
+	return ttl, ttl > 0
+}
+
+// hasUnexpiredPeriod reports whether any of the periods ends after now
+func hasUnexpiredPeriod(periods []model.ForcastPeriod, now time.Time) bool {
+	for _, period := range periods {
+		if period.EndTime.After(now) {
+			return true
+		}
+	}
+	return false
+}
+
+// SnapshotSaver is implemented by services whose caches can be written to disk
+type SnapshotSaver interface {
+	SaveSnapshot(path string) error
+}
+
+// Snapshotter periodically saves cache snapshots and writes a final one when stopped
+type Snapshotter struct {
+	saver    SnapshotSaver
+	path     string
+	interval time.Duration
+	stopOnce sync.Once
+	stop     chan struct{}
+	done     chan struct{}
+}
+
+func NewSnapshotter(saver SnapshotSaver, path string, interval time.Duration) *Snapshotter {
+	return &Snapshotter{
+		saver:    saver,
+		path:     path,
+		interval: interval,
+		stop:     make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+}
+
+// Start runs the periodic snapshot loop in the background
+func (s *Snapshotter) Start() {
+	go func() {
+		defer close(s.done)
+		ticker := time.NewTicker(s.interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				if err := s.saver.SaveSnapshot(s.path); err != nil {
+					log.Printf("Cache snapshot error: %v", err)
+				}
+			case <-s.stop:
+				return
+			}
+		}
+	}()
+}
+
+// Stop ends the periodic loop and writes a final snapshot
+func (s *Snapshotter) Stop(ctx context.Context) {
+	s.stopOnce.Do(func() {
+		close(s.stop)
+	})
+	select {
+	case <-s.done:
+	case <-ctx.Done():
+		log.Printf("Cache snapshot loop did not stop: %v", ctx.Err())
+	}
+	if err := s.saver.SaveSnapshot(s.path); err != nil {
+		log.Printf("Cache snapshot error: %v", err)
+	}
+}
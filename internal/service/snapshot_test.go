@@ -0,0 +1,93 @@
+package service
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/softstone1/fl/internal/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSnapshot_RoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cache.json")
+
+	ctx := context.Background()
+	src, _ := NewForecast(nil, nil, time.Second, 10)
+	require.NoError(t, src.pointsCache.Set(ctx, "1.0000,1.0000", model.PointMetadata{Forecast: "http://test.url"}, 0))
+	require.NoError(t, src.forecastPeriodsCache.Set(ctx, "http://test.url", model.Forecast{
+		Periods: []model.ForcastPeriod{
+			{
+				StartTime:        time.Now().Add(-time.Hour),
+				EndTime:          time.Now().Add(time.Hour),
+				DetailedForecast: "Sunny",
+			},
+		},
+	}, time.Hour))
+	require.NoError(t, src.forecastPeriodsCache.Set(ctx, "http://expired.url", model.Forecast{
+		Periods: []model.ForcastPeriod{
+			{
+				StartTime:        time.Now().Add(-2 * time.Hour),
+				EndTime:          time.Now().Add(-time.Hour),
+				DetailedForecast: "Rainy",
+			},
+		},
+	}, 0))
+	src.recordSeed(ctx, "42", model.Location{Name: "Linn", Latitude: 39.7456, Longitude: -97.0892})
+	require.NoError(t, src.SaveSnapshot(path))
+
+	dst, _ := NewForecast(nil, nil, time.Second, 10)
+	restored, err := dst.LoadSnapshot(path, time.Hour)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 3, restored)
+	point, found, _ := dst.pointsCache.Get(ctx, "1.0000,1.0000")
+	assert.True(t, found)
+	assert.Equal(t, "http://test.url", point.Forecast)
+	_, found, _ = dst.forecastPeriodsCache.Get(ctx, "http://test.url")
+	assert.True(t, found)
+	_, found, _ = dst.forecastPeriodsCache.Get(ctx, "http://expired.url")
+	assert.False(t, found)
+	// the seeds replay their locations after a restart
+	seeded, found, _ := dst.seedsCache.Get(ctx, "42")
+	assert.True(t, found)
+	assert.Equal(t, "Linn", seeded.Name)
+}
+
+func TestSnapshot_StalePointsSkipped(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cache.json")
+	data := `{"version":11,"createdAt":"2000-01-01T00:00:00Z","points":[{"key":"1.0000,1.0000","value":{"forecast":"http://test.url"}}]}`
+	require.NoError(t, os.WriteFile(path, []byte(data), 0o600))
+
+	svc, _ := NewForecast(nil, nil, time.Second, 10)
+	restored, err := svc.LoadSnapshot(path, time.Hour)
+
+	assert.NoError(t, err)
+	assert.Zero(t, restored)
+	_, found, _ := svc.pointsCache.Get(context.Background(), "1.0000,1.0000")
+	assert.False(t, found)
+}
+
+func TestSnapshot_IncompatibleVersion(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cache.json")
+	// version 1 snapshots stored the forecast periods as a bare list
+	data := `{"version":1,"forecastPeriods":[{"key":"http://test.url","value":[]}]}`
+	require.NoError(t, os.WriteFile(path, []byte(data), 0o600))
+
+	svc, _ := NewForecast(nil, nil, time.Second, 10)
+	restored, err := svc.LoadSnapshot(path, time.Hour)
+
+	assert.ErrorIs(t, err, ErrIncompatibleSnapshot)
+	assert.Zero(t, restored)
+}
+
+func TestSnapshot_MissingFile(t *testing.T) {
+	svc, _ := NewForecast(nil, nil, time.Second, 10)
+	restored, err := svc.LoadSnapshot(filepath.Join(t.TempDir(), "missing.json"), time.Hour)
+
+	assert.NoError(t, err)
+	assert.Zero(t, restored)
+}
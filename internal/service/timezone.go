@@ -0,0 +1,78 @@
+package service
+
+import (
+	"log"
+	"time"
+
+	"github.com/softstone1/fl/internal/model"
+)
+
+// resolveZone returns the zone to render times in: zone when the query forces one, then the service
+// default, then the location time zone. It returns nil, keeping the NWS offsets, when the location
+// time zone is unknown.
+func (s *forecast) resolveZone(zone *time.Location, locationTimeZone string) *time.Location {
+	if zone != nil {
+		return zone
+	}
+	if s.zone != nil {
+		return s.zone
+	}
+	if locationTimeZone == "" {
+		return nil
+	}
+	zone, err := time.LoadLocation(locationTimeZone)
+	if err != nil {
+		log.Printf("Unknown location time zone %q: %v", locationTimeZone, err)
+		return nil
+	}
+	return zone
+}
+
+// withTimeZone returns location with its time zone set, when known
+func withTimeZone(location model.Location, timeZone string) model.Location {
+	if timeZone != "" {
+		location.TimeZone = timeZone
+	}
+	return location
+}
+
+// periodsIn sets the start and end times of the periods in zone, in place
+func periodsIn(periods []model.ForcastPeriod, zone *time.Location) {
+	if zone == nil {
+		return
+	}
+	for i := range periods {
+		periods[i].StartTime = periods[i].StartTime.In(zone)
+		periods[i].EndTime = periods[i].EndTime.In(zone)
+	}
+}
+
+// alertsIn returns a copy of the alerts with their onset and expiry times in zone
+func alertsIn(alerts []model.Alert, zone *time.Location) []model.Alert {
+	if zone == nil || alerts == nil {
+		return alerts
+	}
+	localized := make([]model.Alert, 0, len(alerts))
+	for _, alert := range alerts {
+		alert.Onset = timeIn(alert.Onset, zone)
+		alert.Expires = timeIn(alert.Expires, zone)
+		localized = append(localized, alert)
+	}
+	return localized
+}
+
+// startOfHour returns the start of the hour of t in zone, or in the zone of t when zone is nil. Unlike
+// t.Truncate, which aligns to UTC, the hours of the zones offset by a fraction of an hour, such as India
+// or Newfoundland, start at their own whole hours.
+func startOfHour(t time.Time, zone *time.Location) time.Time {
+	t = timeIn(t, zone)
+	return t.Add(-time.Duration(t.Minute())*time.Minute - time.Duration(t.Second())*time.Second - time.Duration(t.Nanosecond()))
+}
+
+// timeIn returns t in zone, leaving zero times unchanged
+func timeIn(t time.Time, zone *time.Location) time.Time {
+	if t.IsZero() || zone == nil {
+		return t
+	}
+	return t.In(zone)
+}
@@ -0,0 +1,69 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/softstone1/fl/internal/client"
+	"github.com/softstone1/fl/internal/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestGetForecastPeriods_TimeZone(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	// NWS returns the periods with the offset of the forecast office
+	start := time.Date(2025, 1, 1, 6, 0, 0, 0, time.FixedZone("", -6*3600))
+
+	tests := []struct {
+		name          string
+		zone          *time.Location
+		opts          []Option
+		expectedStart string
+	}{
+		{
+			name:          "Location time zone",
+			expectedStart: "2025-01-01T07:00:00-05:00",
+		},
+		{
+			name:          "Query time zone",
+			zone:          time.UTC,
+			expectedStart: "2025-01-01T12:00:00Z",
+		},
+		{
+			name:          "Service time zone",
+			opts:          []Option{WithTimeZone(time.UTC)},
+			expectedStart: "2025-01-01T12:00:00Z",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockForecast := client.NewMockForecast(ctrl)
+			opts := append([]Option{WithClock(func() time.Time { return now })}, tc.opts...)
+			svc, _ := NewForecast(nil, mockForecast, time.Second, 10, opts...)
+
+			mockForecast.EXPECT().GetPointMetadata(gomock.Any(), 1.0, 1.0).Return(
+				&model.PointMetadata{Forecast: "http://test.url", TimeZone: "America/New_York"}, nil,
+			)
+			mockForecast.EXPECT().GetForecastPeriods(gomock.Any(), "http://test.url").Return(
+				&model.Forecast{Periods: []model.ForcastPeriod{{StartTime: start, EndTime: start.Add(12 * time.Hour)}}}, nil,
+			)
+
+			resp, err := svc.GetForecastPeriods(context.Background(), PeriodsQuery{
+				Location: &model.Location{Latitude: 1.0, Longitude: 1.0},
+				TimeZone: tc.zone,
+			})
+
+			require.NoError(t, err)
+			require.Len(t, resp.Periods, 1)
+			assert.Equal(t, "America/New_York", resp.Location.TimeZone)
+			assert.Equal(t, tc.expectedStart, resp.Periods[0].StartTime.Format(time.RFC3339))
+		})
+	}
+}
@@ -0,0 +1,139 @@
+package service
+
+import (
+	"math"
+	"regexp"
+	"strconv"
+
+	"github.com/softstone1/fl/internal/model"
+)
+
+const (
+	kmPerMile  = 1.609344
+	mmPerInch  = 25.4
+	kmhPerMps  = 3.6
+	fahrenheit = "F"
+	celsius    = "C"
+)
+
+// unitsForecastURL returns the forecast URL asking NWS for units. NWS defaults to US units,
+// so their URL is left unchanged and keeps sharing its cache entries.
+func unitsForecastURL(forecastURL string, units model.Units) string {
+	if units == model.UnitsSI {
+		return forecastURL + "?units=si"
+	}
+	return forecastURL
+}
+
+// convertPeriods returns a copy of the periods with their temperatures and wind speeds in units
+func convertPeriods(periods []model.ForcastPeriod, units model.Units) []model.ForcastPeriod {
+	converted := make([]model.ForcastPeriod, 0, len(periods))
+	for _, period := range periods {
+		converted = append(converted, convertPeriod(period, units))
+	}
+	return converted
+}
+
+// convertPeriod converts the period temperature and wind speed to units, when NWS returned them in the other system
+func convertPeriod(period model.ForcastPeriod, units model.Units) model.ForcastPeriod {
+	switch {
+	case units == model.UnitsSI && period.TemperatureUnit == fahrenheit:
+		period.Temperature = round(fahrenheitToCelsius(period.Temperature), 1)
+		period.TemperatureUnit = celsius
+	case units == model.UnitsUS && period.TemperatureUnit == celsius:
+		period.Temperature = round(celsiusToFahrenheit(period.Temperature), 1)
+		period.TemperatureUnit = fahrenheit
+	}
+	period.WindSpeed = convertWindSpeedText(period.WindSpeed, units)
+	return period
+}
+
+// windSpeedText matches the speeds of NWS wind texts such as "10 mph" or "5 to 15 km/h"
+var windSpeedText = regexp.MustCompile(`(\d+(?:\.\d+)?)( to (\d+(?:\.\d+)?))? (mph|km/h)`)
+
+// convertWindSpeedText converts the speeds of an NWS wind text to units
+func convertWindSpeedText(text string, units model.Units) string {
+	return windSpeedText.ReplaceAllStringFunc(text, func(match string) string {
+		parts := windSpeedText.FindStringSubmatch(match)
+		from, to, unit := "mph", "km/h", kmPerMile
+		if units == model.UnitsUS {
+			from, to, unit = "km/h", "mph", 1/kmPerMile
+		}
+		if parts[4] != from {
+			return match
+		}
+		convert := func(speed string) string {
+			value, _ := strconv.ParseFloat(speed, 64)
+			return strconv.FormatFloat(math.Round(value*unit), 'f', -1, 64)
+		}
+		if parts[3] == "" {
+			return convert(parts[1]) + " " + to
+		}
+		return convert(parts[1]) + " to " + convert(parts[3]) + " " + to
+	})
+}
+
+// convertObservation converts the observation temperature and wind speed to units
+func convertObservation(observation model.Observation, units model.Units) model.Observation {
+	observation.Temperature = convertQuantity(observation.Temperature, units)
+	observation.WindSpeed = convertQuantity(observation.WindSpeed, units)
+	return observation
+}
+
+// NWS unit codes of the quantities converted between unit systems
+const (
+	unitCelsius    = "wmoUnit:degC"
+	unitFahrenheit = "wmoUnit:degF"
+	unitKmh        = "wmoUnit:km_h-1"
+	unitMps        = "wmoUnit:m_s-1"
+	unitMph        = "wmoUnit:mi_h-1"
+	unitMillimeter = "wmoUnit:mm"
+	unitInch       = "wmoUnit:in"
+	unitMeter      = "wmoUnit:m"
+)
+
+// convertQuantity converts a temperature, speed or precipitation amount to units. Other quantities,
+// and quantities without a value, are returned unchanged.
+func convertQuantity(q model.QuantitativeValue, units model.Units) model.QuantitativeValue {
+	if q.Value == nil {
+		return q
+	}
+	value := *q.Value
+	switch {
+	case units == model.UnitsUS && q.UnitCode == unitCelsius:
+		value, q.UnitCode = celsiusToFahrenheit(value), unitFahrenheit
+	case units == model.UnitsUS && q.UnitCode == unitKmh:
+		value, q.UnitCode = value/kmPerMile, unitMph
+	case units == model.UnitsUS && q.UnitCode == unitMps:
+		value, q.UnitCode = value*kmhPerMps/kmPerMile, unitMph
+	case units == model.UnitsUS && q.UnitCode == unitMillimeter:
+		value, q.UnitCode = value/mmPerInch, unitInch
+	case units == model.UnitsSI && q.UnitCode == unitFahrenheit:
+		value, q.UnitCode = fahrenheitToCelsius(value), unitCelsius
+	case units == model.UnitsSI && q.UnitCode == unitMph:
+		value, q.UnitCode = value*kmPerMile, unitKmh
+	case units == model.UnitsSI && q.UnitCode == unitMps:
+		value, q.UnitCode = value*kmhPerMps, unitKmh
+	case units == model.UnitsSI && q.UnitCode == unitInch:
+		value, q.UnitCode = value*mmPerInch, unitMillimeter
+	default:
+		return q
+	}
+	value = round(value, 2)
+	q.Value = &value
+	return q
+}
+
+func celsiusToFahrenheit(c float64) float64 {
+	return c*9/5 + 32
+}
+
+func fahrenheitToCelsius(f float64) float64 {
+	return (f - 32) * 5 / 9
+}
+
+// round rounds value to the given number of decimals
+func round(value float64, decimals int) float64 {
+	scale := math.Pow(10, float64(decimals))
+	return math.Round(value*scale) / scale
+}
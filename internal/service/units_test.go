@@ -0,0 +1,134 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/softstone1/fl/internal/client"
+	"github.com/softstone1/fl/internal/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestConvertPeriod(t *testing.T) {
+	tests := []struct {
+		name     string
+		period   model.ForcastPeriod
+		units    model.Units
+		expected model.ForcastPeriod
+	}{
+		{
+			name:     "US to SI",
+			period:   model.ForcastPeriod{Temperature: 50, TemperatureUnit: "F", WindSpeed: "5 to 10 mph"},
+			units:    model.UnitsSI,
+			expected: model.ForcastPeriod{Temperature: 10, TemperatureUnit: "C", WindSpeed: "8 to 16 km/h"},
+		},
+		{
+			name:     "SI to US",
+			period:   model.ForcastPeriod{Temperature: -3, TemperatureUnit: "C", WindSpeed: "20 km/h"},
+			units:    model.UnitsUS,
+			expected: model.ForcastPeriod{Temperature: 26.6, TemperatureUnit: "F", WindSpeed: "12 mph"},
+		},
+		{
+			name:     "Already in the requested units",
+			period:   model.ForcastPeriod{Temperature: 41, TemperatureUnit: "F", WindSpeed: "10 mph"},
+			units:    model.UnitsUS,
+			expected: model.ForcastPeriod{Temperature: 41, TemperatureUnit: "F", WindSpeed: "10 mph"},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, convertPeriod(tc.period, tc.units))
+		})
+	}
+}
+
+func TestConvertQuantity(t *testing.T) {
+	value := func(v float64) *float64 { return &v }
+	tests := []struct {
+		name     string
+		quantity model.QuantitativeValue
+		units    model.Units
+		expected model.QuantitativeValue
+	}{
+		{
+			name:     "Celsius to Fahrenheit",
+			quantity: model.QuantitativeValue{Value: value(-40), UnitCode: "wmoUnit:degC"},
+			units:    model.UnitsUS,
+			expected: model.QuantitativeValue{Value: value(-40), UnitCode: "wmoUnit:degF"},
+		},
+		{
+			name:     "Kilometers per hour to miles per hour",
+			quantity: model.QuantitativeValue{Value: value(16.09344), UnitCode: "wmoUnit:km_h-1"},
+			units:    model.UnitsUS,
+			expected: model.QuantitativeValue{Value: value(10), UnitCode: "wmoUnit:mi_h-1"},
+		},
+		{
+			name:     "Meters per second to kilometers per hour",
+			quantity: model.QuantitativeValue{Value: value(5), UnitCode: "wmoUnit:m_s-1"},
+			units:    model.UnitsSI,
+			expected: model.QuantitativeValue{Value: value(18), UnitCode: "wmoUnit:km_h-1"},
+		},
+		{
+			name:     "Inches to millimeters",
+			quantity: model.QuantitativeValue{Value: value(0.5), UnitCode: "wmoUnit:in"},
+			units:    model.UnitsSI,
+			expected: model.QuantitativeValue{Value: value(12.7), UnitCode: "wmoUnit:mm"},
+		},
+		{
+			name:     "Missing values are left alone",
+			quantity: model.QuantitativeValue{UnitCode: "wmoUnit:degC"},
+			units:    model.UnitsUS,
+			expected: model.QuantitativeValue{UnitCode: "wmoUnit:degC"},
+		},
+		{
+			name:     "Percentages are not converted",
+			quantity: model.QuantitativeValue{Value: value(20), UnitCode: "wmoUnit:percent"},
+			units:    model.UnitsUS,
+			expected: model.QuantitativeValue{Value: value(20), UnitCode: "wmoUnit:percent"},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, convertQuantity(tc.quantity, tc.units))
+		})
+	}
+}
+
+func TestGetForecastPeriods_SIUnits(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockForecast := client.NewMockForecast(ctrl)
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	svc, _ := NewForecast(nil, mockForecast, time.Second, 10, WithClock(func() time.Time { return now }))
+
+	mockForecast.EXPECT().GetPointMetadata(gomock.Any(), 1.0, 1.0).Return(
+		&model.PointMetadata{Forecast: "http://test.url"}, nil,
+	)
+	// NWS is asked for SI units, the periods it still returns in US units are converted locally
+	mockForecast.EXPECT().GetForecastPeriods(gomock.Any(), "http://test.url?units=si").Return(
+		&model.Forecast{
+			Periods: []model.ForcastPeriod{
+				{StartTime: now, EndTime: now.Add(12 * time.Hour), Temperature: 2, TemperatureUnit: "C", WindSpeed: "10 km/h"},
+				{StartTime: now.Add(12 * time.Hour), EndTime: now.Add(24 * time.Hour), Temperature: 32, TemperatureUnit: "F", WindSpeed: "10 mph"},
+			},
+		}, nil,
+	)
+
+	resp, err := svc.GetForecastPeriods(context.Background(), PeriodsQuery{
+		Location: &model.Location{Latitude: 1.0, Longitude: 1.0},
+		Units:    model.UnitsSI,
+	})
+
+	require.NoError(t, err)
+	require.Len(t, resp.Periods, 2)
+	assert.Equal(t, "10 km/h", resp.Periods[0].WindSpeed)
+	assert.Equal(t, 0.0, resp.Periods[1].Temperature)
+	assert.Equal(t, "C", resp.Periods[1].TemperatureUnit)
+	assert.Equal(t, "16 km/h", resp.Periods[1].WindSpeed)
+}
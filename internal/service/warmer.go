@@ -0,0 +1,204 @@
+package service
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/softstone1/fl/internal/model"
+)
+
+// ForecastWarmer is implemented by services that can prefetch the forecast of a location
+type ForecastWarmer interface {
+	Warm(ctx context.Context, lat, lng float64) (time.Time, error)
+}
+
+// WarmerConfiguration holds the settings for the cache Warmer
+type WarmerConfiguration struct {
+	// Interval between full warm-ups of all locations
+	Interval time.Duration
+	// RateLimit is the maximum number of locations warmed per second
+	RateLimit float64
+	// RefreshLead is how long before the current period ends its location is warmed again
+	RefreshLead time.Duration
+}
+
+// Warmer keeps the forecasts of a fixed set of locations cached. Every location is warmed at startup,
+// every Interval, and shortly before its current forecast period ends.
+type Warmer struct {
+	warmer    ForecastWarmer
+	locations []model.Location
+	config    WarmerConfiguration
+	stopOnce  sync.Once
+	stop      chan struct{}
+	done      chan struct{}
+}
+
+func NewWarmer(warmer ForecastWarmer, locations []model.Location, config WarmerConfiguration) *Warmer {
+	return &Warmer{
+		warmer:    warmer,
+		locations: locations,
+		config:    config,
+		stop:      make(chan struct{}),
+		done:      make(chan struct{}),
+	}
+}
+
+// Start runs the warm-up loop in the background
+func (w *Warmer) Start() {
+	go func() {
+		defer close(w.done)
+		w.run()
+	}()
+}
+
+// Stop ends the warm-up loop
+func (w *Warmer) Stop(ctx context.Context) {
+	w.stopOnce.Do(func() {
+		close(w.stop)
+	})
+	select {
+	case <-w.done:
+	case <-ctx.Done():
+		log.Printf("Cache warmer did not stop: %v", ctx.Err())
+	}
+}
+
+func (w *Warmer) run() {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		select {
+		case <-w.stop:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	throttle := time.NewTicker(time.Duration(float64(time.Second) / w.config.RateLimit))
+	defer throttle.Stop()
+
+	// a zero due time means the location is warmed right away
+	due := make([]time.Time, len(w.locations))
+	nextFull := time.Now().Add(w.config.Interval)
+	for {
+		current := time.Now()
+		if !current.Before(nextFull) {
+			for i := range due {
+				due[i] = time.Time{}
+			}
+			nextFull = current.Add(w.config.Interval)
+		}
+
+		for i, location := range w.locations {
+			if due[i].After(current) {
+				continue
+			}
+			select {
+			case <-throttle.C:
+			case <-ctx.Done():
+				return
+			}
+			due[i] = w.warm(ctx, location)
+		}
+
+		wake := nextFull
+		for _, next := range due {
+			if next.Before(wake) {
+				wake = next
+			}
+		}
+		timer := time.NewTimer(time.Until(wake))
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		}
+	}
+}
+
+// warm prefetches the forecast of location and returns when it is due again
+func (w *Warmer) warm(ctx context.Context, location model.Location) time.Time {
+	endTime, err := w.warmer.Warm(ctx, location.Latitude, location.Longitude)
+	if err != nil {
+		if ctx.Err() == nil {
+			log.Printf("Cache warm-up of %f,%f failed: %v", location.Latitude, location.Longitude, err)
+		}
+		// retry with the next full warm-up
+		return time.Now().Add(w.config.Interval)
+	}
+
+	next := endTime.Add(-w.config.RefreshLead)
+	if earliest := time.Now().Add(w.config.RefreshLead); next.Before(earliest) {
+		// the period is about to end, warm again once the next one started
+		next = endTime.Add(time.Second)
+	}
+	return next
+}
+
+// LoadWarmLocations parses the locations to warm from a list of "lat,lng[,name]" entries separated
+// by semicolons and, when path is not empty, from a file with one entry per line.
+// Blank lines and lines starting with # are ignored.
+func LoadWarmLocations(list, path string) ([]model.Location, error) {
+	locations, err := parseWarmLocations(strings.NewReader(strings.ReplaceAll(list, ";", "\n")))
+	if err != nil {
+		return nil, err
+	}
+	if path == "" {
+		return locations, nil
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open warm locations file: %w", err)
+	}
+	defer file.Close()
+
+	fileLocations, err := parseWarmLocations(file)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return append(locations, fileLocations...), nil
+}
+
+func parseWarmLocations(r io.Reader) ([]model.Location, error) {
+	var locations []model.Location
+	scanner := bufio.NewScanner(r)
+	line := 0
+	for scanner.Scan() {
+		line++
+		entry := strings.TrimSpace(scanner.Text())
+		if entry == "" || strings.HasPrefix(entry, "#") {
+			continue
+		}
+		fields := strings.SplitN(entry, ",", 3)
+		if len(fields) < 2 {
+			return nil, fmt.Errorf("line %d: expected lat,lng[,name], got %q", line, entry)
+		}
+		lat, err := strconv.ParseFloat(strings.TrimSpace(fields[0]), 64)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: invalid latitude: %w", line, err)
+		}
+		lng, err := strconv.ParseFloat(strings.TrimSpace(fields[1]), 64)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: invalid longitude: %w", line, err)
+		}
+		location := model.Location{Latitude: lat, Longitude: lng}
+		if len(fields) == 3 {
+			location.Name = strings.TrimSpace(fields[2])
+		}
+		locations = append(locations, location)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read warm locations: %w", err)
+	}
+	return locations, nil
+}
@@ -0,0 +1,133 @@
+package service
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/softstone1/fl/internal/client"
+	"github.com/softstone1/fl/internal/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+// recordingWarmer counts the warm-ups per location and reports a period ending after periodLength
+type recordingWarmer struct {
+	mu           sync.Mutex
+	calls        map[model.Location]int
+	periodLength time.Duration
+}
+
+func (r *recordingWarmer) Warm(_ context.Context, lat, lng float64) (time.Time, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.calls[model.Location{Latitude: lat, Longitude: lng}]++
+	return time.Now().Add(r.periodLength), nil
+}
+
+func (r *recordingWarmer) count(location model.Location) int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.calls[location]
+}
+
+func TestWarmer_WarmsAndRefreshesBeforePeriodEnds(t *testing.T) {
+	seattle := model.Location{Latitude: 47.6062, Longitude: -122.3321}
+	denver := model.Location{Latitude: 39.7392, Longitude: -104.9903}
+	recorder := &recordingWarmer{calls: map[model.Location]int{}, periodLength: 60 * time.Millisecond}
+
+	warmer := NewWarmer(recorder, []model.Location{seattle, denver}, WarmerConfiguration{
+		Interval:    time.Hour,
+		RateLimit:   1000,
+		RefreshLead: 20 * time.Millisecond,
+	})
+	warmer.Start()
+
+	assert.Eventually(t, func() bool {
+		return recorder.count(seattle) >= 2 && recorder.count(denver) >= 2
+	}, time.Second, 5*time.Millisecond)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	warmer.Stop(ctx)
+	assert.NoError(t, ctx.Err())
+}
+
+func TestWarm_RefreshesCachedPeriods(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockForecast := client.NewMockForecast(ctrl)
+	svc, _ := NewForecast(nil, mockForecast, time.Second, 10)
+
+	endTime := time.Now().Add(time.Hour)
+	mockForecast.EXPECT().GetPointMetadata(gomock.Any(), 1.0, 2.0).Return(&model.PointMetadata{Forecast: "http://test.url"}, nil)
+	mockForecast.EXPECT().GetForecastPeriods(gomock.Any(), "http://test.url").Return(
+		&model.Forecast{
+			Periods: []model.ForcastPeriod{
+				{
+					StartTime:        time.Now().Add(-time.Hour),
+					EndTime:          endTime,
+					DetailedForecast: "Sunny",
+				},
+			},
+		}, nil,
+	).Times(2)
+
+	// the second warm-up reuses the cached forecast URL but refetches the periods
+	for i := 0; i < 2; i++ {
+		got, err := svc.Warm(context.Background(), 1.0, 2.0)
+		assert.NoError(t, err)
+		assert.True(t, got.Equal(endTime))
+	}
+
+	cached, found, _ := svc.forecastPeriodsCache.Get(context.Background(), "http://test.url")
+	assert.True(t, found)
+	assert.Len(t, cached.Periods, 1)
+}
+
+func TestWarm_OpenMeteo(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	var calls atomic.Int32
+	srv := newOpenMeteoStandIn(t, &calls)
+	defer srv.Close()
+	// NWS is not asked for a location outside of its coverage
+	svc := newOpenMeteoService(t, srv, client.NewMockForecast(ctrl), ProviderAuto)
+
+	// every warm-up refetches the Open-Meteo forecast, which the next request is served from
+	for i := 0; i < 2; i++ {
+		got, err := svc.Warm(context.Background(), paris.Latitude, paris.Longitude)
+		require.NoError(t, err)
+		assert.Equal(t, "2025-07-01T16:00:00Z", got.UTC().Format(time.RFC3339))
+	}
+	assert.Equal(t, int32(2), calls.Load())
+
+	_, err := svc.GetForecastPeriods(context.Background(), PeriodsQuery{Location: &paris})
+	require.NoError(t, err)
+	assert.Equal(t, int32(2), calls.Load())
+}
+
+func TestLoadWarmLocations(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "locations.txt")
+	data := "# stores\n47.6062,-122.3321,Seattle\n\n39.7392, -104.9903\n"
+	require.NoError(t, os.WriteFile(path, []byte(data), 0o600))
+
+	locations, err := LoadWarmLocations("40.7128,-74.0060,New York;", path)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []model.Location{
+		{Name: "New York", Latitude: 40.7128, Longitude: -74.0060},
+		{Name: "Seattle", Latitude: 47.6062, Longitude: -122.3321},
+		{Latitude: 39.7392, Longitude: -104.9903},
+	}, locations)
+
+	_, err = LoadWarmLocations("not-a-location", "")
+	assert.Error(t, err)
+}